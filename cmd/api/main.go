@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -23,6 +24,8 @@ func main() {
 		DSN:        "test.db",
 	}, &gorm.Config{
 		SkipDefaultTransaction: true,
+		//timestamps are stored in UTC, like the dates todos are given
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -34,17 +37,35 @@ func main() {
 		&database.TokenBlacklist{},
 		&database.Todo{},
 		&database.Checklist{},
+		&database.Reminder{},
+		&database.Label{},
+		&database.TodoCompletion{},
+		&database.TodoShare{},
+		&database.Workspace{},
+		&database.WorkspaceMember{},
+		&database.Comment{},
+		&database.Attachment{},
+		&database.View{},
+		&database.Template{},
+		&database.CalendarFeed{},
+		&database.RefreshToken{},
+		&database.Session{},
+		&database.OrphanedBlob{},
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if err = database.MigrateSearch(db); err != nil {
+		log.Fatal(err)
+	}
+
 	r := chi.NewRouter()
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Workspace-ID"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -64,6 +85,8 @@ func main() {
 	appContainer := app.NewAppContainer(db)
 	appContainer.RegisterRoutes(r)
 	appContainer.RegisterListeners()
+	appContainer.RegisterJobs()
+	appContainer.Scheduler.Start(context.Background())
 
 	port := env.FetchString("PORT", ":8000")
 	log.Println("🚀🚀🚀 Starting server on port " + port)
@@ -2,31 +2,69 @@ package app
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/attachment"
 	"github.com/horlerdipo/todo-golang/internal/auth"
+	"github.com/horlerdipo/todo-golang/internal/calendar"
+	"github.com/horlerdipo/todo-golang/internal/comment"
+	"github.com/horlerdipo/todo-golang/internal/export"
+	"github.com/horlerdipo/todo-golang/internal/importer"
+	"github.com/horlerdipo/todo-golang/internal/label"
+	"github.com/horlerdipo/todo-golang/internal/reminder"
+	"github.com/horlerdipo/todo-golang/internal/share"
 	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/internal/template"
 	"github.com/horlerdipo/todo-golang/internal/todo"
+	"github.com/horlerdipo/todo-golang/internal/view"
+	"github.com/horlerdipo/todo-golang/internal/workspace"
 	"github.com/horlerdipo/todo-golang/pkg"
 	"gorm.io/gorm"
 	"net/http"
 )
 
 type Container struct {
-	db            *gorm.DB
-	AuthContainer *auth.Container
-	TodoContainer *todo.Container
-	EventBus      pkg.EventBus
-	SSEContainer  *sse.Container
+	db                  *gorm.DB
+	AuthContainer       *auth.Container
+	TodoContainer       *todo.Container
+	ReminderContainer   *reminder.Container
+	LabelContainer      *label.Container
+	ShareContainer      *share.Container
+	WorkspaceContainer  *workspace.Container
+	CommentContainer    *comment.Container
+	AttachmentContainer *attachment.Container
+	ViewContainer       *view.Container
+	TemplateContainer   *template.Container
+	ExportContainer     *export.Container
+	ImportContainer     *importer.Container
+	CalendarContainer   *calendar.Container
+	EventBus            pkg.EventBus
+	Scheduler           pkg.Scheduler
+	SSEContainer        *sse.Container
 }
 
 func NewAppContainer(db *gorm.DB) *Container {
 	eventBus := pkg.NewEventBus()
 	sseContainer := sse.NewContainer(db)
+	labelContainer := label.NewContainer(db, eventBus, sseContainer.SSEService)
+	todoContainer := todo.NewContainer(db, eventBus, sseContainer.SSEService, labelContainer.LabelService)
+	attachmentContainer := attachment.NewContainer(db, eventBus)
 	return &Container{
-		db:            db,
-		AuthContainer: auth.NewContainer(db, sseContainer.SSEService),
-		TodoContainer: todo.NewContainer(db, eventBus, sseContainer.SSEService),
-		EventBus:      eventBus,
-		SSEContainer:  sseContainer,
+		db:                  db,
+		AuthContainer:       auth.NewContainer(db, sseContainer.SSEService),
+		TodoContainer:       todoContainer,
+		ReminderContainer:   reminder.NewContainer(db, sseContainer.SSEService),
+		LabelContainer:      labelContainer,
+		ShareContainer:      share.NewContainer(db, eventBus, sseContainer.SSEService),
+		WorkspaceContainer:  workspace.NewContainer(db, eventBus, sseContainer.SSEService),
+		CommentContainer:    comment.NewContainer(db, eventBus, sseContainer.SSEService),
+		AttachmentContainer: attachmentContainer,
+		ViewContainer:       view.NewContainer(db, todoContainer.TodoHandler, labelContainer.LabelService),
+		TemplateContainer:   template.NewContainer(db, todoContainer.TodoService, labelContainer.LabelService),
+		ExportContainer:     export.NewContainer(db, attachmentContainer.AttachmentService.Storage),
+		ImportContainer:     importer.NewContainer(db, eventBus),
+		CalendarContainer:   calendar.NewContainer(db),
+		EventBus:            eventBus,
+		Scheduler:           pkg.NewScheduler(),
+		SSEContainer:        sseContainer,
 	}
 }
 
@@ -36,10 +74,33 @@ func (container *Container) RegisterRoutes(r *chi.Mux) {
 	})
 	container.AuthContainer.RegisterRoutes(r)
 	container.TodoContainer.RegisterRoutes(r)
+	container.ReminderContainer.RegisterRoutes(r)
+	container.LabelContainer.RegisterRoutes(r)
+	container.ShareContainer.RegisterRoutes(r)
+	container.WorkspaceContainer.RegisterRoutes(r)
+	container.CommentContainer.RegisterRoutes(r)
+	container.AttachmentContainer.RegisterRoutes(r)
+	container.ViewContainer.RegisterRoutes(r)
+	container.TemplateContainer.RegisterRoutes(r)
+	container.ExportContainer.RegisterRoutes(r)
+	container.ImportContainer.RegisterRoutes(r)
+	container.CalendarContainer.RegisterRoutes(r)
 	container.SSEContainer.RegisterRoutes(r)
 }
 
 func (container *Container) RegisterListeners() {
 	//container.AuthContainer.RegisterListeners(container.EventBus)
 	container.TodoContainer.RegisterListeners(container.EventBus)
+	container.ReminderContainer.RegisterListeners(container.EventBus)
+	container.LabelContainer.RegisterListeners(container.EventBus)
+	container.ShareContainer.RegisterListeners(container.EventBus)
+	container.WorkspaceContainer.RegisterListeners(container.EventBus)
+	container.CommentContainer.RegisterListeners(container.EventBus)
+	container.AttachmentContainer.RegisterListeners(container.EventBus)
+}
+
+func (container *Container) RegisterJobs() {
+	container.TodoContainer.RegisterJobs(container.Scheduler)
+	container.ReminderContainer.RegisterJobs(container.Scheduler)
+	container.AttachmentContainer.RegisterJobs(container.Scheduler)
 }
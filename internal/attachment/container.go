@@ -0,0 +1,44 @@
+package attachment
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Container struct {
+	AttachmentService *Service
+	AttachmentHandler *Handler
+}
+
+func NewContainer(db *gorm.DB, bus pkg.EventBus) *Container {
+	attachmentService := NewService(
+		database.NewAttachmentRepository(db),
+		database.NewTodoRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		pkg.NewLocalStorage(env.FetchString("ATTACHMENT_STORAGE_PATH", "storage/attachments")),
+		bus,
+	)
+
+	return &Container{
+		AttachmentService: attachmentService,
+		AttachmentHandler: NewHandler(attachmentService),
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.AttachmentHandler.RegisterRoutes(r)
+}
+
+func (uc *Container) RegisterListeners(bus pkg.EventBus) {
+	bus.Subscribe("todo.purged", NewTodoPurgedListener(uc.AttachmentService))
+}
+
+func (uc *Container) RegisterJobs(scheduler pkg.Scheduler) {
+	interval := time.Duration(env.FetchInt("ORPHANED_BLOB_SWEEP_INTERVAL_MINUTES", 10)) * time.Minute
+	scheduler.Register("attachments.sweep_orphaned_blobs", interval, uc.AttachmentService.SweepOrphanedBlobs)
+}
@@ -0,0 +1,129 @@
+package attachment
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers around the uploaded file.
+const multipartOverhead = 1 << 20
+
+type Handler struct {
+	AttachmentService *Service
+}
+
+func NewHandler(attachmentService *Service) *Handler {
+	return &Handler{
+		AttachmentService: attachmentService,
+	}
+}
+
+func (handler *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxSize()+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "file is too large", nil)
+			return
+		}
+		utils.RespondWithError(w, http.StatusUnprocessableEntity, "a file is required", nil)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := handler.AttachmentService.UploadAttachment(r.Context(), uint(todoId), authDetails.UserId, header.Filename, header.Size, file)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Attachment uploaded successfully", attachment)
+}
+
+func (handler *Handler) FetchAttachments(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	attachments, err := handler.AttachmentService.FetchAttachments(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Attachments fetched successfully", attachments)
+}
+
+// DownloadAttachment serves an attachment's contents, honouring Range and conditional request headers.
+func (handler *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	attachmentId, err := strconv.ParseUint(chi.URLParam(r, "attachmentId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "attachment not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	attachment, file, err := handler.AttachmentService.OpenAttachment(r.Context(), uint(attachmentId), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", attachment.CreatedAt, file)
+}
+
+func (handler *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	attachmentId, err := strconv.ParseUint(chi.URLParam(r, "attachmentId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "attachment not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.AttachmentService.DeleteAttachment(r.Context(), uint(attachmentId), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/todos/{id}/attachments", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.AttachmentService.TokenBlacklistRepository, handler.AttachmentService.SessionRepository))
+		r.Post("/", handler.UploadAttachment)
+		r.Get("/", handler.FetchAttachments)
+		r.Get("/{attachmentId}", handler.DownloadAttachment)
+		r.Delete("/{attachmentId}", handler.DeleteAttachment)
+	})
+}
@@ -0,0 +1,24 @@
+package attachment
+
+import (
+	"context"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+)
+
+// TodoPurgedListener removes the blobs of attachments whose todos were permanently deleted. Blobs it fails to remove
+// stay recorded as orphaned and are retried by SweepOrphanedBlobs.
+type TodoPurgedListener struct {
+	AttachmentService *Service
+}
+
+func (listener *TodoPurgedListener) Handle(event pkg.Event) {
+	e := event.(*events.TodoPurgedEvent)
+	listener.AttachmentService.DeleteBlobs(context.Background(), e.StorageKeys)
+}
+
+func NewTodoPurgedListener(attachmentService *Service) *TodoPurgedListener {
+	return &TodoPurgedListener{
+		AttachmentService: attachmentService,
+	}
+}
@@ -0,0 +1,258 @@
+package attachment
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const defaultAllowedTypes = "image/png,image/jpeg,image/gif,image/webp,application/pdf"
+
+// orphanedBlobSweepSize is how many orphaned blobs a single sweep tries to remove.
+const orphanedBlobSweepSize = 100
+
+type Service struct {
+	AttachmentRepository     database.AttachmentRepository
+	TodoRepository           database.TodoRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	Storage                  pkg.Storage
+	EventBus                 pkg.EventBus
+}
+
+func NewService(attachmentRepository database.AttachmentRepository, todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, storage pkg.Storage, eventBus pkg.EventBus) *Service {
+	return &Service{
+		AttachmentRepository:     attachmentRepository,
+		TodoRepository:           todoRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		Storage:                  storage,
+		EventBus:                 eventBus,
+	}
+}
+
+// MaxSize is the largest file, in bytes, that can be attached to a todo.
+func MaxSize() int64 {
+	return int64(env.FetchInt("ATTACHMENT_MAX_SIZE_KB", 10240)) * 1024
+}
+
+// UploadAttachment stores a file against a todo the user can edit. The content type is sniffed from the file
+// itself rather than trusted from the client, and has to be one of ATTACHMENT_ALLOWED_TYPES.
+func (service *Service) UploadAttachment(ctx context.Context, todoId uint, userId uint, fileName string, size int64, file io.ReadSeeker) (*database.Attachment, error) {
+	_, role, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	if !role.CanEdit() {
+		return nil, errors.New("you do not have permission to edit this todo")
+	}
+
+	if size > MaxSize() {
+		return nil, fmt.Errorf("file is too large, attachments can be at most %d KB", MaxSize()/1024)
+	}
+
+	contentType, err := detectContentType(file)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("unable to read file, please try again")
+	}
+
+	if !allowedType(contentType) {
+		return nil, fmt.Errorf("files of type %s cannot be attached", contentType)
+	}
+
+	key, err := storageKey(todoId, fileName)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("unable to upload attachment, please try again")
+	}
+
+	written, err := service.Storage.Put(ctx, key, file)
+	if err != nil {
+		log.Println("Error while storing attachment", err)
+		return nil, errors.New("unable to upload attachment, please try again")
+	}
+
+	attachment := database.Attachment{
+		TodoID:      todoId,
+		UserID:      userId,
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		Size:        written,
+		StorageKey:  key,
+	}
+	_, err = service.AttachmentRepository.CreateAttachment(ctx, &attachment)
+	if err != nil {
+		log.Println("Error while saving attachment", err)
+		service.DeleteBlobs(ctx, []string{key})
+		return nil, errors.New("unable to upload attachment, please try again")
+	}
+
+	service.EventBus.Publish(&events.TodoUpdatedEvent{
+		TodoId: todoId,
+		UserId: userId,
+	})
+	return &attachment, nil
+}
+
+func (service *Service) FetchAttachments(ctx context.Context, todoId uint, userId uint) ([]database.Attachment, error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	return service.AttachmentRepository.FetchAttachments(ctx, todoId)
+}
+
+// OpenAttachment returns an attachment on a todo the user can see, along with its contents. The caller has to close the contents.
+func (service *Service) OpenAttachment(ctx context.Context, attachmentId uint, todoId uint, userId uint) (*database.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := service.findAttachment(ctx, attachmentId, todoId, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := service.Storage.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		log.Println("Error while opening attachment", err)
+		return nil, nil, errors.New("attachment not found")
+	}
+	return attachment, file, nil
+}
+
+// DeleteAttachment deletes an attachment. Uploaders can delete their own attachments and the todo's owner can delete any of them.
+func (service *Service) DeleteAttachment(ctx context.Context, attachmentId uint, todoId uint, userId uint) error {
+	attachment, err := service.findAttachment(ctx, attachmentId, todoId, userId)
+	if err != nil {
+		return err
+	}
+
+	//anyone may delete their own attachments, while the todo's owner and the admins of its workspace moderate the rest
+	if attachment.UserID != userId && !service.canModerate(ctx, todoId, userId) {
+		return errors.New("you do not have permission to delete this attachment")
+	}
+
+	err = service.AttachmentRepository.DeleteAttachment(ctx, attachmentId)
+	if err != nil {
+		return err
+	}
+	service.DeleteBlobs(ctx, []string{attachment.StorageKey})
+
+	service.EventBus.Publish(&events.TodoUpdatedEvent{
+		TodoId: todoId,
+		UserId: userId,
+	})
+	return nil
+}
+
+// DeleteBlobs removes blobs whose records are already gone, e.g. those of purged todos. A blob that cannot be removed
+// is logged and stays orphaned until SweepOrphanedBlobs gets to it.
+func (service *Service) DeleteBlobs(ctx context.Context, storageKeys []string) {
+	for _, key := range storageKeys {
+		if err := service.Storage.Delete(ctx, key); err != nil {
+			log.Printf("Error while deleting attachment blob %s: %v", key, err)
+			continue
+		}
+		if err := service.AttachmentRepository.DeleteOrphanedBlob(ctx, key); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// SweepOrphanedBlobs retries removing the blobs of deleted attachments that are still in storage.
+func (service *Service) SweepOrphanedBlobs(ctx context.Context) {
+	blobs, err := service.AttachmentRepository.FetchOrphanedBlobs(ctx, orphanedBlobSweepSize)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	storageKeys := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		storageKeys = append(storageKeys, blob.StorageKey)
+	}
+	service.DeleteBlobs(ctx, storageKeys)
+}
+
+func (service *Service) findAttachment(ctx context.Context, attachmentId uint, todoId uint, userId uint) (*database.Attachment, error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	attachment, err := service.AttachmentRepository.FindAttachment(ctx, attachmentId, todoId)
+	if err != nil {
+		return nil, errors.New("attachment not found")
+	}
+	return attachment, nil
+}
+
+// canModerate reports whether the user may remove other people's attachments from the todo, which its owner and the
+// owners and admins of its workspace may.
+func (service *Service) canModerate(ctx context.Context, todoId uint, userId uint) bool {
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
+	return err == nil
+}
+
+// detectContentType sniffs the content type from the start of the file and rewinds it.
+func detectContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	read, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:read]))
+	if err != nil {
+		return "", err
+	}
+	return mediaType, nil
+}
+
+func allowedType(contentType string) bool {
+	for _, allowed := range strings.Split(env.FetchString("ATTACHMENT_ALLOWED_TYPES", defaultAllowedTypes), ",") {
+		if strings.TrimSpace(allowed) == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// storageKey picks a random key for a todo's attachment, keeping the file's extension.
+func storageKey(todoId uint, fileName string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("todos/%d/%s%s", todoId, hex.EncodeToString(random), strings.ToLower(filepath.Ext(cleanFileName(fileName)))), nil
+}
+
+func cleanFileName(fileName string) string {
+	fileName = strings.TrimSpace(filepath.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if fileName == "." || fileName == "/" || fileName == "" {
+		return "attachment"
+	}
+	if len(fileName) > 255 {
+		fileName = fileName[len(fileName)-255:]
+	}
+	return fileName
+}
@@ -17,6 +17,8 @@ func NewContainer(db *gorm.DB, sseService *sse.Service) *Container {
 	authService := NewService(
 		database.NewUserRepository(db),
 		database.NewTokenBlacklistRepository(db),
+		database.NewRefreshTokenRepository(db),
+		database.NewSessionRepository(db),
 		sseService,
 	)
 
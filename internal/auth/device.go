@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"strings"
+)
+
+// maxDeviceNameLength keeps device names guessed from long user agents as short as the ones users can pick.
+const maxDeviceNameLength = 100
+
+// userAgentBrowsers and userAgentSystems are checked in order, since user agents also mention the browsers and
+// systems they want to be mistaken for: Edge claims to be Chrome, Chrome claims to be Safari and iOS claims to be
+// macOS.
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var userAgentSystems = []struct{ token, name string }{
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// deviceName guesses a readable name for the device a user agent belongs to, such as "Firefox on Windows", for
+// logins that did not name their device.
+func deviceName(userAgent string) string {
+	browser := ""
+	for _, candidate := range userAgentBrowsers {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	system := ""
+	for _, candidate := range userAgentSystems {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	name := ""
+	switch {
+	case browser != "" && system != "":
+		name = browser + " on " + system
+	case browser != "":
+		name = browser
+	case system != "":
+		name = system
+	default:
+		//apps and scripts usually lead with their own name, like okhttp/4.9.3
+		fields := strings.Fields(userAgent)
+		if len(fields) == 0 {
+			return "Unknown device"
+		}
+		name = fields[0]
+	}
+
+	if len(name) > maxDeviceNameLength {
+		name = name[:maxDeviceNameLength]
+	}
+	return name
+}
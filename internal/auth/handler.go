@@ -7,7 +7,9 @@ import (
 	"github.com/horlerdipo/todo-golang/internal/middlewares"
 	"github.com/horlerdipo/todo-golang/utils"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 )
 
 type Handler struct {
@@ -20,6 +22,19 @@ func NewAuthHandler(authService *Service) *Handler {
 	}
 }
 
+// requestDevice describes the device a request came from, named name when the user picked a name for it.
+func requestDevice(r *http.Request, name string) dtos.Device {
+	ipAddress, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ipAddress = r.RemoteAddr
+	}
+	return dtos.Device{
+		Name:      name,
+		UserAgent: r.UserAgent(),
+		IPAddress: ipAddress,
+	}
+}
+
 func (h *Handler) loginHandler(w http.ResponseWriter, r *http.Request) {
 	loginDto, err := utils.JsonValidate[dtos.LoginUserDTO](w, r)
 	if err != nil {
@@ -27,7 +42,7 @@ func (h *Handler) loginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.AuthService.Login(r.Context(), loginDto.Email, loginDto.Password)
+	response, err := h.AuthService.Login(r.Context(), loginDto.Email, loginDto.Password, requestDevice(r, loginDto.DeviceName))
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -37,6 +52,21 @@ func (h *Handler) loginHandler(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (h *Handler) refreshHandler(w http.ResponseWriter, r *http.Request) {
+	refreshTokenDto, err := utils.JsonValidate[dtos.RefreshTokenDTO](w, r)
+	if err != nil {
+		return
+	}
+
+	response, err := h.AuthService.Refresh(r.Context(), refreshTokenDto.RefreshToken, requestDevice(r, ""))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusOK, "token refreshed", response)
+}
+
 func (h *Handler) registerHandler(w http.ResponseWriter, r *http.Request) {
 
 	createUserDto, err := utils.JsonValidate[dtos.CreateUserDTO](w, r)
@@ -119,7 +149,7 @@ func (h *Handler) profileHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) logoutHandler(w http.ResponseWriter, r *http.Request) {
 	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
-	resp := h.AuthService.LogoutUser(r.Context(), authDetails.UserId, authDetails.JwtToken, authDetails.JwtExpirationTime.Time)
+	resp := h.AuthService.LogoutUser(r.Context(), authDetails.UserId, authDetails.JwtToken, authDetails.TokenFamily, authDetails.JwtExpirationTime.Time)
 	if !resp {
 		utils.RespondWithError(w, http.StatusInternalServerError, "unable to log out, please try again", nil)
 		return
@@ -129,16 +159,61 @@ func (h *Handler) logoutHandler(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (h *Handler) fetchSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	sessions, err := h.AuthService.FetchSessions(r.Context(), authDetails.UserId, authDetails.TokenFamily)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusOK, "sessions fetched", sessions)
+}
+
+func (h *Handler) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "session not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = h.AuthService.RevokeSession(r.Context(), uint(sessionId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logoutEverywhereHandler(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err := h.AuthService.LogoutEverywhere(r.Context(), authDetails.UserId, authDetails.JwtToken, authDetails.JwtExpirationTime.Time)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", h.loginHandler)
+		r.Post("/refresh", h.refreshHandler)
 		r.Post("/register", h.registerHandler)
 		r.Post("/password/forgot", h.sendResetPasswordToken)
 		r.Post("/password/reset", h.resetPasswordHandler)
 		r.Group(func(r chi.Router) {
-			r.Use(middlewares.JwtAuthMiddleware(h.AuthService.TokenBlacklistRepository))
+			r.Use(middlewares.JwtAuthMiddleware(h.AuthService.TokenBlacklistRepository, h.AuthService.SessionRepository))
 			r.Get("/user", h.profileHandler)
 			r.Post("/logout", h.logoutHandler)
+			r.Get("/sessions", h.fetchSessionsHandler)
+			r.Delete("/sessions", h.logoutEverywhereHandler)
+			r.Delete("/sessions/{id}", h.revokeSessionHandler)
 		})
 	})
 }
@@ -15,16 +15,26 @@ import (
 	"time"
 )
 
+// refreshTokenBytes and familyIdBytes are how many random bytes make up a refresh token and a refresh token family id.
+const (
+	refreshTokenBytes = 32
+	familyIdBytes     = 16
+)
+
 type Service struct {
 	UserRepository           database.UserRepository
 	TokenBlacklistRepository database.TokenBlacklistRepository
+	RefreshTokenRepository   database.RefreshTokenRepository
+	SessionRepository        database.SessionRepository
 	SSEService               *sse.Service
 }
 
-func NewService(userRepository database.UserRepository, tokenBlacklistRepository database.TokenBlacklistRepository, sseService *sse.Service) *Service {
+func NewService(userRepository database.UserRepository, tokenBlacklistRepository database.TokenBlacklistRepository, refreshTokenRepository database.RefreshTokenRepository, sessionRepository database.SessionRepository, sseService *sse.Service) *Service {
 	return &Service{
 		UserRepository:           userRepository,
 		TokenBlacklistRepository: tokenBlacklistRepository,
+		RefreshTokenRepository:   refreshTokenRepository,
+		SessionRepository:        sessionRepository,
 		SSEService:               sseService,
 	}
 }
@@ -49,7 +59,8 @@ func (service *Service) Register(ctx context.Context, userDto dtos.CreateUserDTO
 	return true, nil
 }
 
-func (service *Service) Login(ctx context.Context, email string, password string) (dtos.LoginUserResponseDto, error) {
+// Login checks the user's credentials and starts a session on the device they logged in from.
+func (service *Service) Login(ctx context.Context, email string, password string, device dtos.Device) (dtos.LoginUserResponseDto, error) {
 	email = strings.ToLower(email)
 
 	//check if email exists
@@ -64,25 +75,203 @@ func (service *Service) Login(ctx context.Context, email string, password string
 		return dtos.LoginUserResponseDto{}, errors.New("email or password is not correct")
 	}
 
-	//generate jwt token
-	ttlEnv := env.FetchInt("JWT_TTL", 24)
-	ttl := time.Now().Add(time.Duration(ttlEnv) * time.Hour)
-	tokenString, err := utils.GenerateJwtToken(env.FetchString("JWT_SECRET"), ttl, user.ID)
+	//every login starts a new session, with a refresh token family of its own
+	familyId, err := utils.RandomToken(familyIdBytes)
+	if err != nil {
+		return dtos.LoginUserResponseDto{}, errors.New("error while generating refresh token")
+	}
+
+	if device.Name == "" {
+		device.Name = deviceName(device.UserAgent)
+	}
+	err = service.SessionRepository.CreateSession(ctx, &database.Session{
+		FamilyID:   familyId,
+		UserID:     user.ID,
+		DeviceName: device.Name,
+		UserAgent:  device.UserAgent,
+		IPAddress:  device.IPAddress,
+		LastSeenAt: time.Now(),
+	})
+	if err != nil {
+		return dtos.LoginUserResponseDto{}, err
+	}
+
+	refreshToken, refreshTokenDetails, err := newRefreshToken(user.ID, familyId)
 	if err != nil {
-		return dtos.LoginUserResponseDto{}, errors.New("error while signing token")
+		return dtos.LoginUserResponseDto{}, err
+	}
+	err = service.RefreshTokenRepository.CreateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return dtos.LoginUserResponseDto{}, err
+	}
+
+	accessToken, err := newAccessToken(user.ID, familyId)
+	if err != nil {
+		return dtos.LoginUserResponseDto{}, err
 	}
 
 	return dtos.LoginUserResponseDto{
 		Email:     user.Email,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
-		Token: dtos.TokenDetails{
-			Token: tokenString,
-			Exp:   strconv.FormatInt(ttl.Unix(), 10),
+		AuthTokens: dtos.AuthTokens{
+			Token:        accessToken,
+			RefreshToken: refreshTokenDetails,
 		},
 	}, nil
 }
 
+// newAccessToken signs an access token for the user, valid for ACCESS_TOKEN_TTL minutes.
+func newAccessToken(userId uint, familyId string) (dtos.TokenDetails, error) {
+	ttl := time.Now().Add(time.Duration(env.FetchInt("ACCESS_TOKEN_TTL", 15)) * time.Minute)
+	tokenString, err := utils.GenerateFamilyJwtToken(env.FetchString("JWT_SECRET"), ttl, userId, familyId)
+	if err != nil {
+		return dtos.TokenDetails{}, errors.New("error while signing token")
+	}
+
+	return dtos.TokenDetails{
+		Token: tokenString,
+		Exp:   strconv.FormatInt(ttl.Unix(), 10),
+	}, nil
+}
+
+// newRefreshToken generates a refresh token in the given family, valid for REFRESH_TOKEN_TTL days. It returns the
+// record to store alongside the token itself, which is only ever handed to the user.
+func newRefreshToken(userId uint, familyId string) (*database.RefreshToken, dtos.TokenDetails, error) {
+	token, err := utils.RandomToken(refreshTokenBytes)
+	if err != nil {
+		log.Println("Error while generating refresh token", err)
+		return nil, dtos.TokenDetails{}, errors.New("error while generating refresh token")
+	}
+
+	expiresAt := time.Now().Add(time.Duration(env.FetchInt("REFRESH_TOKEN_TTL", 30)) * 24 * time.Hour)
+	refreshToken := &database.RefreshToken{
+		TokenHash: utils.HashToken(token),
+		FamilyID:  familyId,
+		UserID:    userId,
+		ExpiresAt: expiresAt,
+	}
+	return refreshToken, dtos.TokenDetails{
+		Token: token,
+		Exp:   strconv.FormatInt(expiresAt.Unix(), 10),
+	}, nil
+}
+
+// Refresh trades a refresh token in for a new access token and a new refresh token, rotating the one it was given.
+// A refresh token can only be used once, so seeing one again means it was stolen: the whole family it belongs to is
+// revoked, logging out both the user and whoever took it. Refreshing counts as the session being seen from device.
+func (service *Service) Refresh(ctx context.Context, token string, device dtos.Device) (dtos.AuthTokens, error) {
+	current, err := service.RefreshTokenRepository.FindRefreshTokenByHash(ctx, utils.HashToken(token))
+	if err != nil || current.RevokedAt != nil {
+		return dtos.AuthTokens{}, errors.New("refresh token is invalid")
+	}
+
+	if current.UsedAt != nil {
+		if err = service.endSession(ctx, current.UserID, current.FamilyID); err != nil {
+			log.Println(err)
+		}
+		return dtos.AuthTokens{}, errors.New("refresh token has already been used")
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return dtos.AuthTokens{}, errors.New("refresh token has expired")
+	}
+
+	next, refreshTokenDetails, err := newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return dtos.AuthTokens{}, err
+	}
+
+	rotated, err := service.RefreshTokenRepository.RotateRefreshToken(ctx, current.ID, next)
+	if err != nil {
+		return dtos.AuthTokens{}, err
+	}
+	if !rotated {
+		if err = service.endSession(ctx, current.UserID, current.FamilyID); err != nil {
+			log.Println(err)
+		}
+		return dtos.AuthTokens{}, errors.New("refresh token has already been used")
+	}
+
+	err = service.SessionRepository.TouchSession(ctx, current.FamilyID, device.UserAgent, device.IPAddress, time.Now())
+	if err != nil {
+		log.Println(err)
+	}
+
+	accessToken, err := newAccessToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return dtos.AuthTokens{}, err
+	}
+	return dtos.AuthTokens{
+		Token:        accessToken,
+		RefreshToken: refreshTokenDetails,
+	}, nil
+}
+
+// endSession revokes a session along with its refresh tokens, which turns off its access tokens too, and disconnects
+// the SSE clients connected with it.
+func (service *Service) endSession(ctx context.Context, userId uint, familyId string) error {
+	service.SSEService.RemoveSessionClients(userId, familyId)
+	if err := service.SessionRepository.RevokeSession(ctx, familyId); err != nil {
+		return err
+	}
+	return service.RefreshTokenRepository.RevokeRefreshTokenFamily(ctx, familyId)
+}
+
+// endAllSessions revokes every session of the user and disconnects all of their SSE clients.
+func (service *Service) endAllSessions(ctx context.Context, userId uint) error {
+	service.SSEService.RemoveClients(userId)
+	if err := service.SessionRepository.RevokeUserSessions(ctx, userId); err != nil {
+		return err
+	}
+	return service.RefreshTokenRepository.RevokeUserRefreshTokens(ctx, userId)
+}
+
+// FetchSessions lists the user's sessions that are still alive, flagging the one with the given refresh token family
+// as the current one.
+func (service *Service) FetchSessions(ctx context.Context, userId uint, currentFamily string) ([]dtos.SessionDetails, error) {
+	seenSince := time.Now().Add(-time.Duration(env.FetchInt("REFRESH_TOKEN_TTL", 30)) * 24 * time.Hour)
+	sessions, err := service.SessionRepository.FetchSessions(ctx, userId, seenSince)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]dtos.SessionDetails, 0, len(sessions))
+	for _, session := range sessions {
+		details = append(details, dtos.SessionDetails{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			LastSeenAt: session.LastSeenAt,
+			CreatedAt:  session.CreatedAt,
+			Current:    currentFamily != "" && session.FamilyID == currentFamily,
+		})
+	}
+	return details, nil
+}
+
+// RevokeSession logs the user out of one of their sessions, which can be the one they are using.
+func (service *Service) RevokeSession(ctx context.Context, sessionId uint, userId uint) error {
+	session, err := service.SessionRepository.FindSessionByUserId(ctx, sessionId, userId)
+	if err != nil {
+		return errors.New("session does not exist")
+	}
+	return service.endSession(ctx, userId, session.FamilyID)
+}
+
+// LogoutEverywhere logs the user out of every session, the one they are using included. The access token they used
+// is blacklisted as well, in case it was issued without a session.
+func (service *Service) LogoutEverywhere(ctx context.Context, userId uint, authToken string, tokenExpirationDate time.Time) error {
+	if err := service.endAllSessions(ctx, userId); err != nil {
+		return err
+	}
+	if _, err := service.TokenBlacklistRepository.InsertToken(ctx, authToken, &tokenExpirationDate); err != nil {
+		return errors.New("unable to log out, please try again")
+	}
+	return nil
+}
+
 func (service *Service) SendForgotPasswordToken(ctx context.Context, email string) (bool, error) {
 	//check if email exists
 	email = strings.ToLower(email)
@@ -146,6 +335,11 @@ func (service *Service) ResetPassword(ctx context.Context, resetToken string, ne
 		log.Println("Error while updating user password: ", err)
 		return errors.New("error while resetting password")
 	}
+
+	//whoever knew the old password should not stay logged in
+	if err = service.endAllSessions(ctx, user.ID); err != nil {
+		log.Println(err)
+	}
 	return nil
 }
 
@@ -164,11 +358,12 @@ func (service *Service) FetchUserDetails(ctx context.Context, userId uint) (*dto
 	}, nil
 }
 
-func (service *Service) LogoutUser(ctx context.Context, userId uint, authToken string, tokenExpirationDate time.Time) bool {
+// LogoutUser blacklists the access token the user logged out with and ends the session it was issued for, so that it
+// cannot be refreshed either. Only that session's SSE clients are disconnected.
+func (service *Service) LogoutUser(ctx context.Context, userId uint, authToken string, tokenFamily string, tokenExpirationDate time.Time) bool {
 	_, err := service.TokenBlacklistRepository.InsertToken(ctx, authToken, &tokenExpirationDate)
-	service.SSEService.RemoveClients(userId)
 	if err != nil {
 		return false
 	}
-	return true
+	return service.endSession(ctx, userId, tokenFamily) == nil
 }
@@ -0,0 +1,30 @@
+package calendar
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	CalendarService *Service
+	CalendarHandler *Handler
+}
+
+func NewContainer(db *gorm.DB) *Container {
+	calendarService := NewService(
+		database.NewCalendarFeedRepository(db),
+		database.NewTodoRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+	)
+
+	return &Container{
+		CalendarService: calendarService,
+		CalendarHandler: NewHandler(calendarService),
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.CalendarHandler.RegisterRoutes(r)
+}
@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	CalendarService *Service
+}
+
+func NewHandler(calendarService *Service) *Handler {
+	return &Handler{
+		CalendarService: calendarService,
+	}
+}
+
+// feedUrl is the address calendar apps subscribe to, on the host the feed was requested from.
+func feedUrl(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/calendar/" + token + ".ics"
+}
+
+func (handler *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	token, err := handler.CalendarService.CreateFeed(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Calendar feed created successfully", dtos.CalendarFeedDetails{
+		Token: token,
+		URL:   feedUrl(r, token),
+	})
+}
+
+func (handler *Handler) FetchFeed(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	feed, err := handler.CalendarService.FetchFeed(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Calendar feed fetched successfully", feed)
+}
+
+func (handler *Handler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err := handler.CalendarService.DeleteFeed(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeFeed serves a user's todos as an iCalendar file to anyone holding the feed's token, since calendar apps
+// subscribe without logging in. Pass events=true to get every todo as an event too.
+func (handler *Handler) ServeFeed(w http.ResponseWriter, r *http.Request) {
+	events := false
+	if value := r.URL.Query().Get("events"); value != "" {
+		var err error
+		events, err = strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnprocessableEntity, "events must be true or false", nil)
+			return
+		}
+	}
+
+	feed, err := handler.CalendarService.FindFeedByToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="todos.ics"`)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if err = handler.CalendarService.WriteFeed(r.Context(), feed, events, w); err != nil {
+		log.Println("Error while writing calendar feed", err)
+	}
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/calendar", func(r chi.Router) {
+		r.Get("/{token}.ics", handler.ServeFeed)
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.JwtAuthMiddleware(handler.CalendarService.TokenBlacklistRepository, handler.CalendarService.SessionRepository))
+			r.Post("/feed", handler.CreateFeed)
+			r.Get("/feed", handler.FetchFeed)
+			r.Delete("/feed", handler.DeleteFeed)
+		})
+	})
+}
@@ -0,0 +1,217 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsTimeLayout writes times in UTC, which every calendar app understands without a VTIMEZONE definition.
+const icsTimeLayout = "20060102T150405Z"
+
+// maxLineOctets is how long RFC 5545 lets a content line get before it has to be folded onto the next.
+const maxLineOctets = 75
+
+// uidDomain makes the UIDs of todos unique beyond this app, as RFC 5545 asks.
+const uidDomain = "todo-golang"
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// icsPriorities maps priorities onto the 1 (highest) to 9 (lowest) scale of RFC 5545. Todos without a priority
+// leave the property out.
+var icsPriorities = map[enums.TodoPriority]int{
+	enums.PriorityUrgent: 1,
+	enums.PriorityHigh:   3,
+	enums.PriorityMedium: 5,
+	enums.PriorityLow:    9,
+}
+
+// icsWriter writes the content lines of an iCalendar file, holding on to the first error so that callers can check
+// it once at the end.
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newICSWriter(w io.Writer) *icsWriter {
+	return &icsWriter{w: bufio.NewWriter(w)}
+}
+
+// line writes a content line, folding it so that no line is longer than maxLineOctets without splitting a character.
+func (ics *icsWriter) line(name string, value string) {
+	if ics.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, ics.err = ics.w.WriteString(line[:cut] + "\r\n "); ics.err != nil {
+			return
+		}
+		line = line[cut:]
+		// the space that starts a folded line counts towards its length
+		limit = maxLineOctets - 1
+	}
+	_, ics.err = ics.w.WriteString(line + "\r\n")
+}
+
+func (ics *icsWriter) text(name string, value string) {
+	ics.line(name, textEscaper.Replace(value))
+}
+
+func (ics *icsWriter) time(name string, value time.Time) {
+	ics.line(name, value.UTC().Format(icsTimeLayout))
+}
+
+func (ics *icsWriter) flush() error {
+	if ics.err != nil {
+		return ics.err
+	}
+	return ics.w.Flush()
+}
+
+func (ics *icsWriter) beginCalendar(name string) {
+	ics.line("BEGIN", "VCALENDAR")
+	ics.line("VERSION", "2.0")
+	ics.line("PRODID", "-//todo-golang//Todos//EN")
+	ics.line("CALSCALE", "GREGORIAN")
+	ics.line("METHOD", "PUBLISH")
+	ics.text("X-WR-CALNAME", name)
+	// hints for how often subscribed calendars should check for changes
+	ics.line("REFRESH-INTERVAL;VALUE=DURATION", "PT15M")
+	ics.line("X-PUBLISHED-TTL", "PT15M")
+}
+
+func (ics *icsWriter) endCalendar() {
+	ics.line("END", "VCALENDAR")
+}
+
+// todo writes a todo as a VTODO, which task apps show with its due date and whether it is done. Recurring todos are
+// written without an RRULE, since every occurrence is its own todo and is already in the feed once it exists.
+func (ics *icsWriter) todo(todo *database.Todo, stamp time.Time) {
+	ics.line("BEGIN", "VTODO")
+	ics.line("UID", fmt.Sprintf("todo-%d@%s", todo.ID, uidDomain))
+	ics.common(todo, stamp)
+	if todo.StartAt != nil {
+		ics.time("DTSTART", *todo.StartAt)
+	}
+	ics.time("DUE", *todo.DueAt)
+
+	if todo.Completed {
+		ics.line("STATUS", "COMPLETED")
+		if todo.CompletedAt != nil {
+			ics.time("COMPLETED", *todo.CompletedAt)
+		}
+		ics.line("PERCENT-COMPLETE", "100")
+	} else {
+		ics.line("STATUS", "NEEDS-ACTION")
+		if done, total := checklistProgress(todo.Checklists); total > 0 {
+			ics.line("PERCENT-COMPLETE", fmt.Sprint(done*100/total))
+		}
+	}
+
+	if priority, ok := icsPriorities[todo.Priority]; ok {
+		ics.line("PRIORITY", fmt.Sprint(priority))
+	}
+	ics.line("END", "VTODO")
+}
+
+// event writes a todo as a VEVENT running from when it starts until it is due, for calendar apps that ignore VTODOs.
+// Events are transparent so that todos never show the user as busy.
+func (ics *icsWriter) event(todo *database.Todo, stamp time.Time) {
+	ics.line("BEGIN", "VEVENT")
+	ics.line("UID", fmt.Sprintf("event-%d@%s", todo.ID, uidDomain))
+	ics.common(todo, stamp)
+	if todo.StartAt != nil && todo.StartAt.Before(*todo.DueAt) {
+		ics.time("DTSTART", *todo.StartAt)
+		ics.time("DTEND", *todo.DueAt)
+	} else {
+		ics.time("DTSTART", *todo.DueAt)
+	}
+	ics.line("TRANSP", "TRANSPARENT")
+	ics.line("END", "VEVENT")
+}
+
+// common writes the properties a todo's VTODO and VEVENT share.
+func (ics *icsWriter) common(todo *database.Todo, stamp time.Time) {
+	ics.time("DTSTAMP", stamp)
+	ics.time("CREATED", todo.CreatedAt)
+	ics.time("LAST-MODIFIED", todo.UpdatedAt)
+	ics.text("SUMMARY", todo.Title)
+	if description := todoDescription(todo); description != "" {
+		ics.text("DESCRIPTION", description)
+	}
+	if len(todo.Labels) > 0 {
+		names := make([]string, 0, len(todo.Labels))
+		for _, label := range todo.Labels {
+			names = append(names, textEscaper.Replace(label.Name))
+		}
+		ics.line("CATEGORIES", strings.Join(names, ","))
+	}
+}
+
+// todoDescription is a text todo's content, or a summary of a checklist todo's progress followed by its items, with
+// sub-items indented under their parents.
+func todoDescription(todo *database.Todo) string {
+	if todo.Type != enums.Checklist {
+		if todo.Content == nil {
+			return ""
+		}
+		return *todo.Content
+	}
+
+	done, total := checklistProgress(todo.Checklists)
+	if total == 0 {
+		return ""
+	}
+
+	children := make(map[uint][]database.Checklist)
+	ids := make(map[uint]bool, len(todo.Checklists))
+	for _, item := range todo.Checklists {
+		ids[item.ID] = true
+	}
+	var roots []database.Checklist
+	for _, item := range todo.Checklists {
+		if item.ParentID != nil && ids[*item.ParentID] {
+			children[*item.ParentID] = append(children[*item.ParentID], item)
+			continue
+		}
+		roots = append(roots, item)
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "%d of %d items done", done, total)
+	var write func(items []database.Checklist, depth int)
+	write = func(items []database.Checklist, depth int) {
+		for _, item := range items {
+			mark := "[ ]"
+			if item.Done {
+				mark = "[x]"
+			}
+			fmt.Fprintf(&description, "\n%s%s %s", strings.Repeat("  ", depth), mark, item.Description)
+			write(children[item.ID], depth+1)
+		}
+	}
+	write(roots, 0)
+	return description.String()
+}
+
+func checklistProgress(items []database.Checklist) (int, int) {
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(items)
+}
@@ -0,0 +1,94 @@
+package calendar
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/utils"
+	"golang.org/x/net/context"
+	"io"
+	"log"
+	"time"
+)
+
+// feedTokenBytes is how many random bytes make up a feed token, the only thing guarding a subscription URL.
+const feedTokenBytes = 32
+
+type Service struct {
+	CalendarFeedRepository   database.CalendarFeedRepository
+	TodoRepository           database.TodoRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+}
+
+func NewService(calendarFeedRepository database.CalendarFeedRepository, todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository) *Service {
+	return &Service{
+		CalendarFeedRepository:   calendarFeedRepository,
+		TodoRepository:           todoRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+	}
+}
+
+// CreateFeed issues the user a new feed token and returns it. A user has a single feed, so issuing a new token
+// rotates it and the URL with the old token stops working.
+func (service *Service) CreateFeed(ctx context.Context, userId uint) (string, error) {
+	token, err := utils.RandomToken(feedTokenBytes)
+	if err != nil {
+		log.Println("Error while generating calendar feed token", err)
+		return "", errors.New("error while generating calendar feed token")
+	}
+
+	if err = service.CalendarFeedRepository.SaveCalendarFeed(ctx, userId, utils.HashToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (service *Service) FetchFeed(ctx context.Context, userId uint) (*database.CalendarFeed, error) {
+	feed, err := service.CalendarFeedRepository.FindCalendarFeedByUserId(ctx, userId)
+	if err != nil {
+		return nil, errors.New("calendar feed does not exist")
+	}
+	return feed, nil
+}
+
+func (service *Service) DeleteFeed(ctx context.Context, userId uint) error {
+	if _, err := service.FetchFeed(ctx, userId); err != nil {
+		return err
+	}
+	return service.CalendarFeedRepository.DeleteCalendarFeed(ctx, userId)
+}
+
+func (service *Service) FindFeedByToken(ctx context.Context, token string) (*database.CalendarFeed, error) {
+	feed, err := service.CalendarFeedRepository.FindCalendarFeedByTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, errors.New("calendar feed does not exist")
+	}
+	return feed, nil
+}
+
+// WriteFeed writes the todos with a due date that the feed's user can see as an iCalendar file, each as a VTODO and,
+// when events is set, as a VEVENT as well.
+func (service *Service) WriteFeed(ctx context.Context, feed *database.CalendarFeed, events bool, w io.Writer) error {
+	now := time.Now()
+	if err := service.CalendarFeedRepository.MarkCalendarFeedFetched(ctx, feed.ID, now); err != nil {
+		log.Println(err)
+	}
+
+	ics := newICSWriter(w)
+	ics.beginCalendar("Todos")
+	err := service.TodoRepository.StreamCalendarTodos(ctx, feed.UserID, func(todos []database.Todo) error {
+		for i := range todos {
+			ics.todo(&todos[i], now)
+			if events {
+				ics.event(&todos[i], now)
+			}
+		}
+		return ics.err
+	})
+	if err != nil {
+		return err
+	}
+	ics.endCalendar()
+	return ics.flush()
+}
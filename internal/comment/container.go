@@ -0,0 +1,44 @@
+package comment
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	CommentService      *Service
+	CommentHandler      *Handler
+	TodoShareRepository database.TodoShareRepository
+	SSEService          *sse.Service
+}
+
+func NewContainer(db *gorm.DB, bus pkg.EventBus, sseService *sse.Service) *Container {
+	commentService := NewService(
+		database.NewCommentRepository(db),
+		database.NewTodoRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		bus,
+	)
+
+	return &Container{
+		CommentService:      commentService,
+		CommentHandler:      NewHandler(commentService),
+		TodoShareRepository: database.NewTodoShareRepository(db),
+		SSEService:          sseService,
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.CommentHandler.RegisterRoutes(r)
+}
+
+func (uc *Container) RegisterListeners(bus pkg.EventBus) {
+	listener := NewCommentEventListener(uc.TodoShareRepository, uc.SSEService)
+	bus.Subscribe("comment.added", listener)
+	bus.Subscribe("comment.updated", listener)
+	bus.Subscribe("comment.deleted", listener)
+}
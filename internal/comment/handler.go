@@ -0,0 +1,122 @@
+package comment
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	CommentService *Service
+}
+
+func NewHandler(commentService *Service) *Handler {
+	return &Handler{
+		CommentService: commentService,
+	}
+}
+
+func (handler *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.CreateCommentDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.TodoID = uint(todoId)
+	jsonRequest.UserID = authDetails.UserId
+
+	commentId, err := handler.CommentService.AddComment(r.Context(), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Comment added successfully", map[string]uint{"id": commentId})
+}
+
+func (handler *Handler) FetchComments(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	comments, err := handler.CommentService.FetchComments(r.Context(), uint(todoId), dtos.PaginationOptionsFromQuery(r.URL.Query()), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if link := comments.Meta.LinkHeader(r.URL); link != "" {
+		w.Header().Set("Link", link)
+	}
+	utils.RespondWithPaginatedData(w, http.StatusOK, "Comments fetched successfully", comments.Data, comments.Meta)
+}
+
+func (handler *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	commentId, err := strconv.ParseUint(chi.URLParam(r, "commentId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "comment not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.UpdateCommentDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.CommentService.UpdateComment(r.Context(), uint(commentId), uint(todoId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	commentId, err := strconv.ParseUint(chi.URLParam(r, "commentId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "comment not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.CommentService.DeleteComment(r.Context(), uint(commentId), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/todos/{id}/comments", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.CommentService.TokenBlacklistRepository, handler.CommentService.SessionRepository))
+		r.Post("/", handler.AddComment)
+		r.Get("/", handler.FetchComments)
+		r.Patch("/{commentId}", handler.UpdateComment)
+		r.Delete("/{commentId}", handler.DeleteComment)
+	})
+}
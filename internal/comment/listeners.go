@@ -0,0 +1,57 @@
+package comment
+
+import (
+	"context"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"log"
+)
+
+// CommentEventListener pushes comment changes to every user who can see the todo they were made on.
+type CommentEventListener struct {
+	TodoShareRepository database.TodoShareRepository
+	SSEService          *sse.Service
+}
+
+func (listener *CommentEventListener) Handle(event pkg.Event) {
+	var eventType dtos.SSEEventType
+	var commentId, todoId, userId uint
+	switch e := event.(type) {
+	case *events.CommentAddedEvent:
+		eventType, commentId, todoId, userId = dtos.CommentAdded, e.CommentId, e.TodoId, e.UserId
+	case *events.CommentUpdatedEvent:
+		eventType, commentId, todoId, userId = dtos.CommentUpdated, e.CommentId, e.TodoId, e.UserId
+	case *events.CommentDeletedEvent:
+		eventType, commentId, todoId, userId = dtos.CommentDeleted, e.CommentId, e.TodoId, e.UserId
+	default:
+		return
+	}
+
+	audience, err := listener.TodoShareRepository.FetchAudience(context.Background(), todoId)
+	if err != nil {
+		log.Println("Error while fetching todo audience", err)
+		return
+	}
+
+	message := dtos.SSEData{
+		Event: eventType,
+		Data: map[string]uint{
+			"comment_id": commentId,
+			"todo_id":    todoId,
+			"user_id":    userId,
+		},
+	}
+	for _, audienceId := range audience {
+		listener.SSEService.SendMessage(audienceId, message)
+	}
+}
+
+func NewCommentEventListener(todoShareRepository database.TodoShareRepository, sseService *sse.Service) *CommentEventListener {
+	return &CommentEventListener{
+		TodoShareRepository: todoShareRepository,
+		SSEService:          sseService,
+	}
+}
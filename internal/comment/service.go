@@ -0,0 +1,147 @@
+package comment
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"log"
+	"strings"
+)
+
+type Service struct {
+	CommentRepository        database.CommentRepository
+	TodoRepository           database.TodoRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	EventBus                 pkg.EventBus
+}
+
+func NewService(commentRepository database.CommentRepository, todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, eventBus pkg.EventBus) *Service {
+	return &Service{
+		CommentRepository:        commentRepository,
+		TodoRepository:           todoRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		EventBus:                 eventBus,
+	}
+}
+
+// AddComment adds a comment to a todo. Anyone who can see the todo can comment on it, viewers included.
+func (service *Service) AddComment(ctx context.Context, createCommentDto *dtos.CreateCommentDTO) (uint, error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, createCommentDto.TodoID, createCommentDto.UserID, false)
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New("todo does not exist")
+	}
+
+	createCommentDto.Body = strings.TrimSpace(createCommentDto.Body)
+	commentId, err := service.CommentRepository.CreateComment(ctx, createCommentDto)
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New("unable to add comment, please try again")
+	}
+
+	service.EventBus.Publish(&events.CommentAddedEvent{
+		CommentId: commentId,
+		TodoId:    createCommentDto.TodoID,
+		UserId:    createCommentDto.UserID,
+	})
+	return commentId, nil
+}
+
+func (service *Service) UpdateComment(ctx context.Context, commentId uint, todoId uint, updateCommentDto *dtos.UpdateCommentDTO, userId uint) error {
+	comment, err := service.findComment(ctx, commentId, todoId, userId)
+	if err != nil {
+		return err
+	}
+
+	if comment.UserID != userId {
+		return errors.New("only the author can edit this comment")
+	}
+
+	updateCommentDto.Body = strings.TrimSpace(updateCommentDto.Body)
+	err = service.CommentRepository.UpdateComment(ctx, commentId, updateCommentDto)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.CommentUpdatedEvent{
+		CommentId: commentId,
+		TodoId:    todoId,
+		UserId:    userId,
+	})
+	return nil
+}
+
+// DeleteComment deletes a comment. Authors can delete their own comments and the todo's owner can delete any of them.
+func (service *Service) DeleteComment(ctx context.Context, commentId uint, todoId uint, userId uint) error {
+	comment, err := service.findComment(ctx, commentId, todoId, userId)
+	if err != nil {
+		return err
+	}
+
+	//anyone may delete their own comments, while the todo's owner and the admins of its workspace moderate the rest
+	if comment.UserID != userId && !service.canModerate(ctx, todoId, userId) {
+		return errors.New("you do not have permission to delete this comment")
+	}
+
+	err = service.CommentRepository.DeleteComment(ctx, commentId)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.CommentDeletedEvent{
+		CommentId: commentId,
+		TodoId:    todoId,
+		UserId:    userId,
+	})
+	return nil
+}
+
+func (service *Service) FetchComments(ctx context.Context, todoId uint, pagination dtos.PaginationOptions, userId uint) (dtos.PaginatedResponse[database.Comment], error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return dtos.PaginatedResponse[database.Comment]{}, errors.New("todo does not exist")
+	}
+
+	pagination.AllowedSortFields = map[string]bool{
+		"id":         true,
+		"created_at": true,
+	}
+	pagination.AllowedFilters = map[string]dtos.AllowedFilter{
+		"user_id": {
+			Type: dtos.IntegerFilter,
+		},
+		"created_at": {
+			Type: dtos.DateFilter,
+		},
+	}
+
+	return service.CommentRepository.FetchComments(ctx, todoId, pagination)
+}
+
+// findComment finds a comment on a todo the user can see.
+func (service *Service) findComment(ctx context.Context, commentId uint, todoId uint, userId uint) (*database.Comment, error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	comment, err := service.CommentRepository.FindComment(ctx, commentId, todoId)
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+	return comment, nil
+}
+
+// canModerate reports whether the user may remove other people's comments on the todo, which its owner and the owners
+// and admins of its workspace may.
+func (service *Service) canModerate(ctx context.Context, todoId uint, userId uint) bool {
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
+	return err == nil
+}
@@ -0,0 +1,13 @@
+package database
+
+type Attachment struct {
+	Model
+	TodoID      uint   `gorm:"index" json:"todo_id"`
+	Todo        Todo   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID      uint   `gorm:"index" json:"user_id"`
+	User        User   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	StorageKey  string `json:"-"`
+}
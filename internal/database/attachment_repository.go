@@ -0,0 +1,120 @@
+package database
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *Attachment) (uint, error)
+	FindAttachment(ctx context.Context, attachmentId uint, todoId uint) (*Attachment, error)
+	FetchAttachments(ctx context.Context, todoId uint) ([]Attachment, error)
+	DeleteAttachment(ctx context.Context, attachmentId uint) error
+	StreamUserAttachments(ctx context.Context, userId uint, fn func([]Attachment) error) error
+	FetchOrphanedBlobs(ctx context.Context, limit int) ([]OrphanedBlob, error)
+	DeleteOrphanedBlob(ctx context.Context, storageKey string) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (repo *attachmentRepository) CreateAttachment(ctx context.Context, attachment *Attachment) (uint, error) {
+	result := repo.db.WithContext(ctx).Create(attachment)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return attachment.ID, nil
+}
+
+func (repo *attachmentRepository) FindAttachment(ctx context.Context, attachmentId uint, todoId uint) (*Attachment, error) {
+	attachment := Attachment{}
+	result := repo.db.WithContext(ctx).Where("id = ?", attachmentId).Where("todo_id = ?", todoId).First(&attachment)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attachment not found")
+		}
+		return nil, result.Error
+	}
+	return &attachment, nil
+}
+
+func (repo *attachmentRepository) FetchAttachments(ctx context.Context, todoId uint) ([]Attachment, error) {
+	var attachments []Attachment
+	result := repo.db.WithContext(ctx).Where("todo_id = ?", todoId).Order("id asc").Find(&attachments)
+	if result.Error != nil {
+		log.Println("Error while fetching attachments", result.Error)
+		return nil, errors.New("error while fetching attachments")
+	}
+	return attachments, nil
+}
+
+// DeleteAttachment removes an attachment's record outright and records its blob as orphaned, to be removed from
+// storage separately.
+func (repo *attachmentRepository) DeleteAttachment(ctx context.Context, attachmentId uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var storageKeys []string
+		result := tx.Model(&Attachment{}).Where("id = ?", attachmentId).Pluck("storage_key", &storageKeys)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		result = tx.Unscoped().Where("id = ?", attachmentId).Delete(&Attachment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		return orphanBlobs(tx, storageKeys)
+	})
+
+	if err != nil {
+		log.Println("Error while deleting attachment", err)
+		return errors.New("unable to delete attachment, please try again")
+	}
+	return nil
+}
+
+// StreamUserAttachments hands fn the attachments the user uploaded, a batch at a time.
+func (repo *attachmentRepository) StreamUserAttachments(ctx context.Context, userId uint, fn func([]Attachment) error) error {
+	return streamInBatches(repo.db.WithContext(ctx).Where("user_id = ?", userId), fn)
+}
+
+// FetchOrphanedBlobs lists up to limit blobs whose attachments are gone but which are still in storage, oldest first.
+func (repo *attachmentRepository) FetchOrphanedBlobs(ctx context.Context, limit int) ([]OrphanedBlob, error) {
+	var blobs []OrphanedBlob
+	result := repo.db.WithContext(ctx).Order("id asc").Limit(limit).Find(&blobs)
+	if result.Error != nil {
+		log.Println("Error while fetching orphaned blobs", result.Error)
+		return nil, errors.New("error while fetching orphaned blobs")
+	}
+	return blobs, nil
+}
+
+// DeleteOrphanedBlob forgets an orphaned blob once it has been removed from storage.
+func (repo *attachmentRepository) DeleteOrphanedBlob(ctx context.Context, storageKey string) error {
+	result := repo.db.WithContext(ctx).Unscoped().Where("storage_key = ?", storageKey).Delete(&OrphanedBlob{})
+	if result.Error != nil {
+		log.Println("Error while deleting orphaned blob", result.Error)
+		return errors.New("unable to delete orphaned blob")
+	}
+	return nil
+}
+
+// orphanBlobs records the blobs under storageKeys as orphaned, as part of the transaction deleting their attachments.
+func orphanBlobs(tx *gorm.DB, storageKeys []string) error {
+	if len(storageKeys) == 0 {
+		return nil
+	}
+
+	blobs := make([]OrphanedBlob, 0, len(storageKeys))
+	for _, key := range storageKeys {
+		blobs = append(blobs, OrphanedBlob{StorageKey: key})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&blobs).Error
+}
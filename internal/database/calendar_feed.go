@@ -0,0 +1,13 @@
+package database
+
+import "time"
+
+// CalendarFeed lets calendar apps subscribe to a user's todos through a secret URL. Only a hash of the URL's token is
+// kept, so a lost URL cannot be recovered and a new one has to be issued in its place.
+type CalendarFeed struct {
+	Model
+	TokenHash     string     `gorm:"uniqueIndex" json:"-"`
+	UserID        uint       `gorm:"uniqueIndex" json:"user_id"`
+	User          User       `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
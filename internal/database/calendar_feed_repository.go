@@ -0,0 +1,91 @@
+package database
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+type CalendarFeedRepository interface {
+	SaveCalendarFeed(ctx context.Context, userId uint, tokenHash string) error
+	FindCalendarFeedByUserId(ctx context.Context, userId uint) (*CalendarFeed, error)
+	FindCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*CalendarFeed, error)
+	MarkCalendarFeedFetched(ctx context.Context, feedId uint, fetchedAt time.Time) error
+	DeleteCalendarFeed(ctx context.Context, userId uint) error
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+// SaveCalendarFeed gives the user's feed a new token, replacing the one it had before so that the old URL stops working.
+func (repo *calendarFeedRepository) SaveCalendarFeed(ctx context.Context, userId uint, tokenHash string) error {
+	feed := CalendarFeed{
+		TokenHash: tokenHash,
+		UserID:    userId,
+	}
+
+	result := repo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"token_hash":      tokenHash,
+			"last_fetched_at": nil,
+			"updated_at":      time.Now(),
+		}),
+	}).Create(&feed)
+	if result.Error != nil {
+		log.Println("Error while saving calendar feed", result.Error)
+		return errors.New("unable to create calendar feed, please try again")
+	}
+	return nil
+}
+
+func (repo *calendarFeedRepository) FindCalendarFeedByUserId(ctx context.Context, userId uint) (*CalendarFeed, error) {
+	feed := CalendarFeed{}
+	result := repo.db.WithContext(ctx).Where("user_id = ?", userId).First(&feed)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar feed not found")
+		}
+		return nil, result.Error
+	}
+	return &feed, nil
+}
+
+func (repo *calendarFeedRepository) FindCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*CalendarFeed, error) {
+	feed := CalendarFeed{}
+	result := repo.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&feed)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar feed not found")
+		}
+		return nil, result.Error
+	}
+	return &feed, nil
+}
+
+func (repo *calendarFeedRepository) MarkCalendarFeedFetched(ctx context.Context, feedId uint, fetchedAt time.Time) error {
+	result := repo.db.WithContext(ctx).Model(&CalendarFeed{}).Where("id = ?", feedId).UpdateColumn("last_fetched_at", fetchedAt)
+	if result.Error != nil {
+		log.Println("Error while marking calendar feed as fetched", result.Error)
+		return errors.New("unable to update calendar feed")
+	}
+	return nil
+}
+
+// DeleteCalendarFeed turns the user's feed off. The row is removed outright so that a later feed can take its place.
+func (repo *calendarFeedRepository) DeleteCalendarFeed(ctx context.Context, userId uint) error {
+	result := repo.db.WithContext(ctx).Unscoped().Where("user_id = ?", userId).Delete(&CalendarFeed{})
+	if result.Error != nil {
+		log.Println("Error while deleting calendar feed", result.Error)
+		return errors.New("unable to delete calendar feed")
+	}
+	return nil
+}
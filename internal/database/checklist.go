@@ -1,9 +1,98 @@
 package database
 
+import "strings"
+
 type Checklist struct {
 	Model
-	Description string `json:"description"`
-	Done        bool   `json:"done"`
-	TodoID      uint   `json:"todo_id"`
-	Todo        Todo   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Description string      `json:"description"`
+	Done        bool        `json:"done"`
+	Position    string      `gorm:"index" json:"position"`
+	TodoID      uint        `json:"todo_id"`
+	Todo        Todo        `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	ParentID    *uint       `gorm:"index" json:"parent_id"`
+	Children    []Checklist `gorm:"-" json:"children"`
+}
+
+// ChecklistProgress counts a todo's checklist items, sub-items included.
+type ChecklistProgress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total"`
+}
+
+// checklistIndent is the indentation of one level of sub-items when a checklist is written out as text.
+const checklistIndent = "  "
+
+// checklistFromContent turns the content of a text todo into checklist items, one per non-blank line. A line may
+// be written the way checklistContent writes items, "- [x] " marking it done and each level of indentation nesting
+// it under the item above.
+func checklistFromContent(content string) []Checklist {
+	var roots []Checklist
+	//path holds the index of the last item at each depth, leading from the roots to the item added last
+	var path []int
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+
+		indent := strings.ReplaceAll(line[:len(line)-len(trimmed)], "\t", checklistIndent)
+		depth := min(len(indent)/len(checklistIndent), len(path))
+
+		item := Checklist{Description: strings.TrimSpace(trimmed)}
+		for _, bullet := range []string{"- ", "* "} {
+			if rest, ok := strings.CutPrefix(item.Description, bullet); ok && strings.HasPrefix(rest, "[") {
+				item.Description = rest
+				break
+			}
+		}
+		for _, marker := range []string{"[ ] ", "[x] ", "[X] "} {
+			if rest, ok := strings.CutPrefix(item.Description, marker); ok && strings.TrimSpace(rest) != "" {
+				item.Description = strings.TrimSpace(rest)
+				item.Done = marker != "[ ] "
+				break
+			}
+		}
+
+		siblings := &roots
+		for _, index := range path[:depth] {
+			siblings = &(*siblings)[index].Children
+		}
+		*siblings = append(*siblings, item)
+		path = append(path[:depth], len(*siblings)-1)
+	}
+	return roots
+}
+
+// checklistContent writes checklist items out as text, one "- [ ] " or "- [x] " line per item with sub-items
+// indented under their parent. items must be ordered by position.
+func checklistContent(items []Checklist) string {
+	children := make(map[uint][]Checklist)
+	ids := make(map[uint]bool, len(items))
+	for _, item := range items {
+		ids[item.ID] = true
+	}
+
+	var roots []Checklist
+	for _, item := range items {
+		if item.ParentID != nil && ids[*item.ParentID] {
+			children[*item.ParentID] = append(children[*item.ParentID], item)
+			continue
+		}
+		roots = append(roots, item)
+	}
+
+	var lines []string
+	var write func(items []Checklist, depth int)
+	write = func(items []Checklist, depth int) {
+		for _, item := range items {
+			marker := "[ ]"
+			if item.Done {
+				marker = "[x]"
+			}
+			lines = append(lines, strings.Repeat(checklistIndent, depth)+"- "+marker+" "+item.Description)
+			write(children[item.ID], depth+1)
+		}
+	}
+	write(roots, 0)
+	return strings.Join(lines, "\n")
 }
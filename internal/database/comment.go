@@ -0,0 +1,17 @@
+package database
+
+type Comment struct {
+	Model
+	TodoID uint          `gorm:"index" json:"todo_id"`
+	Todo   Todo          `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID uint          `gorm:"index" json:"user_id"`
+	User   User          `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Body   string        `json:"body"`
+	Author CommentAuthor `gorm:"-" json:"author"`
+}
+
+// CommentAuthor is the part of a comment author's account shown alongside their comments.
+type CommentAuthor struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+)
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, createCommentDto *dtos.CreateCommentDTO) (uint, error)
+	UpdateComment(ctx context.Context, commentId uint, updateCommentDto *dtos.UpdateCommentDTO) error
+	DeleteComment(ctx context.Context, commentId uint) error
+	FindComment(ctx context.Context, commentId uint, todoId uint) (*Comment, error)
+	FetchComments(ctx context.Context, todoId uint, paginationOptions dtos.PaginationOptions) (dtos.PaginatedResponse[Comment], error)
+	StreamUserComments(ctx context.Context, userId uint, fn func([]Comment) error) error
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+var commentListing = listing{
+	Resource: "comments",
+	// oldest first, so a thread reads top to bottom
+	DefaultOrder: "created_at asc, id asc",
+}
+
+func (repo *commentRepository) CreateComment(ctx context.Context, createCommentDto *dtos.CreateCommentDTO) (uint, error) {
+	comment := Comment{
+		TodoID: createCommentDto.TodoID,
+		UserID: createCommentDto.UserID,
+		Body:   createCommentDto.Body,
+	}
+
+	result := repo.db.WithContext(ctx).Create(&comment)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return comment.ID, nil
+}
+
+func (repo *commentRepository) UpdateComment(ctx context.Context, commentId uint, updateCommentDto *dtos.UpdateCommentDTO) error {
+	result := repo.db.WithContext(ctx).Model(&Comment{}).Where("id = ?", commentId).Update("body", updateCommentDto.Body)
+	if result.Error != nil {
+		log.Println("Error while updating comment", result.Error)
+		return errors.New("unable to update comment, please try again")
+	}
+	return nil
+}
+
+func (repo *commentRepository) DeleteComment(ctx context.Context, commentId uint) error {
+	result := repo.db.WithContext(ctx).Where("id = ?", commentId).Delete(&Comment{})
+	if result.Error != nil {
+		log.Println("Error while deleting comment", result.Error)
+		return errors.New("unable to delete comment, please try again")
+	}
+	return nil
+}
+
+func (repo *commentRepository) FindComment(ctx context.Context, commentId uint, todoId uint) (*Comment, error) {
+	comment := Comment{}
+	result := repo.db.WithContext(ctx).Where("id = ?", commentId).Where("todo_id = ?", todoId).First(&comment)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, result.Error
+	}
+	return &comment, nil
+}
+
+func (repo *commentRepository) FetchComments(ctx context.Context, todoId uint, paginationOptions dtos.PaginationOptions) (dtos.PaginatedResponse[Comment], error) {
+	query := repo.db.WithContext(ctx).
+		Model(&Comment{}).
+		Preload("User").
+		Where("todo_id = ?", todoId)
+
+	response, err := paginate[Comment](query, paginationOptions, commentListing)
+	if err != nil {
+		return response, err
+	}
+
+	for i := range response.Data {
+		response.Data[i].Author = CommentAuthor{
+			FirstName: response.Data[i].User.FirstName,
+			LastName:  response.Data[i].User.LastName,
+		}
+	}
+	return response, nil
+}
+
+// StreamUserComments hands fn the comments the user wrote, a batch at a time.
+func (repo *commentRepository) StreamUserComments(ctx context.Context, userId uint, fn func([]Comment) error) error {
+	return streamInBatches(repo.db.WithContext(ctx).Where("user_id = ?", userId), fn)
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"log"
+	"reflect"
+	"slices"
+)
+
+// cursorSecret signs pagination cursors. Without a CURSOR_SECRET it derives a key of its own from the JWT secret, so
+// cursors work without extra configuration but are never signed with the key that signs access tokens.
+func cursorSecret() string {
+	if secret := env.FetchString("CURSOR_SECRET", ""); secret != "" {
+		return secret
+	}
+
+	mac := hmac.New(sha256.New, []byte(env.FetchString("JWT_SECRET")))
+	mac.Write([]byte("cursor"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// paginateByCursor pages through query by keyset rather than offset: each page starts after the sort value and id of
+// the last record of the page before, so records added or removed meanwhile do not shift later pages. Records are
+// ordered by the requested sort field with id breaking ties; the listing's default order does not apply.
+func paginateByCursor[T any](query *gorm.DB, paginationOptions dtos.PaginationOptions, listing listing, total int64) (dtos.PaginatedResponse[T], error) {
+	var records []T
+	var response dtos.PaginatedResponse[T]
+
+	cursor := dtos.Cursor{SortBy: paginationOptions.SortBy, Order: paginationOptions.Order}
+	if paginationOptions.Cursor != "" {
+		var err error
+		cursor, err = dtos.DecodeCursor(paginationOptions.Cursor, cursorSecret())
+		if err != nil {
+			return response, err
+		}
+		paginationOptions.SortBy, paginationOptions.Order = cursor.SortBy, cursor.Order
+		paginationOptions.ValidateSortField()
+	}
+
+	statement := &gorm.Statement{DB: query}
+	if err := statement.Parse(new(T)); err != nil {
+		log.Println("Error while parsing model for cursor pagination", err)
+		return response, fmt.Errorf("error while fetching all %s", listing.Resource)
+	}
+	sortField := statement.Schema.LookUpField(paginationOptions.SortBy)
+	idField := statement.Schema.PrioritizedPrimaryField
+	if sortField == nil || idField == nil {
+		return response, fmt.Errorf("%s cannot be sorted by %s with a cursor", listing.Resource, paginationOptions.SortBy)
+	}
+
+	sortBy := paginationOptions.SortBy
+	if expression, ok := listing.SortExpressions[sortBy]; ok {
+		sortBy = expression
+	}
+
+	// walking backwards flips the order, and the page is put back the right way round once fetched
+	order := paginationOptions.Order
+	if cursor.Backward {
+		order = reverseOrder(order)
+	}
+
+	if paginationOptions.Cursor != "" {
+		value, err := cursorValue(cursor.Value, sortField)
+		if err != nil {
+			return response, err
+		}
+		boundSortBy := "?"
+		if sortBy != paginationOptions.SortBy {
+			//evaluate the sort expression against the cursor's value, as it is for each row
+			boundSortBy = fmt.Sprintf("(SELECT %s FROM (SELECT ? AS %s))", sortBy, paginationOptions.SortBy)
+		}
+		query = keyset(query, sortBy, boundSortBy, idField.DBName, order, value, cursor.ID)
+	}
+
+	orderBy := fmt.Sprintf("%s %s", idField.DBName, order)
+	if paginationOptions.SortBy != idField.DBName {
+		orderBy = fmt.Sprintf("%s %s, %s", sortBy, order, orderBy)
+	}
+
+	result := query.
+		Limit(paginationOptions.PerPage + 1).
+		Order(orderBy).
+		Find(&records)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return response, fmt.Errorf("%s not found", listing.Resource)
+		}
+		return response, fmt.Errorf("error while fetching all %s", listing.Resource)
+	}
+
+	hasMore := len(records) > paginationOptions.PerPage
+	if hasMore {
+		records = records[:paginationOptions.PerPage]
+	}
+	if cursor.Backward {
+		slices.Reverse(records)
+	}
+
+	meta := dtos.PaginatedResponseMeta{
+		TotalCount: int(total),
+		PerPage:    paginationOptions.PerPage,
+	}
+	if len(records) > 0 {
+		// a page reached by walking forwards always has one before it, and one reached walking backwards one after it
+		hasNext, hasPrev := hasMore, paginationOptions.Cursor != ""
+		if cursor.Backward {
+			hasNext, hasPrev = true, hasMore
+		}
+
+		var err error
+		if hasNext {
+			meta.NextCursor, err = recordCursor(&records[len(records)-1], paginationOptions, sortField, idField, false)
+		}
+		if hasPrev && err == nil {
+			meta.PrevCursor, err = recordCursor(&records[0], paginationOptions, sortField, idField, true)
+		}
+		if err != nil {
+			log.Println("Error while encoding pagination cursor", err)
+			return response, fmt.Errorf("error while fetching all %s", listing.Resource)
+		}
+	}
+
+	response = dtos.PaginatedResponse[T]{
+		Data: records,
+		Meta: meta,
+	}
+	return response, nil
+}
+
+func reverseOrder(order dtos.Order) dtos.Order {
+	if order == dtos.OrderDesc {
+		return dtos.OrderAsc
+	}
+	return dtos.OrderDesc
+}
+
+// keyset limits query to the records that come after (value, id) in the given order, boundSortBy being the SQL the
+// value is bound through. NULL sort values come first when ascending and last when descending, as SQLite sorts them.
+func keyset(query *gorm.DB, sortBy string, boundSortBy string, idColumn string, order dtos.Order, value interface{}, id uint) *gorm.DB {
+	comparison := ">"
+	if order == dtos.OrderDesc {
+		comparison = "<"
+	}
+
+	if sortBy == idColumn {
+		return query.Where(fmt.Sprintf("%s %s ?", idColumn, comparison), id)
+	}
+
+	switch {
+	case value == nil && order == dtos.OrderAsc:
+		return query.Where(fmt.Sprintf("((%s IS NULL AND %s > ?) OR %s IS NOT NULL)", sortBy, idColumn, sortBy), id)
+	case value == nil:
+		return query.Where(fmt.Sprintf("(%s IS NULL AND %s < ?)", sortBy, idColumn), id)
+	case order == dtos.OrderAsc:
+		return query.Where(
+			fmt.Sprintf("(%s > %s OR (%s = %s AND %s > ?))", sortBy, boundSortBy, sortBy, boundSortBy, idColumn),
+			value, value, id,
+		)
+	default:
+		return query.Where(
+			fmt.Sprintf("(%s < %s OR (%s = %s AND %s < ?) OR %s IS NULL)", sortBy, boundSortBy, sortBy, boundSortBy, idColumn, sortBy),
+			value, value, id,
+		)
+	}
+}
+
+// cursorValue decodes a cursor's sort value into the type of the field it sorts by, so it compares with the column
+// the same way a value read from the database would. It is nil for a NULL value.
+func cursorValue(raw json.RawMessage, field *schema.Field) (interface{}, error) {
+	value := reflect.New(field.FieldType)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return nil, dtos.ErrInvalidCursor
+	}
+
+	decoded := value.Elem()
+	if decoded.Kind() == reflect.Ptr {
+		if decoded.IsNil() {
+			return nil, nil
+		}
+		decoded = decoded.Elem()
+	}
+	return decoded.Interface(), nil
+}
+
+// recordCursor makes a signed cursor pointing after record, or before it when backward is set.
+func recordCursor(record interface{}, paginationOptions dtos.PaginationOptions, sortField *schema.Field, idField *schema.Field, backward bool) (*string, error) {
+	recordValue := reflect.ValueOf(record).Elem()
+	sortValue, _ := sortField.ValueOf(context.Background(), recordValue)
+	id, _ := idField.ValueOf(context.Background(), recordValue)
+
+	value, err := json.Marshal(sortValue)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := dtos.Cursor{
+		SortBy:   paginationOptions.SortBy,
+		Order:    paginationOptions.Order,
+		Value:    value,
+		ID:       id.(uint),
+		Backward: backward,
+	}.Encode(cursorSecret())
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,20 @@
+package database
+
+import "gorm.io/gorm"
+
+// exportBatchSize is how many records are loaded at a time while a user's data is streamed out.
+const exportBatchSize = 500
+
+// streamInBatches hands fn the records matched by query a batch at a time, in id order, so that a large account
+// never has to be loaded into memory all at once.
+func streamInBatches[T any](query *gorm.DB, fn func([]T) error) error {
+	var batch []T
+	return query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// ownedTodoIds is a subquery of the ids of the todos a user created, trashed ones included.
+func ownedTodoIds(db *gorm.DB, userId uint) *gorm.DB {
+	return db.Unscoped().Model(&Todo{}).Select("id").Where("user_id = ?", userId)
+}
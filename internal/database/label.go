@@ -0,0 +1,9 @@
+package database
+
+type Label struct {
+	Model
+	Name   string `json:"name"`
+	Colour string `json:"colour"`
+	UserID uint   `gorm:"index" json:"user_id"`
+	User   User   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
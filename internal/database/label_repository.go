@@ -0,0 +1,109 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+)
+
+type LabelRepository interface {
+	CreateLabel(ctx context.Context, createLabelDto *dtos.CreateLabelDTO) (uint, error)
+	UpdateLabel(ctx context.Context, labelId uint, updateLabelDto *dtos.UpdateLabelDTO) error
+	DeleteLabel(ctx context.Context, labelId uint) error
+	FindLabelByUserId(ctx context.Context, labelId uint, userId uint) (*Label, error)
+	FindLabelByName(ctx context.Context, name string, userId uint) (*Label, error)
+	FetchLabels(ctx context.Context, userId uint) ([]Label, error)
+	CountUserLabels(ctx context.Context, labelIds []uint, userId uint) int64
+}
+
+type labelRepository struct {
+	db *gorm.DB
+}
+
+func NewLabelRepository(db *gorm.DB) LabelRepository {
+	return &labelRepository{db: db}
+}
+
+func (repo *labelRepository) CreateLabel(ctx context.Context, createLabelDto *dtos.CreateLabelDTO) (uint, error) {
+	label := Label{
+		Name:   createLabelDto.Name,
+		Colour: createLabelDto.Colour,
+		UserID: createLabelDto.UserID,
+	}
+
+	result := repo.db.WithContext(ctx).Create(&label)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return label.ID, nil
+}
+
+func (repo *labelRepository) UpdateLabel(ctx context.Context, labelId uint, updateLabelDto *dtos.UpdateLabelDTO) error {
+	result := repo.db.WithContext(ctx).Model(&Label{}).Where("id = ?", labelId).Updates(Label{
+		Name:   updateLabelDto.Name,
+		Colour: updateLabelDto.Colour,
+	})
+	if result.Error != nil {
+		log.Println("Error while updating label", result.Error)
+		return errors.New("unable to update label")
+	}
+	return nil
+}
+
+func (repo *labelRepository) DeleteLabel(ctx context.Context, labelId uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM todo_labels WHERE label_id = ?", labelId).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Label{}, labelId).Error
+	})
+
+	if err != nil {
+		log.Println("Error while deleting label", err)
+		return errors.New("unable to delete label")
+	}
+	return nil
+}
+
+func (repo *labelRepository) FindLabelByUserId(ctx context.Context, labelId uint, userId uint) (*Label, error) {
+	label := Label{}
+	result := repo.db.WithContext(ctx).Where("id = ?", labelId).Where("user_id = ?", userId).First(&label)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("label not found")
+		}
+		return nil, result.Error
+	}
+	return &label, nil
+}
+
+func (repo *labelRepository) FindLabelByName(ctx context.Context, name string, userId uint) (*Label, error) {
+	label := Label{}
+	result := repo.db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).Where("user_id = ?", userId).First(&label)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &label, nil
+}
+
+func (repo *labelRepository) FetchLabels(ctx context.Context, userId uint) ([]Label, error) {
+	labels := make([]Label, 0)
+	result := repo.db.WithContext(ctx).Where("user_id = ?", userId).Order("name asc").Find(&labels)
+	if result.Error != nil {
+		log.Println("Error while fetching labels", result.Error)
+		return nil, errors.New("error while fetching labels")
+	}
+	return labels, nil
+}
+
+func (repo *labelRepository) CountUserLabels(ctx context.Context, labelIds []uint, userId uint) int64 {
+	var count int64
+	repo.db.WithContext(ctx).
+		Model(&Label{}).
+		Where("id IN ?", labelIds).
+		Where("user_id = ?", userId).
+		Count(&count)
+	return count
+}
@@ -0,0 +1,9 @@
+package database
+
+// OrphanedBlob is the storage key of an attachment blob whose record has been deleted. It is written in the same
+// transaction as the deletion and only removed once the blob is gone from storage, so a blob that cannot be deleted
+// straight away is retried instead of being left behind.
+type OrphanedBlob struct {
+	Model
+	StorageKey string `gorm:"uniqueIndex"`
+}
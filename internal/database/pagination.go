@@ -0,0 +1,150 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"gorm.io/gorm"
+	"math"
+	"strings"
+)
+
+// likeEscaper escapes LIKE wildcards so like filters match the text they are given literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// filterCondition applies a filter with an operator on a column, its value having been converted by
+// dtos.PaginationOptions.ConvertCondition.
+func filterCondition(query *gorm.DB, condition dtos.FilterCondition, value interface{}) *gorm.DB {
+	column := condition.Field
+	var expression string
+	var args []interface{}
+
+	switch condition.Operator {
+	case dtos.LikeOperator:
+		expression = fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, column)
+		args = []interface{}{"%" + likeEscaper.Replace(value.(string)) + "%"}
+	case dtos.InOperator:
+		expression = fmt.Sprintf("%s IN ?", column)
+		args = []interface{}{value}
+	case dtos.GreaterThanOperator:
+		expression = fmt.Sprintf("%s > ?", column)
+		args = []interface{}{value}
+	case dtos.GreaterOrEqualOperator:
+		expression = fmt.Sprintf("%s >= ?", column)
+		args = []interface{}{value}
+	case dtos.LessThanOperator:
+		expression = fmt.Sprintf("%s < ?", column)
+		args = []interface{}{value}
+	case dtos.LessOrEqualOperator:
+		expression = fmt.Sprintf("%s <= ?", column)
+		args = []interface{}{value}
+	case dtos.BetweenOperator:
+		expression = fmt.Sprintf("%s BETWEEN ? AND ?", column)
+		args = value.([]interface{})
+	case dtos.NullOperator:
+		expression = fmt.Sprintf("%s IS NULL", column)
+		if !value.(bool) {
+			expression = fmt.Sprintf("%s IS NOT NULL", column)
+		}
+	default:
+		expression = fmt.Sprintf("%s = ?", column)
+		args = []interface{}{value}
+	}
+
+	if condition.Negated {
+		return query.Not(expression, args...)
+	}
+	return query.Where(expression, args...)
+}
+
+// filterScope applies a filter that does not map directly onto a single column equality.
+type filterScope func(query *gorm.DB, value interface{}) *gorm.DB
+
+// listing describes how a resource is filtered and sorted when it is paginated.
+type listing struct {
+	// Resource names the records in error messages, e.g. "todos"
+	Resource string
+	// FilterScopes apply filters that are not a single column equality
+	FilterScopes map[string]filterScope
+	// SortExpressions map sort fields that are not plain columns onto the SQL they sort by
+	SortExpressions map[string]string
+	// DefaultOrder is used instead of sorting by id when no sort_by was requested
+	DefaultOrder string
+}
+
+// paginate applies the filters, sorting and paging in paginationOptions to query, which should already be scoped
+// to the records the caller may see. Filters found in the listing's scopes are applied through them, any other
+// filter is treated as a column equality. Filters with an operator only apply to plain columns. Pages are counted
+// by offset unless a cursor was asked for.
+func paginate[T any](query *gorm.DB, paginationOptions dtos.PaginationOptions, listing listing) (dtos.PaginatedResponse[T], error) {
+	useDefaultOrder := paginationOptions.SortBy == "" && listing.DefaultOrder != ""
+	paginationOptions.Configure()
+	var records []T
+	var total int64
+	var response dtos.PaginatedResponse[T]
+
+	for column, _ := range paginationOptions.Filters {
+		filter, err := paginationOptions.ConvertFilter(column)
+		if err != nil {
+			return response, err
+		}
+		if scope, ok := listing.FilterScopes[column]; ok {
+			query = scope(query, filter)
+			continue
+		}
+		query = query.Where(fmt.Sprintf("%s = ?", column), filter)
+	}
+
+	for _, condition := range paginationOptions.Conditions {
+		if _, ok := listing.FilterScopes[condition.Field]; ok {
+			return response, fmt.Errorf("filter %s does not support the %s operator", condition.Field, condition.Operator)
+		}
+		value, err := paginationOptions.ConvertCondition(condition)
+		if err != nil {
+			return response, err
+		}
+		query = filterCondition(query, condition, value)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return response, fmt.Errorf("error while counting %s", listing.Resource)
+	}
+
+	if paginationOptions.UseCursor {
+		return paginateByCursor[T](query, paginationOptions, listing, total)
+	}
+
+	order := listing.DefaultOrder
+	if !useDefaultOrder {
+		sortBy := paginationOptions.SortBy
+		if expression, ok := listing.SortExpressions[sortBy]; ok {
+			sortBy = expression
+		}
+		order = fmt.Sprintf("%v %v", sortBy, paginationOptions.Order)
+	}
+
+	result := query.
+		Offset(paginationOptions.Offset()).
+		Limit(paginationOptions.PerPage).
+		Order(order).
+		Find(&records)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return response, fmt.Errorf("%s not found", listing.Resource)
+		}
+		return response, fmt.Errorf("error while fetching all %s", listing.Resource)
+	}
+
+	response = dtos.PaginatedResponse[T]{
+		Data: records,
+		Meta: dtos.PaginatedResponseMeta{
+			TotalCount:  int(total),
+			FirstPage:   1,
+			CurrentPage: paginationOptions.Page,
+			LastPage:    int(math.Ceil(float64(total) / float64(paginationOptions.PerPage))),
+			PerPage:     paginationOptions.PerPage,
+		},
+	}
+	return response, nil
+}
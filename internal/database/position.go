@@ -0,0 +1,136 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+// positionScope narrows a table down to the list a record is ordered within, e.g. a user's todos.
+type positionScope func(query *gorm.DB) *gorm.DB
+
+// lastPosition returns a position after every record in the list.
+func lastPosition(tx *gorm.DB, model interface{}, scope positionScope) (string, error) {
+	var last *string
+	result := scope(tx.Model(model)).Select("MAX(position)").Scan(&last)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	if last == nil {
+		return pkg.RankBetween("", "")
+	}
+	return pkg.RankBetween(*last, "")
+}
+
+// movePosition moves record id so it sits directly after afterId and/or directly before beforeId. Only the
+// moved record is rewritten, unless its neighbours share a position, in which case the list is rebalanced first.
+func movePosition(tx *gorm.DB, model interface{}, scope positionScope, id uint, afterId *uint, beforeId *uint) error {
+	position, err := positionBetween(tx, model, scope, id, afterId, beforeId)
+	if errors.Is(err, pkg.ErrNoRankBetween) {
+		if err = rebalancePositions(tx, model, scope); err != nil {
+			return err
+		}
+		position, err = positionBetween(tx, model, scope, id, afterId, beforeId)
+	}
+	if err != nil {
+		return err
+	}
+
+	return scope(tx.Model(model)).Where("id = ?", id).Update("position", position).Error
+}
+
+func positionBetween(tx *gorm.DB, model interface{}, scope positionScope, id uint, afterId *uint, beforeId *uint) (string, error) {
+	type neighbour struct {
+		ID       uint
+		Position string
+	}
+
+	find := func(neighbourId uint) (*neighbour, error) {
+		var found neighbour
+		result := scope(tx.Model(model)).Select("id", "position").Where("id = ?", neighbourId).Take(&found)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		return &found, nil
+	}
+
+	var before, after *neighbour
+	var err error
+	if afterId != nil {
+		if before, err = find(*afterId); err != nil {
+			return "", err
+		}
+	}
+	if beforeId != nil {
+		if after, err = find(*beforeId); err != nil {
+			return "", err
+		}
+	}
+
+	//with only one neighbour given, the other is whatever currently sits next to it
+	if before != nil && after == nil {
+		var next neighbour
+		result := scope(tx.Model(model)).
+			Select("id", "position").
+			Where("id <> ?", id).
+			Where("position > ? OR (position = ? AND id > ?)", before.Position, before.Position, before.ID).
+			Order("position asc, id asc").
+			Limit(1).
+			Find(&next)
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected > 0 {
+			after = &next
+		}
+	}
+
+	if after != nil && before == nil {
+		var previous neighbour
+		result := scope(tx.Model(model)).
+			Select("id", "position").
+			Where("id <> ?", id).
+			Where("position < ? OR (position = ? AND id < ?)", after.Position, after.Position, after.ID).
+			Order("position desc, id desc").
+			Limit(1).
+			Find(&previous)
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected > 0 {
+			before = &previous
+		}
+	}
+
+	lower, upper := "", ""
+	if before != nil {
+		lower = before.Position
+	}
+	if after != nil {
+		upper = after.Position
+	}
+
+	//records that predate ordering have no position, and an empty upper bound would mean the end of the list
+	if after != nil && upper == "" {
+		return "", pkg.ErrNoRankBetween
+	}
+	return pkg.RankBetween(lower, upper)
+}
+
+// rebalancePositions spreads the list's positions out evenly, keeping its current order.
+func rebalancePositions(tx *gorm.DB, model interface{}, scope positionScope) error {
+	var ids []uint
+	result := scope(tx.Model(model)).Order("position asc, id asc").Pluck("id", &ids)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	for i, position := range pkg.SpreadRanks(len(ids)) {
+		result = tx.Model(model).Where("id = ?", ids[i]).Update("position", position)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
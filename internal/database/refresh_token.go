@@ -0,0 +1,17 @@
+package database
+
+import "time"
+
+// RefreshToken trades in for a new access token. Every refresh rotates it, and the tokens descending from one login
+// share a family, so that a token used twice reveals it was stolen and the whole family can be revoked. Only a hash
+// of the token is kept.
+type RefreshToken struct {
+	Model
+	TokenHash string `gorm:"uniqueIndex"`
+	FamilyID  string `gorm:"index"`
+	UserID    uint   `gorm:"index"`
+	User      User   `gorm:"constraint:OnDelete:CASCADE"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, refreshToken *RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, refreshTokenId uint, next *RefreshToken) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyId string) error
+	RevokeUserRefreshTokens(ctx context.Context, userId uint) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (repo *refreshTokenRepository) CreateRefreshToken(ctx context.Context, refreshToken *RefreshToken) error {
+	result := repo.db.WithContext(ctx).Create(refreshToken)
+	if result.Error != nil {
+		log.Println("Error while creating refresh token", result.Error)
+		return errors.New("unable to create refresh token, please try again")
+	}
+	return nil
+}
+
+func (repo *refreshTokenRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	refreshToken := RefreshToken{}
+	result := repo.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&refreshToken)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, result.Error
+	}
+	return &refreshToken, nil
+}
+
+// RotateRefreshToken marks a refresh token as used and creates the one replacing it. It reports false without
+// creating anything when the token was used or revoked in the meantime, so that two requests racing with the same
+// token cannot both succeed.
+func (repo *refreshTokenRepository) RotateRefreshToken(ctx context.Context, refreshTokenId uint, next *RefreshToken) (bool, error) {
+	rotated := false
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&RefreshToken{}).
+			Where("id = ?", refreshTokenId).
+			Where("used_at IS NULL AND revoked_at IS NULL").
+			UpdateColumn("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		log.Println("Error while rotating refresh token", err)
+		return false, errors.New("unable to refresh token, please try again")
+	}
+	return rotated, nil
+}
+
+func (repo *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyId string) error {
+	result := repo.db.WithContext(ctx).
+		Model(&RefreshToken{}).
+		Where("family_id = ?", familyId).
+		Where("revoked_at IS NULL").
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Println("Error while revoking refresh token family", result.Error)
+		return errors.New("unable to revoke refresh tokens")
+	}
+	return nil
+}
+
+func (repo *refreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userId uint) error {
+	result := repo.db.WithContext(ctx).
+		Model(&RefreshToken{}).
+		Where("user_id = ?", userId).
+		Where("revoked_at IS NULL").
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Println("Error while revoking refresh tokens", result.Error)
+		return errors.New("unable to revoke refresh tokens")
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"time"
+)
+
+type Reminder struct {
+	Model
+	TodoID        uint       `gorm:"index" json:"todo_id"`
+	Todo          Todo       `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID        uint       `json:"user_id"`
+	User          User       `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	RemindAt      time.Time  `gorm:"index" json:"remind_at"`
+	OffsetMinutes *int       `json:"offset_minutes"` // set when the reminder is relative to the todo's due date
+	SentAt        *time.Time `gorm:"index" json:"sent_at"`
+	// Attempts counts the deliveries that failed, the last of them with LastError. The reminder is not tried again
+	// before RetryAt.
+	Attempts  int        `gorm:"default:0" json:"attempts"`
+	LastError *string    `json:"last_error"`
+	RetryAt   *time.Time `json:"retry_at"`
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type ReminderRepository interface {
+	CreateReminder(ctx context.Context, createReminderDto *dtos.CreateReminderDTO) (uint, error)
+	FetchTodoReminders(ctx context.Context, todoId uint) ([]Reminder, error)
+	DeleteReminder(ctx context.Context, reminderId uint, todoId uint) error
+	FetchDueReminders(ctx context.Context, before time.Time, maxAttempts int, limit int) ([]Reminder, error)
+	MarkReminderSent(ctx context.Context, reminderId uint, sentAt time.Time) (bool, error)
+	RecordFailedDelivery(ctx context.Context, reminderId uint, deliveryError string, retryAt time.Time) error
+	RescheduleOffsetReminders(ctx context.Context, todoId uint, dueAt *time.Time) error
+	CopyOffsetReminders(ctx context.Context, fromTodoId uint, toTodoId uint, dueAt time.Time) error
+	StreamUserReminders(ctx context.Context, userId uint, fn func([]Reminder) error) error
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+func (repo *reminderRepository) CreateReminder(ctx context.Context, createReminderDto *dtos.CreateReminderDTO) (uint, error) {
+	reminder := Reminder{
+		TodoID:        createReminderDto.TodoID,
+		UserID:        createReminderDto.UserID,
+		RemindAt:      createReminderDto.RemindAt.UTC(),
+		OffsetMinutes: createReminderDto.OffsetMinutes,
+	}
+
+	result := repo.db.WithContext(ctx).Create(&reminder)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return reminder.ID, nil
+}
+
+func (repo *reminderRepository) FetchTodoReminders(ctx context.Context, todoId uint) ([]Reminder, error) {
+	var reminders []Reminder
+	result := repo.db.WithContext(ctx).Where("todo_id = ?", todoId).Order("remind_at asc").Find(&reminders)
+	if result.Error != nil {
+		log.Println("Error while fetching reminders", result.Error)
+		return nil, errors.New("error while fetching reminders")
+	}
+	return reminders, nil
+}
+
+func (repo *reminderRepository) DeleteReminder(ctx context.Context, reminderId uint, todoId uint) error {
+	result := repo.db.WithContext(ctx).Where("id = ?", reminderId).Where("todo_id = ?", todoId).Delete(&Reminder{})
+	if result.Error != nil {
+		log.Println("Error while deleting reminder", result.Error)
+		return errors.New("error while deleting reminder")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("reminder not found")
+	}
+	return nil
+}
+
+// FetchDueReminders returns unsent reminders that were due before the given time, skipping reminders of deleted,
+// archived and completed todos, reminders waiting to be retried and ones that already failed maxAttempts times.
+func (repo *reminderRepository) FetchDueReminders(ctx context.Context, before time.Time, maxAttempts int, limit int) ([]Reminder, error) {
+	before = before.UTC()
+	var reminders []Reminder
+	result := repo.db.WithContext(ctx).
+		Joins("JOIN todos ON todos.id = reminders.todo_id AND todos.deleted_at IS NULL AND todos.archived_at IS NULL AND todos.completed = false").
+		Preload("Todo").
+		Preload("User").
+		Where("reminders.sent_at IS NULL").
+		Where("reminders.remind_at <= ?", before).
+		Where("reminders.attempts < ?", maxAttempts).
+		Where("reminders.retry_at IS NULL OR reminders.retry_at <= ?", before).
+		Order("reminders.remind_at asc").
+		Limit(limit).
+		Find(&reminders)
+	if result.Error != nil {
+		log.Println("Error while fetching due reminders", result.Error)
+		return nil, errors.New("error while fetching due reminders")
+	}
+	return reminders, nil
+}
+
+// MarkReminderSent records that a reminder was delivered, returning false if it had already been marked sent.
+func (repo *reminderRepository) MarkReminderSent(ctx context.Context, reminderId uint, sentAt time.Time) (bool, error) {
+	result := repo.db.WithContext(ctx).
+		Model(&Reminder{}).
+		Where("id = ?", reminderId).
+		Where("sent_at IS NULL").
+		Update("sent_at", sentAt)
+	if result.Error != nil {
+		log.Println("Error while marking reminder as sent", result.Error)
+		return false, errors.New("error while marking reminder as sent")
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordFailedDelivery counts a failed attempt at delivering a reminder and holds it back until retryAt.
+func (repo *reminderRepository) RecordFailedDelivery(ctx context.Context, reminderId uint, deliveryError string, retryAt time.Time) error {
+	result := repo.db.WithContext(ctx).
+		Model(&Reminder{}).
+		Where("id = ?", reminderId).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": deliveryError,
+			"retry_at":   retryAt.UTC(),
+		})
+	if result.Error != nil {
+		log.Println("Error while recording a failed reminder delivery", result.Error)
+		return errors.New("error while recording a failed reminder delivery")
+	}
+	return nil
+}
+
+// RescheduleOffsetReminders moves unsent reminders that are relative to a todo's due date after the due date changes.
+// Relative reminders are removed when the todo no longer has a due date.
+func (repo *reminderRepository) RescheduleOffsetReminders(ctx context.Context, todoId uint, dueAt *time.Time) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("todo_id = ?", todoId).Where("offset_minutes IS NOT NULL").Where("sent_at IS NULL")
+		if dueAt == nil {
+			return query.Delete(&Reminder{}).Error
+		}
+
+		var reminders []Reminder
+		if err := query.Find(&reminders).Error; err != nil {
+			return err
+		}
+
+		for _, reminder := range reminders {
+			remindAt := dueAt.Add(-time.Duration(*reminder.OffsetMinutes) * time.Minute).UTC()
+			//a reminder moved to a new time gets a fresh set of delivery attempts
+			result := tx.Model(&Reminder{}).Where("id = ?", reminder.ID).Updates(map[string]interface{}{
+				"remind_at":  remindAt,
+				"attempts":   0,
+				"last_error": nil,
+				"retry_at":   nil,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
+// CopyOffsetReminders carries the relative reminders of a recurring todo over to its next occurrence.
+func (repo *reminderRepository) CopyOffsetReminders(ctx context.Context, fromTodoId uint, toTodoId uint, dueAt time.Time) error {
+	var reminders []Reminder
+	result := repo.db.WithContext(ctx).Where("todo_id = ?", fromTodoId).Where("offset_minutes IS NOT NULL").Find(&reminders)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	copies := make([]Reminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		copies = append(copies, Reminder{
+			TodoID:        toTodoId,
+			UserID:        reminder.UserID,
+			RemindAt:      dueAt.Add(-time.Duration(*reminder.OffsetMinutes) * time.Minute).UTC(),
+			OffsetMinutes: reminder.OffsetMinutes,
+		})
+	}
+	return repo.db.WithContext(ctx).Create(&copies).Error
+}
+
+// StreamUserReminders hands fn the reminders the user set, a batch at a time.
+func (repo *reminderRepository) StreamUserReminders(ctx context.Context, userId uint, fn func([]Reminder) error) error {
+	return streamInBatches(repo.db.WithContext(ctx).Where("user_id = ?", userId), fn)
+}
@@ -0,0 +1,17 @@
+package database
+
+import "time"
+
+// Session is a device the user logged in on. It lives as long as the refresh token family the login started, which
+// it shares its FamilyID with, and its access tokens stop working as soon as it is revoked.
+type Session struct {
+	Model
+	FamilyID   string     `gorm:"uniqueIndex" json:"-"`
+	UserID     uint       `gorm:"index" json:"user_id"`
+	User       User       `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"-"`
+}
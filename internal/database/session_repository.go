@@ -0,0 +1,148 @@
+package database
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *Session) error
+	TouchSession(ctx context.Context, familyId string, userAgent string, ipAddress string, seenAt time.Time) error
+	MarkSessionSeen(ctx context.Context, familyId string, seenAt time.Time) error
+	CheckSessionRevoked(ctx context.Context, familyId string) bool
+	FetchSessions(ctx context.Context, userId uint, seenSince time.Time) ([]Session, error)
+	FindSessionByUserId(ctx context.Context, sessionId uint, userId uint) (*Session, error)
+	RevokeSession(ctx context.Context, familyId string) error
+	RevokeUserSessions(ctx context.Context, userId uint) error
+}
+
+// sessionSeenInterval is how stale a session's LastSeenAt can get before a request using it records that it was seen,
+// so that every request does not have to write to the database.
+const sessionSeenInterval = 5 * time.Minute
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (repo *sessionRepository) CreateSession(ctx context.Context, session *Session) error {
+	result := repo.db.WithContext(ctx).Create(session)
+	if result.Error != nil {
+		log.Println("Error while creating session", result.Error)
+		return errors.New("unable to create session, please try again")
+	}
+	return nil
+}
+
+// TouchSession records that the session was just used, and from where.
+func (repo *sessionRepository) TouchSession(ctx context.Context, familyId string, userAgent string, ipAddress string, seenAt time.Time) error {
+	result := repo.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("family_id = ?", familyId).
+		Updates(map[string]interface{}{"user_agent": userAgent, "ip_address": ipAddress, "last_seen_at": seenAt})
+	if result.Error != nil {
+		log.Println("Error while updating session", result.Error)
+		return errors.New("unable to update session")
+	}
+	return nil
+}
+
+// MarkSessionSeen records that the session was used at seenAt, unless that was already recorded within the last
+// sessionSeenInterval.
+func (repo *sessionRepository) MarkSessionSeen(ctx context.Context, familyId string, seenAt time.Time) error {
+	var stale int64
+	result := repo.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("family_id = ?", familyId).
+		Where("last_seen_at < ?", seenAt.Add(-sessionSeenInterval)).
+		Count(&stale)
+	if result.Error != nil || stale == 0 {
+		return result.Error
+	}
+
+	result = repo.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("family_id = ?", familyId).
+		UpdateColumn("last_seen_at", seenAt)
+	if result.Error != nil {
+		log.Println("Error while updating session", result.Error)
+		return errors.New("unable to update session")
+	}
+	return nil
+}
+
+// CheckSessionRevoked reports whether the session an access token was issued for has been revoked, which turns off
+// every access token issued for it at once rather than one at a time like the token blacklist.
+func (repo *sessionRepository) CheckSessionRevoked(ctx context.Context, familyId string) bool {
+	var count int64
+	result := repo.db.WithContext(ctx).Model(&Session{}).Where("family_id = ?", familyId).Where("revoked_at IS NOT NULL").Count(&count)
+	if result.Error != nil {
+		return false
+	}
+	return count > 0
+}
+
+// FetchSessions lists the sessions of the user that have not been revoked and were seen since seenSince, the most
+// recently used first.
+func (repo *sessionRepository) FetchSessions(ctx context.Context, userId uint, seenSince time.Time) ([]Session, error) {
+	sessions := make([]Session, 0)
+	result := repo.db.WithContext(ctx).
+		Where("user_id = ?", userId).
+		Where("revoked_at IS NULL").
+		Where("last_seen_at >= ?", seenSince).
+		Order("last_seen_at desc, id desc").
+		Find(&sessions)
+	if result.Error != nil {
+		log.Println("Error while fetching sessions", result.Error)
+		return nil, errors.New("error while fetching sessions")
+	}
+	return sessions, nil
+}
+
+func (repo *sessionRepository) FindSessionByUserId(ctx context.Context, sessionId uint, userId uint) (*Session, error) {
+	session := Session{}
+	result := repo.db.WithContext(ctx).
+		Where("id = ?", sessionId).
+		Where("user_id = ?", userId).
+		Where("revoked_at IS NULL").
+		First(&session)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("session not found")
+		}
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+func (repo *sessionRepository) RevokeSession(ctx context.Context, familyId string) error {
+	result := repo.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("family_id = ?", familyId).
+		Where("revoked_at IS NULL").
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Println("Error while revoking session", result.Error)
+		return errors.New("unable to revoke session")
+	}
+	return nil
+}
+
+func (repo *sessionRepository) RevokeUserSessions(ctx context.Context, userId uint) error {
+	result := repo.db.WithContext(ctx).
+		Model(&Session{}).
+		Where("user_id = ?", userId).
+		Where("revoked_at IS NULL").
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Println("Error while revoking sessions", result.Error)
+		return errors.New("unable to revoke sessions")
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package database
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+// Template is a todo a user creates over and over, with its dates kept as minutes after the moment it is instantiated.
+type Template struct {
+	Model
+	Title              string             `json:"title"`
+	Content            *string            `json:"content"`
+	Type               enums.TodoType     `json:"type"`
+	Checklist          []string           `gorm:"serializer:json" json:"checklist"`
+	Priority           enums.TodoPriority `gorm:"default:none" json:"priority"`
+	LabelIDs           []uint             `gorm:"serializer:json" json:"label_ids"`
+	StartOffsetMinutes *int               `json:"start_offset_minutes"`
+	DueOffsetMinutes   *int               `json:"due_offset_minutes"`
+	UserID             uint               `gorm:"index" json:"user_id"`
+	User               User               `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+)
+
+type TemplateRepository interface {
+	CreateTemplate(ctx context.Context, saveTemplateDto *dtos.SaveTemplateDTO) (uint, error)
+	UpdateTemplate(ctx context.Context, templateId uint, saveTemplateDto *dtos.SaveTemplateDTO) error
+	DeleteTemplate(ctx context.Context, templateId uint) error
+	FindTemplateByUserId(ctx context.Context, templateId uint, userId uint) (*Template, error)
+	FetchTemplates(ctx context.Context, userId uint) ([]Template, error)
+}
+
+type templateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &templateRepository{db: db}
+}
+
+func (repo *templateRepository) CreateTemplate(ctx context.Context, saveTemplateDto *dtos.SaveTemplateDTO) (uint, error) {
+	template := Template{
+		Title:              saveTemplateDto.Title,
+		Content:            saveTemplateDto.Content,
+		Type:               saveTemplateDto.Type,
+		Checklist:          saveTemplateDto.Checklist,
+		Priority:           saveTemplateDto.Priority,
+		LabelIDs:           saveTemplateDto.LabelIDs,
+		StartOffsetMinutes: saveTemplateDto.StartOffsetMinutes,
+		DueOffsetMinutes:   saveTemplateDto.DueOffsetMinutes,
+		UserID:             saveTemplateDto.UserID,
+	}
+
+	result := repo.db.WithContext(ctx).Create(&template)
+	if result.Error != nil {
+		log.Println("Error while creating template", result.Error)
+		return 0, errors.New("unable to create template, please try again")
+	}
+	return template.ID, nil
+}
+
+func (repo *templateRepository) UpdateTemplate(ctx context.Context, templateId uint, saveTemplateDto *dtos.SaveTemplateDTO) error {
+	result := repo.db.WithContext(ctx).
+		Model(&Template{}).
+		Where("id = ?", templateId).
+		Select("Title", "Content", "Type", "Checklist", "Priority", "LabelIDs", "StartOffsetMinutes", "DueOffsetMinutes").
+		Updates(Template{
+			Title:              saveTemplateDto.Title,
+			Content:            saveTemplateDto.Content,
+			Type:               saveTemplateDto.Type,
+			Checklist:          saveTemplateDto.Checklist,
+			Priority:           saveTemplateDto.Priority,
+			LabelIDs:           saveTemplateDto.LabelIDs,
+			StartOffsetMinutes: saveTemplateDto.StartOffsetMinutes,
+			DueOffsetMinutes:   saveTemplateDto.DueOffsetMinutes,
+		})
+	if result.Error != nil {
+		log.Println("Error while updating template", result.Error)
+		return errors.New("unable to update template")
+	}
+	return nil
+}
+
+func (repo *templateRepository) DeleteTemplate(ctx context.Context, templateId uint) error {
+	result := repo.db.WithContext(ctx).Unscoped().Delete(&Template{}, templateId)
+	if result.Error != nil {
+		log.Println("Error while deleting template", result.Error)
+		return errors.New("unable to delete template")
+	}
+	return nil
+}
+
+func (repo *templateRepository) FindTemplateByUserId(ctx context.Context, templateId uint, userId uint) (*Template, error) {
+	template := Template{}
+	result := repo.db.WithContext(ctx).Where("id = ?", templateId).Where("user_id = ?", userId).First(&template)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("template not found")
+		}
+		return nil, result.Error
+	}
+	return &template, nil
+}
+
+func (repo *templateRepository) FetchTemplates(ctx context.Context, userId uint) ([]Template, error) {
+	templates := make([]Template, 0)
+	result := repo.db.WithContext(ctx).Where("user_id = ?", userId).Order("title asc, id asc").Find(&templates)
+	if result.Error != nil {
+		log.Println("Error while fetching templates", result.Error)
+		return nil, errors.New("error while fetching templates")
+	}
+	return templates, nil
+}
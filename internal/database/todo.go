@@ -2,15 +2,29 @@ package database
 
 import (
 	"github.com/horlerdipo/todo-golang/internal/enums"
+	"time"
 )
 
 type Todo struct {
 	Model
-	Title      string         `json:"title"`
-	Content    *string        `json:"content"`
-	Type       enums.TodoType `json:"type"`
-	UserID     uint           `json:"user_id"`
-	User       User           `gorm:"constraint:OnDelete:CASCADE" json:"-"`
-	Pinned     bool           `gorm:"default:false" json:"pinned"`
-	Checklists []Checklist    `gorm:"foreignKey:TodoID" json:"checklists"`
+	Title       string             `json:"title"`
+	Content     *string            `json:"content"`
+	Type        enums.TodoType     `json:"type"`
+	Priority    enums.TodoPriority `gorm:"default:none;index" json:"priority"`
+	UserID      uint               `json:"user_id"`
+	User        User               `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	WorkspaceID *uint              `gorm:"index" json:"workspace_id"`
+	Pinned      bool               `gorm:"default:false" json:"pinned"`
+	Position    string             `gorm:"index" json:"position"`
+	ArchivedAt  *time.Time         `gorm:"index" json:"archived_at"`
+	Completed   bool               `gorm:"default:false;index" json:"completed"`
+	CompletedAt *time.Time         `json:"completed_at"`
+	StartAt     *time.Time         `json:"start_at"`
+	DueAt       *time.Time         `gorm:"index" json:"due_at"`
+	Recurrence  *string            `json:"recurrence"`
+	Occurrence  int                `gorm:"default:1" json:"occurrence"`
+	Progress    ChecklistProgress  `gorm:"-" json:"progress"`
+	Snippet     string             `gorm:"->;-:migration" json:"snippet,omitempty"`
+	Checklists  []Checklist        `gorm:"foreignKey:TodoID" json:"checklists"`
+	Labels      []Label            `gorm:"many2many:todo_labels;" json:"labels"`
 }
@@ -0,0 +1,13 @@
+package database
+
+import "time"
+
+// TodoCompletion records each time a todo was completed, so reopening and completing again keeps the history.
+type TodoCompletion struct {
+	Model
+	TodoID      uint      `gorm:"index" json:"todo_id"`
+	Todo        Todo      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID      uint      `json:"user_id"`
+	User        User      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CompletedAt time.Time `json:"completed_at"`
+}
@@ -5,25 +5,139 @@ import (
 	"fmt"
 	"github.com/horlerdipo/todo-golang/internal/dtos"
 	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/pkg"
 	"golang.org/x/net/context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
-	"math"
+	"sort"
+	"time"
 )
 
 type TodoRepository interface {
 	CreateTodo(ctx context.Context, createTodoDto *dtos.CreateTodoDTO) (uint, error)
 	DeleteTodo(ctx context.Context, todoId uint) error
 	FindTodoByUserId(ctx context.Context, todoId uint, userId uint, withChecklists bool) (*Todo, error)
-	UpdateTodo(ctx context.Context, todoId uint, updateTodoDto *dtos.UpdateTodoDTO, deleteChecklist bool) error
+	FindTodoForUser(ctx context.Context, todoId uint, userId uint, withChecklists bool) (*Todo, enums.ShareRole, error)
+	FindManageableTodo(ctx context.Context, todoId uint, userId uint, trashed bool) (*Todo, error)
+	UpdateTodo(ctx context.Context, todoId uint, updateTodoDto *dtos.UpdateTodoDTO) error
 	PinTodo(ctx context.Context, todoId uint) error
 	UnPinTodo(ctx context.Context, todoId uint) error
 	CountPinnedTodos(ctx context.Context, userId uint) int64
-	FetchAll(ctx context.Context, paginationOptions dtos.PaginationOptions, userId uint) (dtos.PaginatedResponse[Todo], error)
-	AddChecklistItem(ctx context.Context, todoId uint, description string) (uint, error)
+	ArchiveTodo(ctx context.Context, todoId uint) error
+	CompleteTodo(ctx context.Context, todoId uint, userId uint, completedAt time.Time) (bool, error)
+	ReopenTodo(ctx context.Context, todoId uint) (bool, error)
+	FetchCompletions(ctx context.Context, todoId uint) ([]TodoCompletion, error)
+	UnArchiveTodo(ctx context.Context, todoId uint) error
+	FetchAll(ctx context.Context, paginationOptions dtos.PaginationOptions, userId uint, workspaceId *uint) (dtos.PaginatedResponse[Todo], error)
+	AddChecklistItem(ctx context.Context, todoId uint, description string, parentId *uint) (uint, error)
 	DeleteChecklistItem(ctx context.Context, checklistId uint, todoId uint) error
 	UpdateChecklistItem(ctx context.Context, checklistId uint, todoId uint, description string) (uint, error)
 	UpdateChecklistItemStatus(ctx context.Context, checklistId uint, todoId uint, done bool) (uint, error)
+	CountChecklistItems(ctx context.Context, todoId uint) (total int64, done int64, err error)
+	CreateNextOccurrence(ctx context.Context, todoId uint, dueAt time.Time, startAt *time.Time) (uint, error)
+	MoveTodo(ctx context.Context, todoId uint, userId uint, workspaceId *uint, afterId *uint, beforeId *uint) error
+	MoveChecklistItem(ctx context.Context, checklistId uint, todoId uint, afterId *uint, beforeId *uint) error
+	AttachLabels(ctx context.Context, todoId uint, labelIds []uint) error
+	DetachLabel(ctx context.Context, todoId uint, labelId uint) error
+	FetchTrashed(ctx context.Context, paginationOptions dtos.PaginationOptions, userId uint, workspaceId *uint) (dtos.PaginatedResponse[Todo], error)
+	RestoreTodo(ctx context.Context, todoId uint) error
+	PurgeTodo(ctx context.Context, todoId uint) ([]string, error)
+	PurgeTrashedTodos(ctx context.Context, trashedBefore time.Time) (int64, []string, error)
+	ChangeTodoType(ctx context.Context, todoId uint, todoType enums.TodoType) error
+	Transaction(ctx context.Context, fn func(repo TodoRepository) error) error
+	StreamTodos(ctx context.Context, userId uint, fn func([]Todo) error) error
+	StreamChecklists(ctx context.Context, userId uint, fn func([]Checklist) error) error
+	StreamCompletions(ctx context.Context, userId uint, fn func([]TodoCompletion) error) error
+	ImportTodos(ctx context.Context, todos []Todo) ([]uint, error)
+	StreamCalendarTodos(ctx context.Context, userId uint, fn func([]Todo) error) error
+}
+
+var todoFilterScopes = map[string]filterScope{
+	"overdue": func(query *gorm.DB, value interface{}) *gorm.DB {
+		if value.(bool) {
+			return query.Where("due_at IS NOT NULL AND due_at < ? AND completed = ?", time.Now().UTC(), false)
+		}
+		return query.Where("due_at IS NULL OR due_at >= ? OR completed = ?", time.Now().UTC(), true)
+	},
+	"due_today": func(query *gorm.DB, value interface{}) *gorm.DB {
+		start := startOfDay(time.Now())
+		return dueWithin(query, start, start.AddDate(0, 0, 1), value.(bool))
+	},
+	"due_this_week": func(query *gorm.DB, value interface{}) *gorm.DB {
+		start := startOfWeek(time.Now())
+		return dueWithin(query, start, start.AddDate(0, 0, 7), value.(bool))
+	},
+	"due_from": func(query *gorm.DB, value interface{}) *gorm.DB {
+		return query.Where("due_at >= ?", value.(time.Time).UTC())
+	},
+	"due_to": func(query *gorm.DB, value interface{}) *gorm.DB {
+		return query.Where("due_at <= ?", value.(time.Time).UTC())
+	},
+	"archived": func(query *gorm.DB, value interface{}) *gorm.DB {
+		switch value.(string) {
+		case "include":
+			return query
+		case "only":
+			return query.Where("archived_at IS NOT NULL")
+		default:
+			return query.Where("archived_at IS NULL")
+		}
+	},
+	//applied by FetchAll itself, since it depends on who is asking
+	"shared": func(query *gorm.DB, value interface{}) *gorm.DB {
+		return query
+	},
+	"labels": func(query *gorm.DB, value interface{}) *gorm.DB {
+		return query.Where("id IN (SELECT todo_id FROM todo_labels WHERE label_id IN ?)", value)
+	},
+	"labels_all": func(query *gorm.DB, value interface{}) *gorm.DB {
+		labelIds := pkg.Unique(value.([]int64))
+		return query.Where(
+			"id IN (SELECT todo_id FROM todo_labels WHERE label_id IN ? GROUP BY todo_id HAVING COUNT(DISTINCT label_id) = ?)",
+			labelIds,
+			len(labelIds),
+		)
+	},
+}
+
+// todoPriorityRank sorts priorities by how pressing they are rather than alphabetically.
+var todoPriorityRank = func() string {
+	rank := "CASE priority"
+	for i, priority := range enums.TodoPriorities {
+		rank += fmt.Sprintf(" WHEN '%s' THEN %d", priority, i)
+	}
+	return rank + " ELSE 0 END"
+}()
+
+var todoListing = listing{
+	Resource:     "todos",
+	FilterScopes: todoFilterScopes,
+	SortExpressions: map[string]string{
+		"priority": todoPriorityRank,
+	},
+	// pinned first, then the most pressing, then the soonest due with undated todos last
+	DefaultOrder: fmt.Sprintf("pinned desc, %s desc, due_at IS NULL, due_at asc, id asc", todoPriorityRank),
+}
+
+// dueWithin matches todos due from from up to to, comparing in UTC like the due dates are stored.
+func dueWithin(query *gorm.DB, from time.Time, to time.Time, within bool) *gorm.DB {
+	from, to = from.UTC(), to.UTC()
+	if within {
+		return query.Where("due_at >= ? AND due_at < ?", from, to)
+	}
+	return query.Where("due_at IS NULL OR due_at < ? OR due_at >= ?", from, to)
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns midnight of the Monday of the week t falls in.
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -daysSinceMonday)
 }
 
 type todoRepository struct {
@@ -36,25 +150,48 @@ func NewTodoRepository(db *gorm.DB) TodoRepository {
 
 func (repo todoRepository) CreateTodo(ctx context.Context, createTodoDto *dtos.CreateTodoDTO) (uint, error) {
 	todoModel := Todo{
-		Content: createTodoDto.Content,
-		Title:   createTodoDto.Title,
-		Type:    createTodoDto.Type,
-		UserID:  createTodoDto.UserID,
+		Content:     createTodoDto.Content,
+		Title:       createTodoDto.Title,
+		Type:        createTodoDto.Type,
+		UserID:      createTodoDto.UserID,
+		WorkspaceID: createTodoDto.WorkspaceID,
+		StartAt:     createTodoDto.StartAt,
+		DueAt:       createTodoDto.DueAt,
+		Recurrence:  createTodoDto.Recurrence,
+		Priority:    createTodoDto.Priority,
 	}
 
 	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := lastPosition(tx, &Todo{}, todoList(createTodoDto.UserID, createTodoDto.WorkspaceID))
+		if err != nil {
+			return err
+		}
+		todoModel.Position = position
+
 		result := tx.Create(&todoModel)
 		if result.Error != nil {
 			return result.Error
 		}
 
+		if len(createTodoDto.LabelIDs) > 0 {
+			labels := make([]Label, 0, len(createTodoDto.LabelIDs))
+			for _, labelId := range createTodoDto.LabelIDs {
+				labels = append(labels, Label{Model: Model{ID: labelId}})
+			}
+			if err = tx.Model(&todoModel).Association("Labels").Append(labels); err != nil {
+				return err
+			}
+		}
+
 		if createTodoDto.Type == enums.Checklist {
 			var checklists []Checklist
-			for _, checklist := range createTodoDto.Checklist {
+			positions := pkg.SpreadRanks(len(createTodoDto.Checklist))
+			for i, checklist := range createTodoDto.Checklist {
 				checklistModel := Checklist{
 					Description: checklist,
 					Done:        false,
 					TodoID:      todoModel.ID,
+					Position:    positions[i],
 				}
 				checklists = append(checklists, checklistModel)
 			}
@@ -73,11 +210,16 @@ func (repo todoRepository) CreateTodo(ctx context.Context, createTodoDto *dtos.C
 	return todoModel.ID, nil
 }
 
+// DeleteTodo moves a todo and its checklist into the trash. Both share the same deleted_at so that
+// RestoreTodo can tell them apart from checklist items that were deleted on their own beforehand.
 func (repo todoRepository) DeleteTodo(ctx context.Context, todoId uint) error {
+	deletedAt := time.Now()
 	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		tx.Where("todo_id = ?", todoId).Delete(&Checklist{})
-		tx.Delete(&Todo{}, todoId)
-		return nil
+		result := tx.Model(&Checklist{}).Where("todo_id = ?", todoId).Update("deleted_at", deletedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Model(&Todo{}).Where("id = ?", todoId).Update("deleted_at", deletedAt).Error
 	})
 
 	if err != nil {
@@ -93,7 +235,82 @@ func (repo todoRepository) FindTodoByUserId(ctx context.Context, todoId uint, us
 		Where("id = ?", todoId)
 
 	if withChecklist {
-		query = query.Preload("Checklists")
+		query = query.Preload("Checklists", orderedChecklists).Preload("Labels")
+	}
+
+	result := query.First(&todo)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, result.Error
+	}
+
+	if withChecklist {
+		countProgress(&todo)
+	}
+	return &todo, nil
+}
+
+// FindTodoForUser finds a todo the user either owns or has been given access to, along with the role they have on it.
+// Members of a todo's workspace can edit it.
+func (repo todoRepository) FindTodoForUser(ctx context.Context, todoId uint, userId uint, withChecklist bool) (*Todo, enums.ShareRole, error) {
+	todo := Todo{}
+	query := repo.db.WithContext(ctx).
+		Where("id = ?", todoId).
+		Where("user_id = ? OR id IN (?) OR workspace_id IN (?)", userId, sharedTodoIds(repo.db, userId), memberWorkspaceIds(repo.db, userId))
+
+	if withChecklist {
+		query = query.Preload("Checklists", orderedChecklists).Preload("Labels")
+	}
+
+	result := query.First(&todo)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, "", errors.New("todo not found")
+		}
+		return nil, "", result.Error
+	}
+
+	if withChecklist {
+		countProgress(&todo)
+	}
+
+	if todo.UserID == userId {
+		return &todo, enums.Owner, nil
+	}
+
+	if todo.WorkspaceID != nil {
+		var count int64
+		result = repo.db.WithContext(ctx).Model(&WorkspaceMember{}).Where("workspace_id = ?", *todo.WorkspaceID).Where("user_id = ?", userId).Count(&count)
+		if result.Error != nil {
+			return nil, "", result.Error
+		}
+		if count > 0 {
+			return &todo, enums.Editor, nil
+		}
+	}
+
+	share := TodoShare{}
+	result = repo.db.WithContext(ctx).Where("todo_id = ?", todoId).Where("user_id = ?", userId).First(&share)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	return &todo, share.Role, nil
+}
+
+// FindManageableTodo finds a todo the user may delete, pin, archive, restore or purge: one of their personal todos,
+// one they created in a workspace they still belong to, or any todo of a workspace they own or administer. trashed
+// looks for the todo in the trash instead.
+func (repo todoRepository) FindManageableTodo(ctx context.Context, todoId uint, userId uint, trashed bool) (*Todo, error) {
+	todo := Todo{}
+	query := repo.db.WithContext(ctx).
+		Where("id = ?", todoId).
+		Where("(workspace_id IS NULL AND user_id = ?) OR (user_id = ? AND workspace_id IN (?)) OR workspace_id IN (?)",
+			userId, userId, memberWorkspaceIds(repo.db, userId), managedWorkspaceIds(repo.db, userId))
+
+	if trashed {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
 	}
 
 	result := query.First(&todo)
@@ -106,7 +323,14 @@ func (repo todoRepository) FindTodoByUserId(ctx context.Context, todoId uint, us
 	return &todo, nil
 }
 
-func (repo todoRepository) UpdateTodo(ctx context.Context, todoId uint, updateTodoDto *dtos.UpdateTodoDTO, deleteChecklist bool) error {
+// sharedTodoIds is a subquery of the ids of todos shared with the user.
+func sharedTodoIds(db *gorm.DB, userId uint) *gorm.DB {
+	return db.Model(&TodoShare{}).Select("todo_id").Where("user_id = ?", userId)
+}
+
+// UpdateTodo writes the todo's fields. Its checklist is left alone, since ChangeTodoType converts it when the type
+// changes.
+func (repo todoRepository) UpdateTodo(ctx context.Context, todoId uint, updateTodoDto *dtos.UpdateTodoDTO) error {
 
 	var todo Todo
 	result := repo.db.WithContext(ctx).Where("id = ?", todoId).First(&todo)
@@ -118,30 +342,182 @@ func (repo todoRepository) UpdateTodo(ctx context.Context, todoId uint, updateTo
 		return errors.New("unable to fetch todo while updating todo")
 	}
 
+	result = repo.db.WithContext(ctx).Model(&todo).Select("Content", "Title", "Type", "StartAt", "DueAt", "Recurrence", "Priority").Where("id = ?", todo.ID).Updates(Todo{
+		Content:    updateTodoDto.Content,
+		Title:      updateTodoDto.Title,
+		Type:       updateTodoDto.Type,
+		StartAt:    updateTodoDto.StartAt.Value,
+		DueAt:      updateTodoDto.DueAt.Value,
+		Recurrence: updateTodoDto.Recurrence.Value,
+		Priority:   updateTodoDto.Priority,
+	})
+
+	if result.Error != nil {
+		log.Printf("unable to update todo: %v", result.Error)
+		return errors.New("unable to update todo")
+	}
+	return nil
+}
+
+// ChangeTodoType switches a todo between text and checklist without losing what it holds. The lines of a text todo's
+// content become checklist items, and the items of a checklist todo are written out as its content the way
+// checklistContent formats them.
+func (repo todoRepository) ChangeTodoType(ctx context.Context, todoId uint, todoType enums.TodoType) error {
 	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		result := tx.Model(&todo).Select("Content", "Title", "Type").Where("id = ?", todo.ID).Updates(Todo{
-			Content: updateTodoDto.Content,
-			Title:   updateTodoDto.Title,
-			Type:    updateTodoDto.Type,
-		})
+		var todo Todo
+		if err := tx.Where("id = ?", todoId).First(&todo).Error; err != nil {
+			return err
+		}
 
-		if result.Error != nil {
-			return result.Error
+		updates := map[string]interface{}{"type": todoType}
+		if todoType == enums.Checklist {
+			updates["content"] = nil
+
+			var items []Checklist
+			if todo.Content != nil {
+				items = checklistFromContent(*todo.Content)
+			}
+			positions := pkg.SpreadRanks(countChecklistTree(items))
+			if err := createChecklistTree(tx, todoId, nil, items, &positions); err != nil {
+				return err
+			}
+		} else {
+			var items []Checklist
+			if err := orderedChecklists(tx.Where("todo_id = ?", todoId)).Find(&items).Error; err != nil {
+				return err
+			}
+			if len(items) > 0 {
+				updates["content"] = checklistContent(items)
+			}
+			if err := tx.Where("todo_id = ?", todoId).Delete(&Checklist{}).Error; err != nil {
+				return err
+			}
 		}
 
-		if deleteChecklist {
-			tx.Model(&Checklist{}).Where("todo_id = ?", todoId).Delete(&Checklist{})
+		return tx.Model(&Todo{}).Where("id = ?", todoId).Updates(updates).Error
+	})
+
+	if err != nil {
+		log.Println("Error while changing todo type", err)
+		return errors.New("unable to change todo type")
+	}
+	return nil
+}
+
+// Transaction runs fn with a repository bound to a single database transaction, which is rolled back when fn
+// returns an error. Transactions started from that repository are nested as savepoints.
+func (repo todoRepository) Transaction(ctx context.Context, fn func(repo TodoRepository) error) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(todoRepository{db: tx})
+	})
+}
+
+// ImportTodos creates imported todos at the end of their lists in a single transaction. Their checklists are
+// created depth first from each item's Children, so sub-items follow their parent the way checklistTree nests them.
+func (repo todoRepository) ImportTodos(ctx context.Context, todos []Todo) ([]uint, error) {
+	todoIds := make([]uint, 0, len(todos))
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, todo := range todos {
+			position, err := lastPosition(tx, &Todo{}, todoList(todo.UserID, todo.WorkspaceID))
+			if err != nil {
+				return err
+			}
+			todo.Position = position
+
+			checklists := todo.Checklists
+			todo.Checklists = nil
+			if err = tx.Omit(clause.Associations).Create(&todo).Error; err != nil {
+				return err
+			}
+			todoIds = append(todoIds, todo.ID)
+
+			positions := pkg.SpreadRanks(countChecklistTree(checklists))
+			if err = createChecklistTree(tx, todo.ID, nil, checklists, &positions); err != nil {
+				return err
+			}
+
+			if todo.Completed && todo.CompletedAt != nil {
+				err = tx.Create(&TodoCompletion{TodoID: todo.ID, UserID: todo.UserID, CompletedAt: *todo.CompletedAt}).Error
+				if err != nil {
+					return err
+				}
+			}
 		}
 		return nil
 	})
 
 	if err != nil {
-		log.Printf("unable to update todo: %v", err)
-		return errors.New("unable to update todo")
+		log.Println("Error while importing todos", err)
+		return nil, errors.New("unable to import todos, please try again")
+	}
+	return todoIds, nil
+}
+
+func countChecklistTree(items []Checklist) int {
+	count := len(items)
+	for _, item := range items {
+		count += countChecklistTree(item.Children)
+	}
+	return count
+}
+
+// createChecklistTree creates checklist items under parentId, each followed by its own sub-items, taking their
+// positions from the front of positions.
+func createChecklistTree(tx *gorm.DB, todoId uint, parentId *uint, items []Checklist, positions *[]string) error {
+	for _, item := range items {
+		checklist := Checklist{
+			Description: item.Description,
+			Done:        item.Done,
+			TodoID:      todoId,
+			ParentID:    parentId,
+			Position:    (*positions)[0],
+		}
+		*positions = (*positions)[1:]
+
+		if err := tx.Create(&checklist).Error; err != nil {
+			return err
+		}
+		if err := createChecklistTree(tx, todoId, &checklist.ID, item.Children, positions); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// StreamTodos hands fn the todos the user created, trashed ones and labels included, a batch at a time.
+func (repo todoRepository) StreamTodos(ctx context.Context, userId uint, fn func([]Todo) error) error {
+	query := repo.db.WithContext(ctx).Unscoped().Preload("Labels").Where("user_id = ?", userId)
+	return streamInBatches(query, fn)
+}
+
+// StreamChecklists hands fn the checklist items of the todos the user created, a batch at a time. The items of a
+// trashed todo are included when they were trashed along with it.
+func (repo todoRepository) StreamChecklists(ctx context.Context, userId uint, fn func([]Checklist) error) error {
+	query := repo.db.WithContext(ctx).
+		Unscoped().
+		Where("todo_id IN (?)", ownedTodoIds(repo.db, userId)).
+		Where("deleted_at IS NULL OR deleted_at = (SELECT deleted_at FROM todos WHERE todos.id = checklists.todo_id)")
+	return streamInBatches(query, fn)
+}
+
+// StreamCompletions hands fn the completion history of the todos the user created, a batch at a time.
+func (repo todoRepository) StreamCompletions(ctx context.Context, userId uint, fn func([]TodoCompletion) error) error {
+	query := repo.db.WithContext(ctx).Where("todo_id IN (?)", ownedTodoIds(repo.db, userId))
+	return streamInBatches(query, fn)
+}
+
+// StreamCalendarTodos hands fn the unarchived todos with a due date that the user owns, has been shared or can see
+// through a workspace, checklists and labels included, a batch at a time.
+func (repo todoRepository) StreamCalendarTodos(ctx context.Context, userId uint, fn func([]Todo) error) error {
+	query := repo.db.WithContext(ctx).
+		Preload("Checklists", orderedChecklists).
+		Preload("Labels").
+		Where("user_id = ? OR id IN (?) OR workspace_id IN (?)", userId, sharedTodoIds(repo.db, userId), memberWorkspaceIds(repo.db, userId)).
+		Where("due_at IS NOT NULL").
+		Where("archived_at IS NULL")
+	return streamInBatches(query, fn)
+}
+
 func (repo todoRepository) PinTodo(ctx context.Context, todoId uint) error {
 	result := repo.db.WithContext(ctx).Model(&Todo{}).Where("id = ?", todoId).Update("pinned", true)
 	if result.Error != nil {
@@ -170,24 +546,169 @@ func (repo todoRepository) CountPinnedTodos(ctx context.Context, userId uint) in
 	return count
 }
 
-func (repo todoRepository) AddChecklistItem(ctx context.Context, todoId uint, description string) (uint, error) {
+// ArchiveTodo archives a todo, unpinning it so it stops taking up one of the user's pin slots.
+func (repo todoRepository) ArchiveTodo(ctx context.Context, todoId uint) error {
+	result := repo.db.WithContext(ctx).
+		Model(&Todo{}).
+		Where("id = ?", todoId).
+		Where("archived_at IS NULL").
+		Updates(map[string]interface{}{"archived_at": time.Now(), "pinned": false})
+	if result.Error != nil {
+		log.Println(result.Error)
+		return errors.New("unable to archive todo, please try again")
+	}
+	return nil
+}
+
+func (repo todoRepository) UnArchiveTodo(ctx context.Context, todoId uint) error {
+	result := repo.db.WithContext(ctx).Model(&Todo{}).Where("id = ?", todoId).Update("archived_at", nil)
+	if result.Error != nil {
+		log.Println(result.Error)
+		return errors.New("unable to unarchive todo, please try again")
+	}
+	return nil
+}
+
+// CompleteTodo marks a todo as completed and records it in the todo's completion history. It returns false
+// without recording anything when the todo was already completed.
+func (repo todoRepository) CompleteTodo(ctx context.Context, todoId uint, userId uint, completedAt time.Time) (bool, error) {
+	completed := false
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Todo{}).
+			Where("id = ?", todoId).
+			Where("completed = ?", false).
+			Updates(map[string]interface{}{"completed": true, "completed_at": completedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		completed = true
+		return tx.Create(&TodoCompletion{
+			TodoID:      todoId,
+			UserID:      userId,
+			CompletedAt: completedAt,
+		}).Error
+	})
+
+	if err != nil {
+		log.Println("Error while completing todo", err)
+		return false, errors.New("unable to complete todo, please try again")
+	}
+	return completed, nil
+}
+
+// ReopenTodo marks a completed todo as open again, returning false when it was not completed.
+func (repo todoRepository) ReopenTodo(ctx context.Context, todoId uint) (bool, error) {
+	result := repo.db.WithContext(ctx).
+		Model(&Todo{}).
+		Where("id = ?", todoId).
+		Where("completed = ?", true).
+		Updates(map[string]interface{}{"completed": false, "completed_at": nil})
+	if result.Error != nil {
+		log.Println("Error while reopening todo", result.Error)
+		return false, errors.New("unable to reopen todo, please try again")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repo todoRepository) FetchCompletions(ctx context.Context, todoId uint) ([]TodoCompletion, error) {
+	var completions []TodoCompletion
+	result := repo.db.WithContext(ctx).
+		Where("todo_id = ?", todoId).
+		Order("completed_at desc").
+		Find(&completions)
+	if result.Error != nil {
+		log.Println("Error while fetching todo completions", result.Error)
+		return nil, errors.New("error while fetching todo completions")
+	}
+	return completions, nil
+}
+
+func (repo todoRepository) AddChecklistItem(ctx context.Context, todoId uint, description string, parentId *uint) (uint, error) {
 	checklist := Checklist{
 		Description: description,
 		Done:        false,
 		TodoID:      todoId,
+		ParentID:    parentId,
 	}
 
-	result := repo.db.WithContext(ctx).Create(&checklist)
-	if result.Error != nil {
-		return 0, result.Error
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := lastPosition(tx, &Checklist{}, todoChecklist(todoId))
+		if err != nil {
+			return err
+		}
+		checklist.Position = position
+		return tx.Create(&checklist).Error
+	})
+	if err != nil {
+		return 0, err
 	}
 	return checklist.ID, nil
 }
 
+// MoveTodo moves a todo within the list it belongs to, the workspace's when workspaceId is set and userId's personal list otherwise.
+func (repo todoRepository) MoveTodo(ctx context.Context, todoId uint, userId uint, workspaceId *uint, afterId *uint, beforeId *uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return movePosition(tx, &Todo{}, todoList(userId, workspaceId), todoId, afterId, beforeId)
+	})
+	return positionError(err, "todo")
+}
+
+func (repo todoRepository) MoveChecklistItem(ctx context.Context, checklistId uint, todoId uint, afterId *uint, beforeId *uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return movePosition(tx, &Checklist{}, todoChecklist(todoId), checklistId, afterId, beforeId)
+	})
+	return positionError(err, "checklist")
+}
+
+func positionError(err error, resource string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%s to move next to not found", resource)
+	case errors.Is(err, pkg.ErrNoRankBetween):
+		return errors.New("after_id must come before before_id")
+	default:
+		log.Printf("Error while moving %s: %v", resource, err)
+		return fmt.Errorf("unable to move %s, please try again", resource)
+	}
+}
+
+// todoList scopes todos to a workspace's board when workspaceId is set and to a user's personal todos otherwise.
+func todoList(userId uint, workspaceId *uint) positionScope {
+	return func(query *gorm.DB) *gorm.DB {
+		if workspaceId != nil {
+			return query.Where("workspace_id = ?", *workspaceId)
+		}
+		return query.Where("user_id = ?", userId).Where("workspace_id IS NULL")
+	}
+}
+
+func todoChecklist(todoId uint) positionScope {
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Where("todo_id = ?", todoId)
+	}
+}
+
+func orderedChecklists(query *gorm.DB) *gorm.DB {
+	return query.Order("position asc, id asc")
+}
+
+// DeleteChecklistItem deletes a checklist item along with all of its sub-items.
 func (repo todoRepository) DeleteChecklistItem(ctx context.Context, checklistId uint, todoId uint) error {
-	result := repo.db.WithContext(ctx).Where("id = ?", checklistId).Where("todo_id = ?", todoId).Delete(&Checklist{})
-	if result.Error != nil {
-		log.Print("Error while deleting checklist", result.Error)
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		checklistIds, err := checklistSubtree(tx, todoId, checklistId)
+		if err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", checklistIds).Where("todo_id = ?", todoId).Delete(&Checklist{}).Error
+	})
+	if err != nil {
+		log.Print("Error while deleting checklist", err)
 		return errors.New("error while deleting checklist")
 	}
 	return nil
@@ -206,66 +727,421 @@ func (repo todoRepository) UpdateChecklistItem(ctx context.Context, checklistId
 	return checklistId, nil
 }
 
+// UpdateChecklistItemStatus sets whether a checklist item is done. Checking an item also checks all of its
+// sub-items, unchecking it leaves them as they are.
 func (repo todoRepository) UpdateChecklistItemStatus(ctx context.Context, checklistId uint, todoId uint, done bool) (uint, error) {
-	result := repo.db.WithContext(ctx).Model(&Checklist{}).Where("todo_id = ?", todoId).Where("id = ?", checklistId).Update("Done", done)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		checklistIds := []uint{checklistId}
+		if done {
+			var err error
+			checklistIds, err = checklistSubtree(tx, todoId, checklistId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&Checklist{}).Where("todo_id = ?", todoId).Where("id IN ?", checklistIds).Update("Done", done).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return 0, errors.New("checklist not found")
 		}
 
-		log.Println("Error while getting checklist status", result.Error)
+		log.Println("Error while getting checklist status", err)
 		return 0, errors.New("error while updating checklist status")
 	}
 	return checklistId, nil
 }
 
-func (repo todoRepository) FetchAll(ctx context.Context, paginationOptions dtos.PaginationOptions, userId uint) (dtos.PaginatedResponse[Todo], error) {
-	paginationOptions.Configure()
-	var todos []Todo
-	var total int64
-	var response dtos.PaginatedResponse[Todo]
+// checklistSubtree returns the id of a checklist item followed by the ids of all of its descendants.
+func checklistSubtree(tx *gorm.DB, todoId uint, checklistId uint) ([]uint, error) {
+	var items []Checklist
+	result := tx.Select("id", "parent_id").Where("todo_id = ?", todoId).Find(&items)
+	if result.Error != nil {
+		return nil, result.Error
+	}
 
-	baseQuery := repo.db.WithContext(ctx).
-		Model(&Todo{}).
-		Where("user_id = ?", userId)
+	children := make(map[uint][]uint)
+	for _, item := range items {
+		if item.ParentID != nil {
+			children[*item.ParentID] = append(children[*item.ParentID], item.ID)
+		}
+	}
 
-	for column, _ := range paginationOptions.Filters {
-		filter, err := paginationOptions.ConvertFilter(column)
-		if err != nil {
-			return response, err
+	subtree := []uint{checklistId}
+	for i := 0; i < len(subtree); i++ {
+		subtree = append(subtree, children[subtree[i]]...)
+	}
+	return subtree, nil
+}
+
+// countProgress sets the checklist progress of a todo whose checklist has been loaded.
+func countProgress(todo *Todo) {
+	for _, checklist := range todo.Checklists {
+		todo.Progress.Total++
+		if checklist.Done {
+			todo.Progress.Done++
 		}
-		baseQuery = baseQuery.Where(fmt.Sprintf("%s = ?", column), filter)
 	}
+}
 
-	if err := baseQuery.Count(&total).Error; err != nil {
-		return response, errors.New("error while counting todos")
+// fillProgress sets the checklist progress of each todo with a single query.
+func fillProgress(tx *gorm.DB, todos []Todo) error {
+	if len(todos) == 0 {
+		return nil
 	}
 
-	result := baseQuery.
-		Debug().
-		Offset(paginationOptions.Offset()).
-		Limit(paginationOptions.PerPage).
-		Order(fmt.Sprintf("%v %v", paginationOptions.SortBy, paginationOptions.Order)).
-		Find(&todos)
+	todoIds := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		todoIds = append(todoIds, todo.ID)
+	}
 
+	var counts []struct {
+		TodoID uint
+		Total  int64
+		Done   int64
+	}
+	result := tx.Model(&Checklist{}).
+		Select("todo_id, COUNT(*) AS total, COALESCE(SUM(CASE WHEN done THEN 1 ELSE 0 END), 0) AS done").
+		Where("todo_id IN ?", todoIds).
+		Group("todo_id").
+		Scan(&counts)
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return response, errors.New("todos not found")
+		return result.Error
+	}
+
+	progress := make(map[uint]ChecklistProgress, len(counts))
+	for _, count := range counts {
+		progress[count.TodoID] = ChecklistProgress{Done: count.Done, Total: count.Total}
+	}
+	for i := range todos {
+		todos[i].Progress = progress[todos[i].ID]
+	}
+	return nil
+}
+
+func (repo todoRepository) CountChecklistItems(ctx context.Context, todoId uint) (int64, int64, error) {
+	var counts struct {
+		Total int64
+		Done  int64
+	}
+
+	result := repo.db.WithContext(ctx).
+		Model(&Checklist{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN done THEN 1 ELSE 0 END), 0) AS done").
+		Where("todo_id = ?", todoId).
+		Scan(&counts)
+	if result.Error != nil {
+		log.Println("Error while counting checklist items", result.Error)
+		return 0, 0, errors.New("error while counting checklist items")
+	}
+	return counts.Total, counts.Done, nil
+}
+
+// CreateNextOccurrence copies a recurring todo (its checklist unchecked, its labels and who it is shared with) into
+// the next occurrence of its series. The recurrence rule moves onto the new todo so the same occurrence can only be
+// spawned once.
+func (repo todoRepository) CreateNextOccurrence(ctx context.Context, todoId uint, dueAt time.Time, startAt *time.Time) (uint, error) {
+	var nextTodo Todo
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo Todo
+		result := tx.Preload("Checklists", orderedChecklists).Preload("Labels").Where("id = ?", todoId).First(&todo)
+		if result.Error != nil {
+			return result.Error
 		}
-		err := errors.New("error while fetching all todos")
-		return response, err
+
+		result = tx.Model(&Todo{}).Where("id = ?", todoId).Where("recurrence IS NOT NULL").Update("recurrence", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("todo has already recurred")
+		}
+
+		position, err := lastPosition(tx, &Todo{}, todoList(todo.UserID, todo.WorkspaceID))
+		if err != nil {
+			return err
+		}
+
+		nextTodo = Todo{
+			Position:    position,
+			Title:       todo.Title,
+			Content:     todo.Content,
+			Type:        todo.Type,
+			Priority:    todo.Priority,
+			UserID:      todo.UserID,
+			WorkspaceID: todo.WorkspaceID,
+			StartAt:     startAt,
+			DueAt:       &dueAt,
+			Recurrence:  todo.Recurrence,
+			Occurrence:  todo.Occurrence + 1,
+		}
+		result = tx.Create(&nextTodo)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(todo.Labels) > 0 {
+			if err = tx.Model(&nextTodo).Association("Labels").Append(todo.Labels); err != nil {
+				return err
+			}
+		}
+
+		var shares []TodoShare
+		result = tx.Where("todo_id = ?", todoId).Order("id asc").Find(&shares)
+		if result.Error != nil {
+			return result.Error
+		}
+		for _, share := range shares {
+			copied := TodoShare{TodoID: nextTodo.ID, UserID: share.UserID, Role: share.Role, InvitedByID: share.InvitedByID}
+			if err = tx.Create(&copied).Error; err != nil {
+				return err
+			}
+		}
+
+		//parents are always created before their sub-items, so copying in id order can remap every parent
+		sort.Slice(todo.Checklists, func(i, j int) bool {
+			return todo.Checklists[i].ID < todo.Checklists[j].ID
+		})
+
+		copiedIds := make(map[uint]uint, len(todo.Checklists))
+		for _, checklist := range todo.Checklists {
+			copied := Checklist{
+				Description: checklist.Description,
+				Done:        false,
+				TodoID:      nextTodo.ID,
+				Position:    checklist.Position,
+			}
+			if checklist.ParentID != nil {
+				parentId, ok := copiedIds[*checklist.ParentID]
+				if !ok {
+					continue
+				}
+				copied.ParentID = &parentId
+			}
+
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+			copiedIds[checklist.ID] = copied.ID
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Println("Error while creating next occurrence", err)
+		return 0, err
+	}
+	return nextTodo.ID, nil
+}
+
+func (repo todoRepository) AttachLabels(ctx context.Context, todoId uint, labelIds []uint) error {
+	labels := make([]Label, 0, len(labelIds))
+	for _, labelId := range labelIds {
+		labels = append(labels, Label{Model: Model{ID: labelId}})
+	}
+
+	err := repo.db.WithContext(ctx).Model(&Todo{Model: Model{ID: todoId}}).Association("Labels").Append(labels)
+	if err != nil {
+		log.Println("Error while attaching labels", err)
+		return errors.New("unable to attach labels to todo")
+	}
+	return nil
+}
+
+func (repo todoRepository) DetachLabel(ctx context.Context, todoId uint, labelId uint) error {
+	err := repo.db.WithContext(ctx).Model(&Todo{Model: Model{ID: todoId}}).Association("Labels").Delete(&Label{Model: Model{ID: labelId}})
+	if err != nil {
+		log.Println("Error while detaching label", err)
+		return errors.New("unable to detach label from todo")
+	}
+	return nil
+}
+
+// FetchAll lists a user's personal todos, or a workspace's todos when workspaceId is set, leaving archived todos
+// out unless the archived filter is set to "include" or "only". Todos shared with the user are listed alongside
+// their personal todos when the shared filter is set to "include" or "only". When a search is given only matching
+// todos are listed, best match first unless another sort was requested, each with a highlighted snippet.
+func (repo todoRepository) FetchAll(ctx context.Context, paginationOptions dtos.PaginationOptions, userId uint, workspaceId *uint) (dtos.PaginatedResponse[Todo], error) {
+	query := repo.db.WithContext(ctx).
+		Model(&Todo{}).
+		Preload("Labels")
+
+	personal := "user_id = ? AND workspace_id IS NULL"
+	switch {
+	case workspaceId != nil:
+		query = query.Where("workspace_id = ?", *workspaceId)
+	case paginationOptions.Filters["shared"] == "include":
+		query = query.Where(personal+" OR id IN (?)", userId, sharedTodoIds(repo.db, userId))
+	case paginationOptions.Filters["shared"] == "only":
+		query = query.Where("id IN (?)", sharedTodoIds(repo.db, userId))
+	default:
+		query = query.Where(personal, userId)
+	}
+
+	if _, ok := paginationOptions.Filters["archived"]; !ok {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	listing := todoListing
+	if expression := searchExpression(paginationOptions.Search); expression != "" {
+		query = searchMatches(query, expression)
+		listing.DefaultOrder = "search_rank asc, id asc"
 	}
 
-	response = dtos.PaginatedResponse[Todo]{
-		Data: todos,
-		Meta: dtos.PaginatedResponseMeta{
-			TotalCount:  int(total),
-			FirstPage:   1,
-			CurrentPage: paginationOptions.Page,
-			LastPage:    int(math.Ceil(float64(total) / float64(paginationOptions.PerPage))),
-			PerPage:     paginationOptions.PerPage,
-		},
+	response, err := paginate[Todo](query, paginationOptions, listing)
+	if err != nil {
+		return response, err
 	}
 
+	if err = fillProgress(repo.db.WithContext(ctx), response.Data); err != nil {
+		log.Println("Error while counting checklist progress", err)
+		return response, errors.New("error while fetching all todos")
+	}
 	return response, nil
 }
+
+// FetchTrashed lists the trashed todos of workspaceId the user may restore when it is set, and the user's trashed
+// personal todos otherwise.
+func (repo todoRepository) FetchTrashed(ctx context.Context, paginationOptions dtos.PaginationOptions, userId uint, workspaceId *uint) (dtos.PaginatedResponse[Todo], error) {
+	query := repo.db.WithContext(ctx).
+		Unscoped().
+		Model(&Todo{}).
+		Where("deleted_at IS NOT NULL").
+		Preload("Labels")
+
+	if workspaceId != nil {
+		query = query.Where("workspace_id = ?", *workspaceId).
+			Where("user_id = ? OR workspace_id IN (?)", userId, managedWorkspaceIds(repo.db, userId))
+	} else {
+		query = query.Where("user_id = ? AND workspace_id IS NULL", userId)
+	}
+
+	return paginate[Todo](query, paginationOptions, todoListing)
+}
+
+// RestoreTodo takes a todo out of the trash along with the checklist items that were trashed with it.
+func (repo todoRepository) RestoreTodo(ctx context.Context, todoId uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Model(&Checklist{}).
+			Where("todo_id = ?", todoId).
+			Where("deleted_at = (SELECT deleted_at FROM todos WHERE id = ?)", todoId).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Unscoped().Model(&Todo{}).Where("id = ?", todoId).Update("deleted_at", nil).Error
+	})
+
+	if err != nil {
+		log.Println("Error while restoring todo", err)
+		return errors.New("unable to restore todo")
+	}
+	return nil
+}
+
+// PurgeTodo permanently deletes a todo, returning the storage keys of the attachments that went with it.
+func (repo todoRepository) PurgeTodo(ctx context.Context, todoId uint) ([]string, error) {
+	var storageKeys []string
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		storageKeys, err = purgeTodos(tx, []uint{todoId})
+		return err
+	})
+
+	if err != nil {
+		log.Println("Error while purging todo", err)
+		return nil, errors.New("unable to permanently delete todo")
+	}
+	return storageKeys, nil
+}
+
+// PurgeTrashedTodos permanently deletes every todo that was moved to the trash before trashedBefore,
+// returning how many were removed and the storage keys of the attachments that went with them.
+func (repo todoRepository) PurgeTrashedTodos(ctx context.Context, trashedBefore time.Time) (int64, []string, error) {
+	var todoIds []uint
+	var storageKeys []string
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Model(&Todo{}).
+			Where("deleted_at IS NOT NULL").
+			Where("deleted_at < ?", trashedBefore).
+			Pluck("id", &todoIds)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(todoIds) == 0 {
+			return nil
+		}
+
+		var err error
+		storageKeys, err = purgeTodos(tx, todoIds)
+		return err
+	})
+
+	if err != nil {
+		log.Println("Error while purging trashed todos", err)
+		return 0, nil, errors.New("unable to purge trashed todos")
+	}
+	return int64(len(todoIds)), storageKeys, nil
+}
+
+// purgeTodos hard deletes todos together with every record that hangs off them, returning the storage keys
+// of their attachments so the blobs can be removed once the transaction has committed. The blobs are recorded as
+// orphaned too, so that they are still removed when that fails.
+func purgeTodos(tx *gorm.DB, todoIds []uint) ([]string, error) {
+	var storageKeys []string
+	result := tx.Unscoped().Model(&Attachment{}).Where("todo_id IN ?", todoIds).Pluck("storage_key", &storageKeys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Unscoped().Where("todo_id IN ?", todoIds).Delete(&Attachment{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := orphanBlobs(tx, storageKeys); err != nil {
+		return nil, err
+	}
+
+	result = tx.Unscoped().Where("todo_id IN ?", todoIds).Delete(&Checklist{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Unscoped().Where("todo_id IN ?", todoIds).Delete(&Reminder{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Unscoped().Where("todo_id IN ?", todoIds).Delete(&TodoShare{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Unscoped().Where("todo_id IN ?", todoIds).Delete(&TodoCompletion{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Unscoped().Where("todo_id IN ?", todoIds).Delete(&Comment{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Exec("DELETE FROM todo_labels WHERE todo_id IN ?", todoIds)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = tx.Unscoped().Where("id IN ?", todoIds).Delete(&Todo{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return storageKeys, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// todoSearchTable is an FTS5 index over each todo's title, content and checklist items, keyed by the todo's id.
+const todoSearchTable = "todo_search"
+
+// checklistText gathers the descriptions of a todo's live checklist items into one searchable column.
+const checklistText = "(SELECT COALESCE(group_concat(description, ' '), '') FROM checklists WHERE todo_id = %s AND deleted_at IS NULL)"
+
+// todoSearchTriggers keep the index in step with the todos and checklists tables, so every write path, including
+// bulk updates that skip model hooks, is covered.
+var todoSearchTriggers = map[string]string{
+	"todo_search_todo_insert": `AFTER INSERT ON todos BEGIN
+		INSERT INTO todo_search(rowid, title, content, checklist)
+		VALUES (new.id, new.title, COALESCE(new.content, ''), ` + fmt.Sprintf(checklistText, "new.id") + `);
+	END`,
+	"todo_search_todo_update": `AFTER UPDATE OF title, content ON todos BEGIN
+		UPDATE todo_search SET title = new.title, content = COALESCE(new.content, '') WHERE rowid = new.id;
+	END`,
+	"todo_search_todo_delete": `AFTER DELETE ON todos BEGIN
+		DELETE FROM todo_search WHERE rowid = old.id;
+	END`,
+	"todo_search_checklist_insert": `AFTER INSERT ON checklists BEGIN
+		UPDATE todo_search SET checklist = ` + fmt.Sprintf(checklistText, "new.todo_id") + ` WHERE rowid = new.todo_id;
+	END`,
+	"todo_search_checklist_update": `AFTER UPDATE OF description, deleted_at ON checklists BEGIN
+		UPDATE todo_search SET checklist = ` + fmt.Sprintf(checklistText, "new.todo_id") + ` WHERE rowid = new.todo_id;
+	END`,
+	"todo_search_checklist_delete": `AFTER DELETE ON checklists BEGIN
+		UPDATE todo_search SET checklist = ` + fmt.Sprintf(checklistText, "old.todo_id") + ` WHERE rowid = old.todo_id;
+	END`,
+}
+
+// MigrateSearch creates the todo search index and the triggers that maintain it, indexing any existing todos the
+// first time it runs. It must run after the todos and checklists tables have been migrated.
+func MigrateSearch(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		exists := tx.Migrator().HasTable(todoSearchTable)
+		if !exists {
+			err := tx.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(title, content, checklist, tokenize = 'porter unicode61')", todoSearchTable)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for name, trigger := range todoSearchTriggers {
+			if err := tx.Exec(fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s %s", name, trigger)).Error; err != nil {
+				return err
+			}
+		}
+
+		if exists {
+			return nil
+		}
+		return tx.Exec(fmt.Sprintf(
+			"INSERT INTO %s(rowid, title, content, checklist) SELECT id, title, COALESCE(content, ''), %s FROM todos",
+			todoSearchTable, fmt.Sprintf(checklistText, "todos.id"),
+		)).Error
+	})
+}
+
+// searchMatches joins query onto the todos matching an FTS5 expression, exposing their bm25 score as search_rank
+// (lower is a better match) and a highlighted excerpt of the best matching column as snippet.
+func searchMatches(query *gorm.DB, expression string) *gorm.DB {
+	return query.Select("todos.*, matches.snippet").Joins(
+		"JOIN (SELECT rowid AS search_id, rank AS search_rank, snippet(todo_search, -1, '<mark>', '</mark>', '…', 12) AS snippet "+
+			"FROM todo_search WHERE todo_search MATCH ?) AS matches ON matches.search_id = todos.id",
+		expression,
+	)
+}
+
+// searchExpression turns free text into an FTS5 query matching todos that contain every word, each as a prefix so
+// results appear while the user is still typing. Words are quoted so FTS5 operators in the input are not parsed.
+// It is empty when the text has no words to search for.
+func searchExpression(search string) string {
+	terms := make([]string, 0)
+	for _, word := range strings.Fields(search) {
+		word = strings.ReplaceAll(word, `"`, "")
+		if word == "" {
+			continue
+		}
+		terms = append(terms, fmt.Sprintf(`"%s"*`, word))
+	}
+	return strings.Join(terms, " ")
+}
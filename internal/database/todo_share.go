@@ -0,0 +1,14 @@
+package database
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+// TodoShare gives another user access to a todo.
+type TodoShare struct {
+	Model
+	TodoID      uint            `gorm:"uniqueIndex:idx_todo_shares_todo_user" json:"todo_id"`
+	Todo        Todo            `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID      uint            `gorm:"uniqueIndex:idx_todo_shares_todo_user;index" json:"user_id"`
+	User        User            `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Role        enums.ShareRole `json:"role"`
+	InvitedByID uint            `json:"invited_by_id"`
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+)
+
+type TodoShareRepository interface {
+	ShareTodo(ctx context.Context, todoId uint, userId uint, role enums.ShareRole, invitedById uint) error
+	FindShare(ctx context.Context, todoId uint, userId uint) (*TodoShare, error)
+	FetchShares(ctx context.Context, todoId uint) ([]TodoShare, error)
+	DeleteShare(ctx context.Context, todoId uint, userId uint) error
+	FetchAudience(ctx context.Context, todoId uint) ([]uint, error)
+	StreamOutgoingShares(ctx context.Context, userId uint, fn func([]TodoShare) error) error
+}
+
+type todoShareRepository struct {
+	db *gorm.DB
+}
+
+func NewTodoShareRepository(db *gorm.DB) TodoShareRepository {
+	return &todoShareRepository{db: db}
+}
+
+// ShareTodo gives userId access to a todo, or changes their role when they already have it.
+func (repo *todoShareRepository) ShareTodo(ctx context.Context, todoId uint, userId uint, role enums.ShareRole, invitedById uint) error {
+	share := TodoShare{
+		TodoID:      todoId,
+		UserID:      userId,
+		Role:        role,
+		InvitedByID: invitedById,
+	}
+
+	result := repo.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "todo_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(&share)
+	if result.Error != nil {
+		log.Println("Error while sharing todo", result.Error)
+		return errors.New("unable to share todo, please try again")
+	}
+	return nil
+}
+
+func (repo *todoShareRepository) FindShare(ctx context.Context, todoId uint, userId uint) (*TodoShare, error) {
+	share := TodoShare{}
+	result := repo.db.WithContext(ctx).Where("todo_id = ?", todoId).Where("user_id = ?", userId).First(&share)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("share not found")
+		}
+		return nil, result.Error
+	}
+	return &share, nil
+}
+
+func (repo *todoShareRepository) FetchShares(ctx context.Context, todoId uint) ([]TodoShare, error) {
+	var shares []TodoShare
+	result := repo.db.WithContext(ctx).Preload("User").Where("todo_id = ?", todoId).Order("id asc").Find(&shares)
+	if result.Error != nil {
+		log.Println("Error while fetching todo shares", result.Error)
+		return nil, errors.New("error while fetching collaborators")
+	}
+	return shares, nil
+}
+
+// DeleteShare revokes a share outright, so the user can be invited again later.
+func (repo *todoShareRepository) DeleteShare(ctx context.Context, todoId uint, userId uint) error {
+	result := repo.db.WithContext(ctx).Unscoped().Where("todo_id = ?", todoId).Where("user_id = ?", userId).Delete(&TodoShare{})
+	if result.Error != nil {
+		log.Println("Error while deleting todo share", result.Error)
+		return errors.New("unable to remove collaborator, please try again")
+	}
+	return nil
+}
+
+// FetchAudience returns every user who can see a todo: its owner, its collaborators and the members of its workspace.
+func (repo *todoShareRepository) FetchAudience(ctx context.Context, todoId uint) ([]uint, error) {
+	todo := Todo{}
+	result := repo.db.WithContext(ctx).Select("id", "user_id", "workspace_id").Where("id = ?", todoId).First(&todo)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var collaboratorIds []uint
+	result = repo.db.WithContext(ctx).Model(&TodoShare{}).Where("todo_id = ?", todoId).Order("id asc").Pluck("user_id", &collaboratorIds)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var memberIds []uint
+	if todo.WorkspaceID != nil {
+		result = repo.db.WithContext(ctx).Model(&WorkspaceMember{}).Where("workspace_id = ?", *todo.WorkspaceID).Order("id asc").Pluck("user_id", &memberIds)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	}
+
+	audience := []uint{todo.UserID}
+	seen := map[uint]bool{todo.UserID: true}
+	for _, userId := range append(collaboratorIds, memberIds...) {
+		if !seen[userId] {
+			seen[userId] = true
+			audience = append(audience, userId)
+		}
+	}
+	return audience, nil
+}
+
+// StreamOutgoingShares hands fn the shares of the todos the user created, a batch at a time.
+func (repo *todoShareRepository) StreamOutgoingShares(ctx context.Context, userId uint, fn func([]TodoShare) error) error {
+	return streamInBatches(repo.db.WithContext(ctx).Where("todo_id IN (?)", ownedTodoIds(repo.db, userId)), fn)
+}
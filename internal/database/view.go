@@ -0,0 +1,55 @@
+package database
+
+import (
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// View is a saved todo list: the filters, sort, search and labels a user lists their todos by.
+type View struct {
+	Model
+	Name      string            `json:"name"`
+	UserID    uint              `gorm:"index" json:"user_id"`
+	User      User              `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Filters   map[string]string `gorm:"serializer:json" json:"filters"`
+	SortBy    string            `json:"sort_by"`
+	Order     dtos.Order        `gorm:"column:sort_order" json:"order"`
+	Search    string            `json:"q"`
+	LabelIDs  []uint            `gorm:"serializer:json" json:"label_ids"`
+	Position  string            `gorm:"index" json:"position"`
+	IsDefault bool              `gorm:"default:false" json:"is_default"`
+}
+
+// Query returns the query string listing the view's todos. A filter keyed "due_at[gt]" becomes filters[due_at][gt]
+// and the view's labels are matched by any of them.
+func (view *View) Query() url.Values {
+	query := url.Values{}
+	for key, value := range view.Filters {
+		if field, operator, ok := strings.Cut(key, "["); ok {
+			query.Set("filters["+field+"]["+operator, value)
+			continue
+		}
+		query.Set("filters["+key+"]", value)
+	}
+
+	if len(view.LabelIDs) > 0 {
+		labelIds := make([]string, 0, len(view.LabelIDs))
+		for _, labelId := range view.LabelIDs {
+			labelIds = append(labelIds, strconv.FormatUint(uint64(labelId), 10))
+		}
+		query.Set("filters[labels]", strings.Join(labelIds, ","))
+	}
+
+	if view.SortBy != "" {
+		query.Set("sort_by", view.SortBy)
+	}
+	if view.Order != "" {
+		query.Set("order", string(view.Order))
+	}
+	if view.Search != "" {
+		query.Set("q", view.Search)
+	}
+	return query
+}
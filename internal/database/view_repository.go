@@ -0,0 +1,152 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+)
+
+type ViewRepository interface {
+	CreateView(ctx context.Context, saveViewDto *dtos.SaveViewDTO) (uint, error)
+	UpdateView(ctx context.Context, viewId uint, saveViewDto *dtos.SaveViewDTO) error
+	DeleteView(ctx context.Context, viewId uint) error
+	FindViewByUserId(ctx context.Context, viewId uint, userId uint) (*View, error)
+	FindDefaultView(ctx context.Context, userId uint) (*View, error)
+	FetchViews(ctx context.Context, userId uint) ([]View, error)
+	MoveView(ctx context.Context, viewId uint, userId uint, afterId *uint, beforeId *uint) error
+}
+
+type viewRepository struct {
+	db *gorm.DB
+}
+
+func NewViewRepository(db *gorm.DB) ViewRepository {
+	return &viewRepository{db: db}
+}
+
+// userViews scopes positions to a user's views.
+func userViews(userId uint) positionScope {
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Where("user_id = ?", userId)
+	}
+}
+
+// clearDefaultView unmarks the user's default view, so the view about to be marked is the only one.
+func clearDefaultView(tx *gorm.DB, userId uint) error {
+	return tx.Model(&View{}).Where("user_id = ?", userId).Where("is_default = ?", true).Update("is_default", false).Error
+}
+
+func (repo *viewRepository) CreateView(ctx context.Context, saveViewDto *dtos.SaveViewDTO) (uint, error) {
+	view := View{
+		Name:      saveViewDto.Name,
+		UserID:    saveViewDto.UserID,
+		Filters:   saveViewDto.Filters,
+		SortBy:    saveViewDto.SortBy,
+		Order:     saveViewDto.Order,
+		Search:    saveViewDto.Search,
+		LabelIDs:  saveViewDto.LabelIDs,
+		IsDefault: saveViewDto.IsDefault,
+	}
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := lastPosition(tx, &View{}, userViews(saveViewDto.UserID))
+		if err != nil {
+			return err
+		}
+		view.Position = position
+
+		if view.IsDefault {
+			if err = clearDefaultView(tx, saveViewDto.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&view).Error
+	})
+
+	if err != nil {
+		log.Println("Error while creating view", err)
+		return 0, errors.New("unable to create view, please try again")
+	}
+	return view.ID, nil
+}
+
+func (repo *viewRepository) UpdateView(ctx context.Context, viewId uint, saveViewDto *dtos.SaveViewDTO) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if saveViewDto.IsDefault {
+			if err := clearDefaultView(tx, saveViewDto.UserID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&View{}).
+			Where("id = ?", viewId).
+			Select("Name", "Filters", "SortBy", "Order", "Search", "LabelIDs", "IsDefault").
+			Updates(View{
+				Name:      saveViewDto.Name,
+				Filters:   saveViewDto.Filters,
+				SortBy:    saveViewDto.SortBy,
+				Order:     saveViewDto.Order,
+				Search:    saveViewDto.Search,
+				LabelIDs:  saveViewDto.LabelIDs,
+				IsDefault: saveViewDto.IsDefault,
+			}).Error
+	})
+
+	if err != nil {
+		log.Println("Error while updating view", err)
+		return errors.New("unable to update view")
+	}
+	return nil
+}
+
+func (repo *viewRepository) DeleteView(ctx context.Context, viewId uint) error {
+	result := repo.db.WithContext(ctx).Unscoped().Delete(&View{}, viewId)
+	if result.Error != nil {
+		log.Println("Error while deleting view", result.Error)
+		return errors.New("unable to delete view")
+	}
+	return nil
+}
+
+func (repo *viewRepository) FindViewByUserId(ctx context.Context, viewId uint, userId uint) (*View, error) {
+	view := View{}
+	result := repo.db.WithContext(ctx).Where("id = ?", viewId).Where("user_id = ?", userId).First(&view)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("view not found")
+		}
+		return nil, result.Error
+	}
+	return &view, nil
+}
+
+func (repo *viewRepository) FindDefaultView(ctx context.Context, userId uint) (*View, error) {
+	view := View{}
+	result := repo.db.WithContext(ctx).Where("user_id = ?", userId).Where("is_default = ?", true).First(&view)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("view not found")
+		}
+		return nil, result.Error
+	}
+	return &view, nil
+}
+
+func (repo *viewRepository) FetchViews(ctx context.Context, userId uint) ([]View, error) {
+	views := make([]View, 0)
+	result := repo.db.WithContext(ctx).Where("user_id = ?", userId).Order("position asc, id asc").Find(&views)
+	if result.Error != nil {
+		log.Println("Error while fetching views", result.Error)
+		return nil, errors.New("error while fetching views")
+	}
+	return views, nil
+}
+
+func (repo *viewRepository) MoveView(ctx context.Context, viewId uint, userId uint, afterId *uint, beforeId *uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return movePosition(tx, &View{}, userViews(userId), viewId, afterId, beforeId)
+	})
+	return positionError(err, "view")
+}
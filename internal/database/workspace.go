@@ -0,0 +1,21 @@
+package database
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+// Workspace is a team board whose todos are visible to every one of its members.
+type Workspace struct {
+	Model
+	Name    string              `json:"name"`
+	OwnerID uint                `gorm:"index" json:"owner_id"`
+	Owner   User                `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Role    enums.WorkspaceRole `gorm:"-" json:"role,omitempty"`
+}
+
+type WorkspaceMember struct {
+	Model
+	WorkspaceID uint                `gorm:"uniqueIndex:idx_workspace_members_workspace_user" json:"workspace_id"`
+	Workspace   Workspace           `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID      uint                `gorm:"uniqueIndex:idx_workspace_members_workspace_user;index" json:"user_id"`
+	User        User                `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Role        enums.WorkspaceRole `json:"role"`
+}
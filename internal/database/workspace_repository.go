@@ -0,0 +1,207 @@
+package database
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"log"
+)
+
+type WorkspaceRepository interface {
+	CreateWorkspace(ctx context.Context, createWorkspaceDto *dtos.CreateWorkspaceDTO) (uint, error)
+	UpdateWorkspace(ctx context.Context, workspaceId uint, updateWorkspaceDto *dtos.UpdateWorkspaceDTO) error
+	DeleteWorkspace(ctx context.Context, workspaceId uint) error
+	FindWorkspaceForUser(ctx context.Context, workspaceId uint, userId uint) (*Workspace, error)
+	FetchWorkspaces(ctx context.Context, userId uint) ([]Workspace, error)
+	FetchOwnedWorkspaces(ctx context.Context, userId uint) ([]Workspace, error)
+	AddMember(ctx context.Context, workspaceId uint, userId uint, role enums.WorkspaceRole) error
+	FindMember(ctx context.Context, workspaceId uint, userId uint) (*WorkspaceMember, error)
+	FetchMembers(ctx context.Context, workspaceId uint) ([]WorkspaceMember, error)
+	UpdateMemberRole(ctx context.Context, workspaceId uint, userId uint, role enums.WorkspaceRole) error
+	RemoveMember(ctx context.Context, workspaceId uint, userId uint) error
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// CreateWorkspace creates a workspace with its creator as the owner and first member.
+func (repo *workspaceRepository) CreateWorkspace(ctx context.Context, createWorkspaceDto *dtos.CreateWorkspaceDTO) (uint, error) {
+	workspace := Workspace{
+		Name:    createWorkspaceDto.Name,
+		OwnerID: createWorkspaceDto.OwnerID,
+	}
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      createWorkspaceDto.OwnerID,
+			Role:        enums.WorkspaceOwner,
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return workspace.ID, nil
+}
+
+func (repo *workspaceRepository) UpdateWorkspace(ctx context.Context, workspaceId uint, updateWorkspaceDto *dtos.UpdateWorkspaceDTO) error {
+	result := repo.db.WithContext(ctx).Model(&Workspace{}).Where("id = ?", workspaceId).Update("name", updateWorkspaceDto.Name)
+	if result.Error != nil {
+		log.Println("Error while updating workspace", result.Error)
+		return errors.New("unable to update workspace, please try again")
+	}
+	return nil
+}
+
+// DeleteWorkspace deletes a workspace and its memberships. Its todos, trashed ones included,
+// go back to being personal todos of whoever created them.
+func (repo *workspaceRepository) DeleteWorkspace(ctx context.Context, workspaceId uint) error {
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Todo{}).Where("workspace_id = ?", workspaceId).Update("workspace_id", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		result = tx.Unscoped().Where("workspace_id = ?", workspaceId).Delete(&WorkspaceMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Where("id = ?", workspaceId).Delete(&Workspace{}).Error
+	})
+	if err != nil {
+		log.Println("Error while deleting workspace", err)
+		return errors.New("unable to delete workspace, please try again")
+	}
+	return nil
+}
+
+// FindWorkspaceForUser finds a workspace the user is a member of, with Role set to their role in it.
+func (repo *workspaceRepository) FindWorkspaceForUser(ctx context.Context, workspaceId uint, userId uint) (*Workspace, error) {
+	member, err := repo.FindMember(ctx, workspaceId, userId)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+
+	workspace := Workspace{}
+	result := repo.db.WithContext(ctx).Where("id = ?", workspaceId).First(&workspace)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("workspace not found")
+		}
+		return nil, result.Error
+	}
+
+	workspace.Role = member.Role
+	return &workspace, nil
+}
+
+// FetchWorkspaces lists the workspaces a user is a member of, with Role set to their role in each.
+func (repo *workspaceRepository) FetchWorkspaces(ctx context.Context, userId uint) ([]Workspace, error) {
+	var members []WorkspaceMember
+	result := repo.db.WithContext(ctx).
+		Joins("Workspace").
+		Where("workspace_members.user_id = ?", userId).
+		Order("workspace_members.workspace_id asc").
+		Find(&members)
+	if result.Error != nil {
+		log.Println("Error while fetching workspaces", result.Error)
+		return nil, errors.New("error while fetching workspaces")
+	}
+
+	workspaces := make([]Workspace, 0, len(members))
+	for _, member := range members {
+		workspace := member.Workspace
+		workspace.Role = member.Role
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, nil
+}
+
+// FetchOwnedWorkspaces lists the workspaces a user created.
+func (repo *workspaceRepository) FetchOwnedWorkspaces(ctx context.Context, userId uint) ([]Workspace, error) {
+	var workspaces []Workspace
+	result := repo.db.WithContext(ctx).Where("owner_id = ?", userId).Order("id asc").Find(&workspaces)
+	if result.Error != nil {
+		log.Println("Error while fetching owned workspaces", result.Error)
+		return nil, errors.New("error while fetching workspaces")
+	}
+	return workspaces, nil
+}
+
+func (repo *workspaceRepository) AddMember(ctx context.Context, workspaceId uint, userId uint, role enums.WorkspaceRole) error {
+	member := WorkspaceMember{
+		WorkspaceID: workspaceId,
+		UserID:      userId,
+		Role:        role,
+	}
+
+	result := repo.db.WithContext(ctx).Create(&member)
+	if result.Error != nil {
+		log.Println("Error while adding workspace member", result.Error)
+		return errors.New("unable to add member, please try again")
+	}
+	return nil
+}
+
+func (repo *workspaceRepository) FindMember(ctx context.Context, workspaceId uint, userId uint) (*WorkspaceMember, error) {
+	member := WorkspaceMember{}
+	result := repo.db.WithContext(ctx).Where("workspace_id = ?", workspaceId).Where("user_id = ?", userId).First(&member)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("member not found")
+		}
+		return nil, result.Error
+	}
+	return &member, nil
+}
+
+func (repo *workspaceRepository) FetchMembers(ctx context.Context, workspaceId uint) ([]WorkspaceMember, error) {
+	var members []WorkspaceMember
+	result := repo.db.WithContext(ctx).Preload("User").Where("workspace_id = ?", workspaceId).Order("id asc").Find(&members)
+	if result.Error != nil {
+		log.Println("Error while fetching workspace members", result.Error)
+		return nil, errors.New("error while fetching members")
+	}
+	return members, nil
+}
+
+func (repo *workspaceRepository) UpdateMemberRole(ctx context.Context, workspaceId uint, userId uint, role enums.WorkspaceRole) error {
+	result := repo.db.WithContext(ctx).Model(&WorkspaceMember{}).Where("workspace_id = ?", workspaceId).Where("user_id = ?", userId).Update("role", role)
+	if result.Error != nil {
+		log.Println("Error while updating workspace member", result.Error)
+		return errors.New("unable to update member, please try again")
+	}
+	return nil
+}
+
+// RemoveMember removes a membership outright, so the user can be added again later.
+func (repo *workspaceRepository) RemoveMember(ctx context.Context, workspaceId uint, userId uint) error {
+	result := repo.db.WithContext(ctx).Unscoped().Where("workspace_id = ?", workspaceId).Where("user_id = ?", userId).Delete(&WorkspaceMember{})
+	if result.Error != nil {
+		log.Println("Error while removing workspace member", result.Error)
+		return errors.New("unable to remove member, please try again")
+	}
+	return nil
+}
+
+// memberWorkspaceIds is a subquery of the ids of workspaces the user is a member of.
+func memberWorkspaceIds(db *gorm.DB, userId uint) *gorm.DB {
+	return db.Model(&WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userId)
+}
+
+// managedWorkspaceIds is a subquery of the ids of workspaces the user owns or administers.
+func managedWorkspaceIds(db *gorm.DB, userId uint) *gorm.DB {
+	return memberWorkspaceIds(db, userId).Where("role IN ?", []enums.WorkspaceRole{enums.WorkspaceOwner, enums.WorkspaceAdmin})
+}
@@ -0,0 +1,5 @@
+package dtos
+
+type AttachLabelsDTO struct {
+	LabelIDs []uint `json:"label_ids" validate:"required,gt=0,dive,required"`
+}
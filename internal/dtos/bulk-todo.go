@@ -0,0 +1,32 @@
+package dtos
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+type BulkTodoAction string
+
+const (
+	BulkDelete     BulkTodoAction = "delete"
+	BulkPin        BulkTodoAction = "pin"
+	BulkUnpin      BulkTodoAction = "unpin"
+	BulkArchive    BulkTodoAction = "archive"
+	BulkComplete   BulkTodoAction = "complete"
+	BulkAddLabel   BulkTodoAction = "add_label"
+	BulkChangeType BulkTodoAction = "change_type"
+)
+
+// BulkTodoDTO applies a single action to every todo in IDs. LabelID is only used by add_label and Type by change_type.
+type BulkTodoDTO struct {
+	IDs     []uint         `json:"ids" validate:"required,gt=0,max=100,dive,required"`
+	Action  BulkTodoAction `json:"action" validate:"required,oneof=delete pin unpin archive complete add_label change_type"`
+	LabelID uint           `json:"label_id" validate:"required_if=Action add_label"`
+	Type    enums.TodoType `json:"type" validate:"required_if=Action change_type,omitempty,oneof=checklist text"`
+}
+
+// BulkTodoResult reports how a bulk action went for one of the todos it was given. NextTodoID is the next
+// occurrence created when complete finishes an occurrence of a recurring todo.
+type BulkTodoResult struct {
+	ID         uint   `json:"id"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	NextTodoID uint   `json:"next_todo_id,omitempty"`
+}
@@ -0,0 +1,7 @@
+package dtos
+
+// CalendarFeedDetails is handed out once when a calendar feed is created, since only a hash of its token is kept.
+type CalendarFeedDetails struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
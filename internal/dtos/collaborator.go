@@ -0,0 +1,12 @@
+package dtos
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+// Collaborator is a user a todo is shared with, without the rest of their account details.
+type Collaborator struct {
+	UserID    uint            `json:"user_id"`
+	FirstName string          `json:"first_name"`
+	LastName  string          `json:"last_name"`
+	Email     string          `json:"email"`
+	Role      enums.ShareRole `json:"role"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+type CreateCommentDTO struct {
+	Body   string `json:"body" validate:"required,max=5000"`
+	TodoID uint   `json:"todo_id" validate:"-"`
+	UserID uint   `json:"user_id" validate:"-"`
+}
+
+type UpdateCommentDTO struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
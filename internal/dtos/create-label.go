@@ -0,0 +1,7 @@
+package dtos
+
+type CreateLabelDTO struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Colour string `json:"colour" validate:"required,hexcolor"`
+	UserID uint   `json:"user_id" validate:"-"`
+}
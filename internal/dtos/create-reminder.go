@@ -0,0 +1,10 @@
+package dtos
+
+import "time"
+
+type CreateReminderDTO struct {
+	RemindAt      *time.Time `json:"remind_at" validate:"required_without=OffsetMinutes,excluded_with=OffsetMinutes"`
+	OffsetMinutes *int       `json:"offset_minutes" validate:"required_without=RemindAt,omitempty,min=0"`
+	TodoID        uint       `json:"-" validate:"-"`
+	UserID        uint       `json:"-" validate:"-"`
+}
@@ -1,17 +1,27 @@
 package dtos
 
-import "github.com/horlerdipo/todo-golang/internal/enums"
+import (
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"time"
+)
 
 type CreateTodoDTO struct {
-	Title     string         `json:"title" validate:"required"`
-	Content   *string        `json:"content" validate:"required_if=Type text"`
-	Type      enums.TodoType `json:"type" validate:"required,oneof=checklist text"`
-	UserID    uint           `json:"user_id" validate:"-"`
-	Checklist []string       `json:"checklist" validate:"required_if=Type checklist,omitempty,gt=0,dive,required"`
+	Title       string             `json:"title" validate:"required"`
+	Content     *string            `json:"content" validate:"required_if=Type text"`
+	Type        enums.TodoType     `json:"type" validate:"required,oneof=checklist text"`
+	UserID      uint               `json:"user_id" validate:"-"`
+	WorkspaceID *uint              `json:"workspace_id" validate:"-"`
+	Checklist   []string           `json:"checklist" validate:"required_if=Type checklist,omitempty,gt=0,dive,required"`
+	StartAt     *time.Time         `json:"start_at" validate:"omitempty"`
+	DueAt       *time.Time         `json:"due_at" validate:"omitempty"`
+	Recurrence  *string            `json:"recurrence" validate:"omitempty"`
+	Priority    enums.TodoPriority `json:"priority" validate:"omitempty,oneof=none low medium high urgent"`
+	LabelIDs    []uint             `json:"-" validate:"-"`
 }
 
 type ChecklistItem struct {
-	Item string `json:"item" validate:"required"`
+	Item     string `json:"item" validate:"required"`
+	ParentID *uint  `json:"parent_id" validate:"omitempty"`
 }
 
 type ChecklistStatus struct {
@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a keyset paginated list: the sort value and id of the record the next page starts
+// after, or the previous page ends before when Backward is set. It carries the sort it was made for, so following
+// a cursor keeps the same order.
+type Cursor struct {
+	SortBy   string          `json:"s"`
+	Order    Order           `json:"o"`
+	Value    json.RawMessage `json:"v"`
+	ID       uint            `json:"i"`
+	Backward bool            `json:"b,omitempty"`
+}
+
+// Encode signs the cursor with secret and returns it as an opaque, URL safe token.
+func (c Cursor) Encode(secret string) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + cursorSignature(encoded, secret), nil
+}
+
+// DecodeCursor verifies a token made by Cursor.Encode with the same secret and returns the cursor inside it.
+func DecodeCursor(token string, secret string) (Cursor, error) {
+	var cursor Cursor
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(cursorSignature(encoded, secret))) {
+		return cursor, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(payload, &cursor); err != nil || !cursor.Order.IsValid() {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func cursorSignature(encoded string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
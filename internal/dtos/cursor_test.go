@@ -0,0 +1,51 @@
+package dtos
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	t.Parallel()
+	cursor := Cursor{SortBy: "due_at", Order: OrderDesc, Value: json.RawMessage(`"2025-03-01T00:00:00Z"`), ID: 42, Backward: true}
+
+	token, err := cursor.Encode("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeCursor(token, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, cursor) {
+		t.Errorf("expected decoded cursor to be %v, got %v", cursor, decoded)
+	}
+}
+
+func TestDecodeCursor_RejectsTamperedCursors(t *testing.T) {
+	t.Parallel()
+	token, err := Cursor{SortBy: "id", Order: OrderAsc, Value: json.RawMessage(`1`), ID: 1}.Encode("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, signature, _ := strings.Cut(token, ".")
+	forged, _ := Cursor{SortBy: "id", Order: OrderAsc, Value: json.RawMessage(`1`), ID: 99}.Encode("other-secret")
+	forgedEncoded, _, _ := strings.Cut(forged, ".")
+
+	for _, tampered := range []string{
+		"",
+		encoded,
+		encoded + ".",
+		forged,
+		forgedEncoded + "." + signature,
+		encoded + "." + signature + "x",
+	} {
+		if _, err := DecodeCursor(tampered, "secret"); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected DecodeCursor(%q) to be ErrInvalidCursor, got %v", tampered, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FilterOperator is how a filter compares a field with its value, given as filters[field][operator]=value.
+type FilterOperator string
+
+const (
+	EqualOperator FilterOperator = "eq"
+	// LikeOperator matches fields containing the value, case-insensitively
+	LikeOperator FilterOperator = "like"
+	// InOperator matches any of a comma separated list of values
+	InOperator             FilterOperator = "in"
+	GreaterThanOperator    FilterOperator = "gt"
+	GreaterOrEqualOperator FilterOperator = "gte"
+	LessThanOperator       FilterOperator = "lt"
+	LessOrEqualOperator    FilterOperator = "lte"
+	// BetweenOperator matches values within two comma separated bounds, inclusive
+	BetweenOperator FilterOperator = "between"
+	// NullOperator matches empty fields, or fields with a value when its value is false
+	NullOperator FilterOperator = "null"
+)
+
+// negatedPrefix negates any operator, e.g. filters[priority][not_in]=low,none. filters[field][not] negates equality.
+const negatedPrefix = "not_"
+
+// FilterOperators lists the operators each filter type supports.
+var FilterOperators = map[FilterType][]FilterOperator{
+	StringFilter:      {EqualOperator, LikeOperator, InOperator, NullOperator},
+	IntegerFilter:     {EqualOperator, InOperator, GreaterThanOperator, GreaterOrEqualOperator, LessThanOperator, LessOrEqualOperator, BetweenOperator, NullOperator},
+	DateFilter:        {EqualOperator, GreaterThanOperator, GreaterOrEqualOperator, LessThanOperator, LessOrEqualOperator, BetweenOperator, NullOperator},
+	BooleanFilter:     {EqualOperator, NullOperator},
+	IntegerListFilter: {EqualOperator},
+}
+
+func (f FilterType) Supports(operator FilterOperator) bool {
+	return slices.Contains(FilterOperators[f], operator)
+}
+
+// FilterCondition is a filter with an operator, e.g. filters[due_at][not_between]=2025-03-01,2025-03-31.
+type FilterCondition struct {
+	Field    string         `json:"field"`
+	Operator FilterOperator `json:"operator"`
+	Negated  bool           `json:"negated"`
+	Value    string         `json:"value"`
+}
+
+// parseFilterCondition reads the operator out of a filter key such as "due_at][gt", returning false when the key
+// names a plain field.
+func parseFilterCondition(key string, value string) (FilterCondition, bool) {
+	field, operator, ok := strings.Cut(key, "][")
+	if !ok {
+		return FilterCondition{}, false
+	}
+
+	condition := FilterCondition{Field: field, Operator: FilterOperator(operator), Value: value}
+	if operator == "not" {
+		condition.Operator = EqualOperator
+		condition.Negated = true
+	} else if strings.HasPrefix(operator, negatedPrefix) {
+		condition.Operator = FilterOperator(strings.TrimPrefix(operator, negatedPrefix))
+		condition.Negated = true
+	}
+	return condition, true
+}
+
+// ConvertCondition validates condition's operator against the type of filter it is on and converts its value:
+// a list for in, a pair of bounds for between, a bool for null and a single value otherwise.
+func (p *PaginationOptions) ConvertCondition(condition FilterCondition) (interface{}, error) {
+	allowedFilter, ok := p.AllowedFilters[condition.Field]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("filter %s is not allowed", condition.Field))
+	}
+
+	if !allowedFilter.Type.Supports(condition.Operator) {
+		return nil, errors.New(fmt.Sprintf("filter %s does not support the %s operator", condition.Field, condition.Operator))
+	}
+
+	switch condition.Operator {
+	case NullOperator:
+		return convertFilterValue(condition.Field, BooleanFilter, condition.Value)
+	case InOperator:
+		return convertFilterValues(condition.Field, allowedFilter.Type, strings.Split(condition.Value, ","))
+	case BetweenOperator:
+		bounds := strings.Split(condition.Value, ",")
+		if len(bounds) != 2 {
+			return nil, errors.New(fmt.Sprintf("filter %s between needs two comma separated values, got %v", condition.Field, condition.Value))
+		}
+		return convertFilterValues(condition.Field, allowedFilter.Type, bounds)
+	default:
+		return convertFilterValue(condition.Field, allowedFilter.Type, condition.Value)
+	}
+}
+
+func convertFilterValues(column string, filterType FilterType, filters []string) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(filters))
+	for _, filter := range filters {
+		value, err := convertFilterValue(column, filterType, strings.TrimSpace(filter))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
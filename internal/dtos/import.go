@@ -0,0 +1,8 @@
+package dtos
+
+// ImportRowError reports why a row was left out of an import. Row is the record of a CSV file, the line of a
+// Markdown file or the position of a card in a Trello board, counting from 1.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
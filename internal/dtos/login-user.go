@@ -1,18 +1,29 @@
 package dtos
 
 type LoginUserDTO struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=100"`
 }
 
 type LoginUserResponseDto struct {
-	Email     string       `json:"email"`
-	FirstName string       `json:"first_name"`
-	LastName  string       `json:"last_name"`
-	Token     TokenDetails `json:"token"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	AuthTokens
+}
+
+// AuthTokens pairs a short-lived access token with the refresh token that gets the next one.
+type AuthTokens struct {
+	Token        TokenDetails `json:"token"`
+	RefreshToken TokenDetails `json:"refresh_token"`
 }
 
 type TokenDetails struct {
 	Token string `json:"token"`
 	Exp   string `json:"exp"`
 }
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
@@ -0,0 +1,48 @@
+package dtos
+
+import "encoding/json"
+
+// Nullable is a request field that can be left out, to keep what is already stored, or sent as null, to clear it.
+// Set is only true when the field was sent, and Value is nil when it was sent as null. Tag it omitzero so that a
+// field that was never set is left out when it is encoded again.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// Null returns a field that clears what is stored.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true}
+}
+
+// NullableOf returns a field set to value, which clears what is stored when it is nil.
+func NullableOf[T any](value *T) Nullable[T] {
+	return Nullable[T]{Set: true, Value: value}
+}
+
+// Or returns the value the field was sent with, or current when it was left out.
+func (field Nullable[T]) Or(current *T) *T {
+	if !field.Set {
+		return current
+	}
+	return field.Value
+}
+
+func (field *Nullable[T]) UnmarshalJSON(data []byte) error {
+	field.Set = true
+	if string(data) == "null" {
+		field.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	field.Value = &value
+	return nil
+}
+
+func (field Nullable[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(field.Value)
+}
@@ -0,0 +1,64 @@
+package dtos
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNullable_TellsLeftOutFromNull(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Note Nullable[string] `json:"note,omitzero"`
+	}
+
+	tests := []struct {
+		body      string
+		wantSet   bool
+		wantValue string
+	}{
+		{body: `{}`, wantSet: false},
+		{body: `{"note":null}`, wantSet: true},
+		{body: `{"note":"hello"}`, wantSet: true, wantValue: "hello"},
+	}
+
+	for _, tt := range tests {
+		var decoded request
+		if err := json.Unmarshal([]byte(tt.body), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Note.Set != tt.wantSet {
+			t.Errorf("%s: expected Set to be %v", tt.body, tt.wantSet)
+		}
+		if tt.wantValue == "" && decoded.Note.Value != nil {
+			t.Errorf("%s: expected Value to be nil, got %v", tt.body, *decoded.Note.Value)
+		}
+		if tt.wantValue != "" && (decoded.Note.Value == nil || *decoded.Note.Value != tt.wantValue) {
+			t.Errorf("%s: expected Value to be %v, got %v", tt.body, tt.wantValue, decoded.Note.Value)
+		}
+
+		encoded, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tt.body {
+			t.Errorf("expected %s to be encoded as it was sent, got %s", tt.body, encoded)
+		}
+	}
+}
+
+func TestNullable_Or(t *testing.T) {
+	t.Parallel()
+	current := "current"
+	sent := "sent"
+
+	if got := (Nullable[string]{}).Or(&current); got != &current {
+		t.Errorf("expected a field that was left out to keep the current value, got %v", got)
+	}
+	if got := Null[string]().Or(&current); got != nil {
+		t.Errorf("expected a null field to clear the current value, got %v", *got)
+	}
+	if got := NullableOf(&sent).Or(&current); got != &sent {
+		t.Errorf("expected a field that was sent to replace the current value, got %v", got)
+	}
+}
@@ -3,7 +3,11 @@ package dtos
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Order string
@@ -18,11 +22,16 @@ const (
 )
 
 type PaginationOptions struct {
-	Page              int                      `json:"page"`
-	PerPage           int                      `json:"per_page"`
-	SortBy            string                   `json:"sort_by"`
-	Order             Order                    `json:"orderBy"`
-	Filters           map[string]string        `json:"filters"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"per_page"`
+	SortBy     string            `json:"sort_by"`
+	Order      Order             `json:"orderBy"`
+	Filters    map[string]string `json:"filters"`
+	Conditions []FilterCondition `json:"conditions"`
+	Search     string            `json:"q"`
+	// Cursor switches to keyset pagination when set, or when an empty cursor was asked for to start a list
+	Cursor            string                   `json:"cursor"`
+	UseCursor         bool                     `json:"-"`
 	AllowedSortFields map[string]bool          `json:"-"`
 	AllowedFilters    map[string]AllowedFilter `json:"-"`
 }
@@ -37,8 +46,53 @@ const (
 	IntegerFilter FilterType = "integer"
 	BooleanFilter FilterType = "boolean"
 	StringFilter  FilterType = "string"
+	DateFilter    FilterType = "date"
+	// IntegerListFilter accepts a comma separated list of integers, e.g. filters[labels]=1,2,3
+	IntegerListFilter FilterType = "integer_list"
 )
 
+// DateFilterLayouts are the layouts accepted for DateFilter values, tried in order.
+var DateFilterLayouts = []string{
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// PaginationOptionsFromQuery reads page, per_page, cursor, sort_by, order, q, filters[field] and
+// filters[field][operator] from a query string.
+func PaginationOptionsFromQuery(query url.Values) PaginationOptions {
+	page, _ := strconv.Atoi(query.Get("page"))
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+
+	filters := make(map[string]string)
+	conditions := make([]FilterCondition, 0)
+	for key, values := range query {
+		if strings.HasPrefix(key, "filters[") {
+			field := strings.TrimSuffix(strings.TrimPrefix(key, "filters["), "]")
+			condition, ok := parseFilterCondition(field, values[0])
+			switch {
+			case !ok:
+				filters[field] = values[0]
+			case condition.Operator == EqualOperator && !condition.Negated:
+				filters[condition.Field] = condition.Value
+			default:
+				conditions = append(conditions, condition)
+			}
+		}
+	}
+
+	return PaginationOptions{
+		Page:       page,
+		PerPage:    perPage,
+		SortBy:     query.Get("sort_by"),
+		Order:      Order(query.Get("order")),
+		Filters:    filters,
+		Conditions: conditions,
+		Search:     strings.TrimSpace(query.Get("q")),
+		Cursor:     query.Get("cursor"),
+		UseCursor:  query.Has("cursor"),
+	}
+}
+
 func (p *PaginationOptions) ApplyDefaults() {
 	if p.Page <= 0 {
 		p.Page = 1
@@ -73,6 +127,46 @@ func (p *PaginationOptions) ValidateFilters() {
 			delete(p.Filters, key)
 		}
 	}
+
+	conditions := make([]FilterCondition, 0, len(p.Conditions))
+	for _, condition := range p.Conditions {
+		if _, ok := p.AllowedFilters[condition.Field]; ok {
+			conditions = append(conditions, condition)
+		}
+	}
+	p.Conditions = conditions
+}
+
+// ErrInvalidQuery is returned by Validate for a list query with a filter or sort the list does not allow.
+var ErrInvalidQuery = errors.New("invalid list query")
+
+// Validate checks the sort and every filter against the allowed ones and their values against the type of their
+// filter. Unlike Configure, which drops what is not allowed, it fails, for queries that are saved to be run later.
+func (p *PaginationOptions) Validate() error {
+	if p.SortBy != "" && !p.AllowedSortFields[p.SortBy] {
+		return fmt.Errorf("%w: sorting by %s is not allowed", ErrInvalidQuery, p.SortBy)
+	}
+	if p.Order != "" && !p.Order.IsValid() {
+		return fmt.Errorf("%w: order must be asc or desc", ErrInvalidQuery)
+	}
+
+	fields := make([]string, 0, len(p.Filters))
+	for field := range p.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if _, err := p.ConvertFilter(field); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+	}
+
+	for _, condition := range p.Conditions {
+		if _, err := p.ConvertCondition(condition); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+	}
+	return nil
 }
 
 func (p *PaginationOptions) Configure() {
@@ -96,7 +190,12 @@ func (p *PaginationOptions) ConvertFilter(column string) (interface{}, error) {
 		return nil, errors.New(fmt.Sprintf("filter %s is not allowed", column))
 	}
 
-	switch allowedFilter.Type {
+	return convertFilterValue(column, allowedFilter.Type, filter)
+}
+
+// convertFilterValue converts a single filter value on column to the Go type filterType compares with.
+func convertFilterValue(column string, filterType FilterType, filter string) (interface{}, error) {
+	switch filterType {
 	case IntegerFilter:
 		convertedInt, err := strconv.ParseInt(filter, 10, 32)
 		if err != nil {
@@ -110,6 +209,26 @@ func (p *PaginationOptions) ConvertFilter(column string) (interface{}, error) {
 		return true, nil
 	case StringFilter:
 		return filter, nil
+	case IntegerListFilter:
+		values := strings.Split(filter, ",")
+		convertedInts := make([]int64, 0, len(values))
+		for _, value := range values {
+			convertedInt, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("unable to convert filter column: %s value: %v to a list of ints", column, filter))
+			}
+			convertedInts = append(convertedInts, convertedInt)
+		}
+		return convertedInts, nil
+	case DateFilter:
+		for _, layout := range DateFilterLayouts {
+			convertedDate, err := time.ParseInLocation(layout, filter, time.Local)
+			if err == nil {
+				//stored times are in UTC, so compare with them in UTC
+				return convertedDate.UTC(), nil
+			}
+		}
+		return nil, errors.New(fmt.Sprintf("unable to convert filter column: %s value: %v to date", column, filter))
 	default:
 		return filter, nil
 	}
@@ -126,4 +245,26 @@ type PaginatedResponseMeta struct {
 	PerPage     int `json:"per_page"`
 	LastPage    int `json:"last_page"`
 	FirstPage   int `json:"first_page"`
+	// NextCursor and PrevCursor are only set in cursor mode, when there is a page in that direction
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+// LinkHeader returns an RFC 8288 Link header value pointing at the next and previous pages of the list requested
+// at requestUrl, or an empty string when there are none.
+func (m PaginatedResponseMeta) LinkHeader(requestUrl *url.URL) string {
+	links := make([]string, 0, 2)
+	addLink := func(rel string, cursor *string) {
+		if cursor == nil {
+			return
+		}
+		query := requestUrl.Query()
+		query.Set("cursor", *cursor)
+		link := url.URL{Path: requestUrl.Path, RawQuery: query.Encode()}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, link.String(), rel))
+	}
+
+	addLink("next", m.NextCursor)
+	addLink("prev", m.PrevCursor)
+	return strings.Join(links, ", ")
 }
@@ -1,6 +1,13 @@
 package dtos
 
-import "testing"
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestPaginationOptions_ApplyDefaults(t *testing.T) {
 	t.Parallel()
@@ -171,3 +178,212 @@ func TestPaginationOptions_ConvertFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestPaginationOptions_ConvertDateFilter(t *testing.T) {
+	t.Parallel()
+	paginationOptions := PaginationOptions{}
+	paginationOptions.ApplyDefaults()
+	paginationOptions.Filters = map[string]string{
+		"due_from": "2025-03-01",
+		"due_to":   "2025-03-31T18:30:00Z",
+		"due_bad":  "next tuesday",
+	}
+	paginationOptions.AllowedFilters = map[string]AllowedFilter{
+		"due_from": {
+			Type: DateFilter,
+		},
+		"due_to": {
+			Type: DateFilter,
+		},
+		"due_bad": {
+			Type: DateFilter,
+		},
+	}
+
+	dueFrom, err := paginationOptions.ConvertFilter("due_from")
+	if err != nil {
+		t.Error(err)
+	}
+	if !dueFrom.(time.Time).Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("expected due_from to be 2025-03-01, got %v", dueFrom)
+	}
+
+	dueTo, err := paginationOptions.ConvertFilter("due_to")
+	if err != nil {
+		t.Error(err)
+	}
+	if !dueTo.(time.Time).Equal(time.Date(2025, 3, 31, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected due_to to be 2025-03-31T18:30:00Z, got %v", dueTo)
+	}
+
+	_, err = paginationOptions.ConvertFilter("due_bad")
+	if err == nil {
+		t.Error("paginationOptions.ConvertFilter(\"due_bad\") should have been an error")
+	}
+}
+
+func TestPaginationOptions_ConvertIntegerListFilter(t *testing.T) {
+	t.Parallel()
+	paginationOptions := PaginationOptions{}
+	paginationOptions.ApplyDefaults()
+	paginationOptions.Filters = map[string]string{
+		"labels":     "1, 2,3",
+		"labels_bad": "1,two",
+	}
+	paginationOptions.AllowedFilters = map[string]AllowedFilter{
+		"labels": {
+			Type: IntegerListFilter,
+		},
+		"labels_bad": {
+			Type: IntegerListFilter,
+		},
+	}
+
+	labels, err := paginationOptions.ConvertFilter("labels")
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(labels, []int64{1, 2, 3}) {
+		t.Errorf("expected labels to be [1 2 3], got %v", labels)
+	}
+
+	_, err = paginationOptions.ConvertFilter("labels_bad")
+	if err == nil {
+		t.Error("paginationOptions.ConvertFilter(\"labels_bad\") should have been an error")
+	}
+}
+
+func TestPaginationOptionsFromQuery_FilterOperators(t *testing.T) {
+	t.Parallel()
+	query, _ := url.ParseQuery("filters[title]=milk&filters[pinned][eq]=true&filters[due_at][gt]=2025-03-01&filters[priority][not_in]=low,none&filters[title][not]=bread")
+	paginationOptions := PaginationOptionsFromQuery(query)
+
+	expectedFilters := map[string]string{"title": "milk", "pinned": "true"}
+	if !reflect.DeepEqual(paginationOptions.Filters, expectedFilters) {
+		t.Errorf("expected filters to be %v, got %v", expectedFilters, paginationOptions.Filters)
+	}
+
+	expectedConditions := []FilterCondition{
+		{Field: "due_at", Operator: GreaterThanOperator, Value: "2025-03-01"},
+		{Field: "priority", Operator: InOperator, Negated: true, Value: "low,none"},
+		{Field: "title", Operator: EqualOperator, Negated: true, Value: "bread"},
+	}
+	if len(paginationOptions.Conditions) != len(expectedConditions) {
+		t.Fatalf("expected %d conditions, got %v", len(expectedConditions), paginationOptions.Conditions)
+	}
+	for _, expected := range expectedConditions {
+		if !slices.Contains(paginationOptions.Conditions, expected) {
+			t.Errorf("expected conditions to contain %v, got %v", expected, paginationOptions.Conditions)
+		}
+	}
+}
+
+func TestPaginationOptions_ValidateFiltersDropsUnknownConditions(t *testing.T) {
+	t.Parallel()
+	paginationOptions := PaginationOptions{
+		Conditions: []FilterCondition{
+			{Field: "title", Operator: LikeOperator, Value: "milk"},
+			{Field: "password", Operator: LikeOperator, Value: "a"},
+		},
+		AllowedFilters: map[string]AllowedFilter{
+			"title": {
+				Type: StringFilter,
+			},
+		},
+	}
+
+	paginationOptions.Configure()
+	if len(paginationOptions.Conditions) != 1 || paginationOptions.Conditions[0].Field != "title" {
+		t.Errorf("expected only the title condition to remain, got %v", paginationOptions.Conditions)
+	}
+}
+
+func TestPaginationOptions_ConvertCondition(t *testing.T) {
+	t.Parallel()
+	paginationOptions := PaginationOptions{}
+	paginationOptions.AllowedFilters = map[string]AllowedFilter{
+		"title": {
+			Type: StringFilter,
+		},
+		"occurrence": {
+			Type: IntegerFilter,
+		},
+		"due_at": {
+			Type: DateFilter,
+		},
+		"pinned": {
+			Type: BooleanFilter,
+		},
+	}
+
+	tests := []struct {
+		condition FilterCondition
+		expected  interface{}
+	}{
+		{FilterCondition{Field: "title", Operator: LikeOperator, Value: "milk"}, "milk"},
+		{FilterCondition{Field: "title", Operator: InOperator, Value: "milk, bread"}, []interface{}{"milk", "bread"}},
+		{FilterCondition{Field: "occurrence", Operator: GreaterThanOperator, Value: "2"}, int64(2)},
+		{FilterCondition{Field: "occurrence", Operator: BetweenOperator, Value: "2,5"}, []interface{}{int64(2), int64(5)}},
+		{FilterCondition{Field: "due_at", Operator: LessOrEqualOperator, Value: "2025-03-01"}, time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local).UTC()},
+		{FilterCondition{Field: "due_at", Operator: NullOperator, Value: "false"}, false},
+		{FilterCondition{Field: "pinned", Operator: NullOperator, Value: "true"}, true},
+	}
+	for _, test := range tests {
+		value, err := paginationOptions.ConvertCondition(test.condition)
+		if err != nil {
+			t.Errorf("unexpected error converting %v: %v", test.condition, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("expected %v to convert to %v, got %v", test.condition, test.expected, value)
+		}
+	}
+
+	invalid := []FilterCondition{
+		{Field: "title", Operator: GreaterThanOperator, Value: "milk"},
+		{Field: "pinned", Operator: LikeOperator, Value: "true"},
+		{Field: "due_at", Operator: InOperator, Value: "2025-03-01"},
+		{Field: "due_at", Operator: "near", Value: "2025-03-01"},
+		{Field: "occurrence", Operator: BetweenOperator, Value: "2"},
+		{Field: "occurrence", Operator: InOperator, Value: "2,three"},
+		{Field: "unknown", Operator: EqualOperator, Value: "1"},
+	}
+	for _, condition := range invalid {
+		if _, err := paginationOptions.ConvertCondition(condition); err == nil {
+			t.Errorf("expected converting %v to be an error", condition)
+		}
+	}
+}
+
+func TestPaginationOptions_Validate(t *testing.T) {
+	t.Parallel()
+	allowed := func(query url.Values) PaginationOptions {
+		paginationOptions := PaginationOptionsFromQuery(query)
+		paginationOptions.AllowedSortFields = map[string]bool{"due_at": true}
+		paginationOptions.AllowedFilters = map[string]AllowedFilter{
+			"title":  {Type: StringFilter},
+			"due_at": {Type: DateFilter},
+		}
+		return paginationOptions
+	}
+
+	valid := allowed(url.Values{"filters[title]": {"milk"}, "filters[due_at][lt]": {"2025-03-01"}, "sort_by": {"due_at"}, "order": {"desc"}})
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected the query to be valid, got %v", err)
+	}
+
+	invalid := []url.Values{
+		{"sort_by": {"title"}},
+		{"order": {"sideways"}},
+		{"filters[colour]": {"red"}},
+		{"filters[due_at]": {"someday"}},
+		{"filters[title][gt]": {"milk"}},
+		{"filters[colour][not]": {"red"}},
+	}
+	for _, query := range invalid {
+		paginationOptions := allowed(query)
+		if err := paginationOptions.Validate(); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("expected %v to be an invalid query, got %v", query, err)
+		}
+	}
+}
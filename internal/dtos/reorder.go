@@ -0,0 +1,7 @@
+package dtos
+
+// ReorderDTO places an item directly after AfterID and/or directly before BeforeID.
+type ReorderDTO struct {
+	AfterID  *uint `json:"after_id" validate:"required_without=BeforeID"`
+	BeforeID *uint `json:"before_id" validate:"required_without=AfterID"`
+}
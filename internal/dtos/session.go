@@ -0,0 +1,21 @@
+package dtos
+
+import "time"
+
+// Device describes where a login or a refresh came from.
+type Device struct {
+	Name      string
+	UserAgent string
+	IPAddress string
+}
+
+// SessionDetails is a session as listed to the user it belongs to, flagging the one the request was made with.
+type SessionDetails struct {
+	ID         uint      `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Current    bool      `json:"current"`
+}
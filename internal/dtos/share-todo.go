@@ -0,0 +1,8 @@
+package dtos
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+type ShareTodoDTO struct {
+	Email string          `json:"email" validate:"required,email"`
+	Role  enums.ShareRole `json:"role" validate:"required,oneof=viewer editor"`
+}
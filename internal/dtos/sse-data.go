@@ -3,12 +3,26 @@ package dtos
 type SSEEventType string
 
 const (
-	TodoCreated      SSEEventType = "todoCreated"
-	TodoDeleted      SSEEventType = "todoDeleted"
-	TodoUpdated      SSEEventType = "todoUpdated"
-	ChecklistAdded   SSEEventType = "checklistAdded"
-	ChecklistDeleted SSEEventType = "checklistDeleted"
-	ChecklistUpdated SSEEventType = "checklistUpdated"
+	TodoCreated       SSEEventType = "todoCreated"
+	TodoDeleted       SSEEventType = "todoDeleted"
+	TodoUpdated       SSEEventType = "todoUpdated"
+	ChecklistAdded    SSEEventType = "checklistAdded"
+	ChecklistDeleted  SSEEventType = "checklistDeleted"
+	ChecklistUpdated  SSEEventType = "checklistUpdated"
+	TodoRecurred      SSEEventType = "todoRecurred"
+	TodoCompleted     SSEEventType = "todoCompleted"
+	TodoReopened      SSEEventType = "todoReopened"
+	TodoShared        SSEEventType = "todoShared"
+	WorkspaceJoined   SSEEventType = "workspaceJoined"
+	CommentAdded      SSEEventType = "commentAdded"
+	CommentUpdated    SSEEventType = "commentUpdated"
+	CommentDeleted    SSEEventType = "commentDeleted"
+	ReminderDue       SSEEventType = "reminder"
+	LabelCreated      SSEEventType = "labelCreated"
+	LabelUpdated      SSEEventType = "labelUpdated"
+	LabelDeleted      SSEEventType = "labelDeleted"
+	TodoLabelsUpdated SSEEventType = "todoLabelsUpdated"
+	TodosBulkUpdated  SSEEventType = "todosBulkUpdated"
 )
 
 type SSEData struct {
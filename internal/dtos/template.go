@@ -0,0 +1,16 @@
+package dtos
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+// SaveTemplateDTO describes a todo template. Its dates are offsets, in minutes, from the moment it is instantiated.
+type SaveTemplateDTO struct {
+	Title              string             `json:"title" validate:"required"`
+	Content            *string            `json:"content" validate:"required_if=Type text"`
+	Type               enums.TodoType     `json:"type" validate:"required,oneof=checklist text"`
+	Checklist          []string           `json:"checklist" validate:"required_if=Type checklist,omitempty,gt=0,dive,required"`
+	Priority           enums.TodoPriority `json:"priority" validate:"omitempty,oneof=none low medium high urgent"`
+	LabelIDs           []uint             `json:"label_ids" validate:"omitempty"`
+	StartOffsetMinutes *int               `json:"start_offset_minutes" validate:"omitempty,min=0"`
+	DueOffsetMinutes   *int               `json:"due_offset_minutes" validate:"omitempty,min=0"`
+	UserID             uint               `json:"user_id" validate:"-"`
+}
@@ -0,0 +1,6 @@
+package dtos
+
+type UpdateLabelDTO struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Colour string `json:"colour" validate:"required,hexcolor"`
+}
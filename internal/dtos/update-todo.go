@@ -1,9 +1,18 @@
 package dtos
 
-import "github.com/horlerdipo/todo-golang/internal/enums"
+import (
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"time"
+)
 
+// UpdateTodoDTO replaces a todo's title, type and content. The dates, recurrence and priority are kept when they
+// are left out, and the dates and recurrence are cleared when they are sent as null.
 type UpdateTodoDTO struct {
-	Title   string         `json:"title" validate:"required"`
-	Content *string        `json:"content" validate:"required_if=Type text"`
-	Type    enums.TodoType `json:"type" validate:"required,oneof=checklist text"`
+	Title      string              `json:"title" validate:"required"`
+	Content    *string             `json:"content" validate:"required_if=Type text"`
+	Type       enums.TodoType      `json:"type" validate:"required,oneof=checklist text"`
+	StartAt    Nullable[time.Time] `json:"start_at,omitzero"`
+	DueAt      Nullable[time.Time] `json:"due_at,omitzero"`
+	Recurrence Nullable[string]    `json:"recurrence,omitzero"`
+	Priority   enums.TodoPriority  `json:"priority" validate:"omitempty,oneof=none low medium high urgent"`
 }
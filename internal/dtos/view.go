@@ -0,0 +1,14 @@
+package dtos
+
+// SaveViewDTO describes a saved view. Filters are keyed the way they are written inside filters[...] in a query
+// string, e.g. "priority" or "due_at[gt]".
+type SaveViewDTO struct {
+	Name      string            `json:"name" validate:"required,max=100"`
+	Filters   map[string]string `json:"filters" validate:"omitempty"`
+	SortBy    string            `json:"sort_by" validate:"omitempty,max=50"`
+	Order     Order             `json:"order" validate:"omitempty,oneof=asc desc"`
+	Search    string            `json:"q" validate:"omitempty,max=200"`
+	LabelIDs  []uint            `json:"label_ids" validate:"omitempty"`
+	IsDefault bool              `json:"is_default"`
+	UserID    uint              `json:"user_id" validate:"-"`
+}
@@ -0,0 +1,30 @@
+package dtos
+
+import "github.com/horlerdipo/todo-golang/internal/enums"
+
+type CreateWorkspaceDTO struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	OwnerID uint   `json:"owner_id" validate:"-"`
+}
+
+type UpdateWorkspaceDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddWorkspaceMemberDTO struct {
+	Email string              `json:"email" validate:"required,email"`
+	Role  enums.WorkspaceRole `json:"role" validate:"required,oneof=admin member"`
+}
+
+type UpdateWorkspaceMemberDTO struct {
+	Role enums.WorkspaceRole `json:"role" validate:"required,oneof=admin member"`
+}
+
+// WorkspaceMember is a member of a workspace, without the rest of their account details.
+type WorkspaceMember struct {
+	UserID    uint                `json:"user_id"`
+	FirstName string              `json:"first_name"`
+	LastName  string              `json:"last_name"`
+	Email     string              `json:"email"`
+	Role      enums.WorkspaceRole `json:"role"`
+}
@@ -0,0 +1,15 @@
+package enums
+
+type ShareRole string
+
+const (
+	// Owner is never stored on a share, it describes the access the todo's owner has
+	Owner  ShareRole = "owner"
+	Editor ShareRole = "editor"
+	Viewer ShareRole = "viewer"
+)
+
+// CanEdit reports whether the role may change a todo and its checklist.
+func (role ShareRole) CanEdit() bool {
+	return role == Owner || role == Editor
+}
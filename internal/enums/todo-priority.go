@@ -0,0 +1,20 @@
+package enums
+
+type TodoPriority string
+
+const (
+	PriorityNone   TodoPriority = "none"
+	PriorityLow    TodoPriority = "low"
+	PriorityMedium TodoPriority = "medium"
+	PriorityHigh   TodoPriority = "high"
+	PriorityUrgent TodoPriority = "urgent"
+)
+
+// TodoPriorities lists every priority from least to most pressing.
+var TodoPriorities = []TodoPriority{
+	PriorityNone,
+	PriorityLow,
+	PriorityMedium,
+	PriorityHigh,
+	PriorityUrgent,
+}
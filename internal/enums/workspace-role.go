@@ -0,0 +1,14 @@
+package enums
+
+type WorkspaceRole string
+
+const (
+	WorkspaceOwner  WorkspaceRole = "owner"
+	WorkspaceAdmin  WorkspaceRole = "admin"
+	WorkspaceMember WorkspaceRole = "member"
+)
+
+// CanManage reports whether the role may rename the workspace and manage its members.
+func (role WorkspaceRole) CanManage() bool {
+	return role == WorkspaceOwner || role == WorkspaceAdmin
+}
@@ -0,0 +1,11 @@
+package events
+
+type CommentAddedEvent struct {
+	CommentId uint
+	TodoId    uint
+	UserId    uint
+}
+
+func (event *CommentAddedEvent) Name() string {
+	return "comment.added"
+}
@@ -0,0 +1,11 @@
+package events
+
+type CommentDeletedEvent struct {
+	CommentId uint
+	TodoId    uint
+	UserId    uint
+}
+
+func (event *CommentDeletedEvent) Name() string {
+	return "comment.deleted"
+}
@@ -0,0 +1,11 @@
+package events
+
+type CommentUpdatedEvent struct {
+	CommentId uint
+	TodoId    uint
+	UserId    uint
+}
+
+func (event *CommentUpdatedEvent) Name() string {
+	return "comment.updated"
+}
@@ -0,0 +1,10 @@
+package events
+
+type LabelCreatedEvent struct {
+	LabelId uint
+	UserId  uint
+}
+
+func (event *LabelCreatedEvent) Name() string {
+	return "label.created"
+}
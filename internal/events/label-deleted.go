@@ -0,0 +1,10 @@
+package events
+
+type LabelDeletedEvent struct {
+	LabelId uint
+	UserId  uint
+}
+
+func (event *LabelDeletedEvent) Name() string {
+	return "label.deleted"
+}
@@ -0,0 +1,10 @@
+package events
+
+type LabelUpdatedEvent struct {
+	LabelId uint
+	UserId  uint
+}
+
+func (event *LabelUpdatedEvent) Name() string {
+	return "label.updated"
+}
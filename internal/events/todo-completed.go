@@ -0,0 +1,10 @@
+package events
+
+type TodoCompletedEvent struct {
+	TodoId uint
+	UserId uint
+}
+
+func (event *TodoCompletedEvent) Name() string {
+	return "todo.completed"
+}
@@ -0,0 +1,10 @@
+package events
+
+type TodoLabelsUpdatedEvent struct {
+	TodoId uint
+	UserId uint
+}
+
+func (event *TodoLabelsUpdatedEvent) Name() string {
+	return "todo.labels_updated"
+}
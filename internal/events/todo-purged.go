@@ -0,0 +1,11 @@
+package events
+
+// TodoPurgedEvent is published once todos have been permanently deleted, with the storage keys of the
+// attachment blobs that have to be removed along with them.
+type TodoPurgedEvent struct {
+	StorageKeys []string
+}
+
+func (event *TodoPurgedEvent) Name() string {
+	return "todo.purged"
+}
@@ -0,0 +1,11 @@
+package events
+
+type TodoRecurredEvent struct {
+	TodoId     uint
+	NextTodoId uint
+	UserId     uint
+}
+
+func (event *TodoRecurredEvent) Name() string {
+	return "todo.recurred"
+}
@@ -0,0 +1,10 @@
+package events
+
+type TodoReopenedEvent struct {
+	TodoId uint
+	UserId uint
+}
+
+func (event *TodoReopenedEvent) Name() string {
+	return "todo.reopened"
+}
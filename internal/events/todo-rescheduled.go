@@ -0,0 +1,13 @@
+package events
+
+import "time"
+
+type TodoRescheduledEvent struct {
+	TodoId uint
+	UserId uint
+	DueAt  *time.Time
+}
+
+func (event *TodoRescheduledEvent) Name() string {
+	return "todo.rescheduled"
+}
@@ -0,0 +1,11 @@
+package events
+
+type TodoSharedEvent struct {
+	TodoId      uint
+	UserId      uint
+	InvitedById uint
+}
+
+func (event *TodoSharedEvent) Name() string {
+	return "todo.shared"
+}
@@ -0,0 +1,11 @@
+package events
+
+// TodoUpdatedEvent is published whenever a todo or its checklist changes, UserId being whoever changed it.
+type TodoUpdatedEvent struct {
+	TodoId uint
+	UserId uint
+}
+
+func (event *TodoUpdatedEvent) Name() string {
+	return "todo.updated"
+}
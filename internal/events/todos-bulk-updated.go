@@ -0,0 +1,12 @@
+package events
+
+// TodosBulkUpdatedEvent is published once per bulk action with the ids of the todos it was applied to.
+type TodosBulkUpdatedEvent struct {
+	Action  string
+	TodoIds []uint
+	UserId  uint
+}
+
+func (event *TodosBulkUpdatedEvent) Name() string {
+	return "todo.bulk_updated"
+}
@@ -0,0 +1,11 @@
+package events
+
+type WorkspaceMemberAddedEvent struct {
+	WorkspaceId uint
+	UserId      uint
+	AddedById   uint
+}
+
+func (event *WorkspaceMemberAddedEvent) Name() string {
+	return "workspace.member_added"
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	ExportService *Service
+	ExportHandler *Handler
+}
+
+func NewContainer(db *gorm.DB, storage pkg.Storage) *Container {
+	exportService := NewService(
+		database.NewUserRepository(db),
+		database.NewTodoRepository(db),
+		database.NewLabelRepository(db),
+		database.NewCommentRepository(db),
+		database.NewReminderRepository(db),
+		database.NewAttachmentRepository(db),
+		database.NewViewRepository(db),
+		database.NewTemplateRepository(db),
+		database.NewWorkspaceRepository(db),
+		database.NewTodoShareRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		storage,
+	)
+
+	return &Container{
+		ExportService: exportService,
+		ExportHandler: NewHandler(exportService),
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.ExportHandler.RegisterRoutes(r)
+}
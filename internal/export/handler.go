@@ -0,0 +1,72 @@
+package export
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"log"
+	"net/http"
+)
+
+type Handler struct {
+	ExportService *Service
+}
+
+func NewHandler(exportService *Service) *Handler {
+	return &Handler{
+		ExportService: exportService,
+	}
+}
+
+// Export streams everything the user owns as a JSON document, or as a zip of CSV files when format=csv. Only the zip
+// holds the contents of attachments; the JSON document lists them without their files.
+// Once streaming has started the status can no longer change, so a failure part way through cuts the download short.
+func (handler *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		utils.RespondWithError(w, http.StatusBadRequest, "export format must be json or csv", nil)
+		return
+	}
+
+	user, err := handler.ExportService.FindProfile(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	flush := func() {}
+	if flusher, ok := w.(http.Flusher); ok {
+		flush = flusher.Flush
+	}
+
+	var out exporter
+	if format == "csv" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="todo-export.zip"`)
+		out = newCSVExporter(w, flush)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="todo-export.json"`)
+		out, err = newJSONExporter(w, flush)
+		if err != nil {
+			log.Println("Error while exporting user data", err)
+			return
+		}
+	}
+
+	if err = handler.ExportService.Export(r.Context(), user, out); err != nil {
+		log.Println("Error while exporting user data", err)
+	}
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/export", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.ExportService.TokenBlacklistRepository, handler.ExportService.SessionRepository))
+		r.Get("/", handler.Export)
+	})
+}
@@ -0,0 +1,232 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile is the part of a user's account that is exported. Passwords and reset tokens are left out.
+type Profile struct {
+	ID        uint      `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var profileColumns = []string{"id", "first_name", "last_name", "email", "created_at"}
+
+func profileRow(user Profile) []string {
+	return []string{formatId(user.ID), user.FirstName, user.LastName, user.Email, formatTime(user.CreatedAt)}
+}
+
+// exportedTodo is a todo as it is exported, with the time it was moved to the trash, if it has been.
+type exportedTodo struct {
+	database.Todo
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func newExportedTodos(todos []database.Todo) []exportedTodo {
+	exported := make([]exportedTodo, 0, len(todos))
+	for _, todo := range todos {
+		var deletedAt *time.Time
+		if todo.DeletedAt.Valid {
+			deletedAt = &todo.DeletedAt.Time
+		}
+		exported = append(exported, exportedTodo{Todo: todo, DeletedAt: deletedAt})
+	}
+	return exported
+}
+
+var todoColumns = []string{"id", "title", "content", "type", "priority", "workspace_id", "pinned", "position", "archived_at", "completed", "completed_at", "start_at", "due_at", "recurrence", "occurrence", "label_ids", "created_at", "updated_at", "deleted_at"}
+
+func todoRow(todo exportedTodo) []string {
+	labelIds := make([]string, 0, len(todo.Labels))
+	for _, label := range todo.Labels {
+		labelIds = append(labelIds, formatId(label.ID))
+	}
+
+	return []string{
+		formatId(todo.ID),
+		todo.Title,
+		formatString(todo.Content),
+		string(todo.Type),
+		string(todo.Priority),
+		formatOptionalId(todo.WorkspaceID),
+		strconv.FormatBool(todo.Pinned),
+		todo.Position,
+		formatOptionalTime(todo.ArchivedAt),
+		strconv.FormatBool(todo.Completed),
+		formatOptionalTime(todo.CompletedAt),
+		formatOptionalTime(todo.StartAt),
+		formatOptionalTime(todo.DueAt),
+		formatString(todo.Recurrence),
+		strconv.Itoa(todo.Occurrence),
+		strings.Join(labelIds, ";"),
+		formatTime(todo.CreatedAt),
+		formatTime(todo.UpdatedAt),
+		formatOptionalTime(todo.DeletedAt),
+	}
+}
+
+var checklistColumns = []string{"id", "todo_id", "parent_id", "description", "done", "position", "created_at", "updated_at"}
+
+func checklistRow(checklist database.Checklist) []string {
+	return []string{
+		formatId(checklist.ID),
+		formatId(checklist.TodoID),
+		formatOptionalId(checklist.ParentID),
+		checklist.Description,
+		strconv.FormatBool(checklist.Done),
+		checklist.Position,
+		formatTime(checklist.CreatedAt),
+		formatTime(checklist.UpdatedAt),
+	}
+}
+
+var completionColumns = []string{"id", "todo_id", "user_id", "completed_at"}
+
+func completionRow(completion database.TodoCompletion) []string {
+	return []string{formatId(completion.ID), formatId(completion.TodoID), formatId(completion.UserID), formatTime(completion.CompletedAt)}
+}
+
+var labelColumns = []string{"id", "name", "colour", "created_at"}
+
+func labelRow(label database.Label) []string {
+	return []string{formatId(label.ID), label.Name, label.Colour, formatTime(label.CreatedAt)}
+}
+
+var commentColumns = []string{"id", "todo_id", "body", "created_at", "updated_at"}
+
+func commentRow(comment database.Comment) []string {
+	return []string{formatId(comment.ID), formatId(comment.TodoID), comment.Body, formatTime(comment.CreatedAt), formatTime(comment.UpdatedAt)}
+}
+
+var reminderColumns = []string{"id", "todo_id", "remind_at", "offset_minutes", "sent_at"}
+
+func reminderRow(reminder database.Reminder) []string {
+	return []string{formatId(reminder.ID), formatId(reminder.TodoID), formatTime(reminder.RemindAt), formatOptionalInt(reminder.OffsetMinutes), formatOptionalTime(reminder.SentAt)}
+}
+
+var attachmentColumns = []string{"id", "todo_id", "file_name", "content_type", "size", "created_at", "file"}
+
+func attachmentRow(attachment database.Attachment) []string {
+	return []string{
+		formatId(attachment.ID),
+		formatId(attachment.TodoID),
+		attachment.FileName,
+		attachment.ContentType,
+		strconv.FormatInt(attachment.Size, 10),
+		formatTime(attachment.CreatedAt),
+		attachmentFile(attachment),
+	}
+}
+
+// attachmentFile is where the contents of an attachment are written in a CSV export.
+func attachmentFile(attachment database.Attachment) string {
+	return "attachments/" + formatId(attachment.ID) + "/" + path.Base(attachment.FileName)
+}
+
+var viewColumns = []string{"id", "name", "filters", "sort_by", "order", "q", "label_ids", "position", "is_default"}
+
+func viewRow(view database.View) []string {
+	return []string{
+		formatId(view.ID),
+		view.Name,
+		formatFilters(view.Filters),
+		view.SortBy,
+		string(view.Order),
+		view.Search,
+		formatIds(view.LabelIDs),
+		view.Position,
+		strconv.FormatBool(view.IsDefault),
+	}
+}
+
+var workspaceColumns = []string{"id", "name", "created_at"}
+
+func workspaceRow(workspace database.Workspace) []string {
+	return []string{formatId(workspace.ID), workspace.Name, formatTime(workspace.CreatedAt)}
+}
+
+var shareColumns = []string{"id", "todo_id", "user_id", "role", "invited_by_id", "created_at"}
+
+func shareRow(share database.TodoShare) []string {
+	return []string{formatId(share.ID), formatId(share.TodoID), formatId(share.UserID), string(share.Role), formatId(share.InvitedByID), formatTime(share.CreatedAt)}
+}
+
+var templateColumns = []string{"id", "title", "content", "type", "checklist", "priority", "label_ids", "start_offset_minutes", "due_offset_minutes"}
+
+func templateRow(template database.Template) []string {
+	return []string{
+		formatId(template.ID),
+		template.Title,
+		formatString(template.Content),
+		string(template.Type),
+		strings.Join(template.Checklist, "\n"),
+		string(template.Priority),
+		formatIds(template.LabelIDs),
+		formatOptionalInt(template.StartOffsetMinutes),
+		formatOptionalInt(template.DueOffsetMinutes),
+	}
+}
+
+func formatId(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func formatIds(ids []uint) string {
+	formatted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		formatted = append(formatted, formatId(id))
+	}
+	return strings.Join(formatted, ";")
+}
+
+func formatOptionalId(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return formatId(*id)
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// formatFilters writes a view's filters as the JSON object they are stored as.
+func formatFilters(filters map[string]string) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func formatTime(value time.Time) string {
+	return value.UTC().Format(time.RFC3339)
+}
+
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return formatTime(*value)
+}
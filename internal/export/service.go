@@ -0,0 +1,194 @@
+package export
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"log"
+)
+
+type Service struct {
+	UserRepository           database.UserRepository
+	TodoRepository           database.TodoRepository
+	LabelRepository          database.LabelRepository
+	CommentRepository        database.CommentRepository
+	ReminderRepository       database.ReminderRepository
+	AttachmentRepository     database.AttachmentRepository
+	ViewRepository           database.ViewRepository
+	TemplateRepository       database.TemplateRepository
+	WorkspaceRepository      database.WorkspaceRepository
+	TodoShareRepository      database.TodoShareRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	Storage                  pkg.Storage
+}
+
+func NewService(userRepository database.UserRepository, todoRepository database.TodoRepository, labelRepository database.LabelRepository, commentRepository database.CommentRepository, reminderRepository database.ReminderRepository, attachmentRepository database.AttachmentRepository, viewRepository database.ViewRepository, templateRepository database.TemplateRepository, workspaceRepository database.WorkspaceRepository, todoShareRepository database.TodoShareRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, storage pkg.Storage) *Service {
+	return &Service{
+		UserRepository:           userRepository,
+		TodoRepository:           todoRepository,
+		LabelRepository:          labelRepository,
+		CommentRepository:        commentRepository,
+		ReminderRepository:       reminderRepository,
+		AttachmentRepository:     attachmentRepository,
+		ViewRepository:           viewRepository,
+		TemplateRepository:       templateRepository,
+		WorkspaceRepository:      workspaceRepository,
+		TodoShareRepository:      todoShareRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		Storage:                  storage,
+	}
+}
+
+// writeFunc writes a single record of a section, both as it is encoded in JSON and as a CSV row.
+type writeFunc func(record interface{}, row []string) error
+
+// section is one kind of record in an export, written as a JSON array or a CSV file of its own.
+type section struct {
+	name    string
+	columns []string
+	stream  func(ctx context.Context, userId uint, write writeFunc) error
+}
+
+// records adapts a batch of records to write, formatting each of them as a CSV row with row.
+func records[T any](write writeFunc, row func(T) []string) func([]T) error {
+	return func(batch []T) error {
+		for _, record := range batch {
+			if err := write(record, row(record)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// sections lists everything a user owns in the order it is exported. The larger sections are streamed from the
+// database in batches while the ones a user only has a handful of are fetched whole. Trashed todos are exported
+// along with everything attached to them until they are purged.
+func (service *Service) sections() []section {
+	return []section{
+		{name: "todos", columns: todoColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.TodoRepository.StreamTodos(ctx, userId, func(todos []database.Todo) error {
+				return records(write, todoRow)(newExportedTodos(todos))
+			})
+		}},
+		{name: "checklist_items", columns: checklistColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.TodoRepository.StreamChecklists(ctx, userId, records(write, checklistRow))
+		}},
+		{name: "completions", columns: completionColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.TodoRepository.StreamCompletions(ctx, userId, records(write, completionRow))
+		}},
+		{name: "labels", columns: labelColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			labels, err := service.LabelRepository.FetchLabels(ctx, userId)
+			if err != nil {
+				return err
+			}
+			return records(write, labelRow)(labels)
+		}},
+		{name: "comments", columns: commentColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.CommentRepository.StreamUserComments(ctx, userId, records(write, commentRow))
+		}},
+		{name: "reminders", columns: reminderColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.ReminderRepository.StreamUserReminders(ctx, userId, records(write, reminderRow))
+		}},
+		{name: "attachments", columns: attachmentColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.AttachmentRepository.StreamUserAttachments(ctx, userId, records(write, attachmentRow))
+		}},
+		{name: "views", columns: viewColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			views, err := service.ViewRepository.FetchViews(ctx, userId)
+			if err != nil {
+				return err
+			}
+			return records(write, viewRow)(views)
+		}},
+		{name: "templates", columns: templateColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			templates, err := service.TemplateRepository.FetchTemplates(ctx, userId)
+			if err != nil {
+				return err
+			}
+			return records(write, templateRow)(templates)
+		}},
+		{name: "workspaces", columns: workspaceColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			workspaces, err := service.WorkspaceRepository.FetchOwnedWorkspaces(ctx, userId)
+			if err != nil {
+				return err
+			}
+			return records(write, workspaceRow)(workspaces)
+		}},
+		{name: "shares", columns: shareColumns, stream: func(ctx context.Context, userId uint, write writeFunc) error {
+			return service.TodoShareRepository.StreamOutgoingShares(ctx, userId, records(write, shareRow))
+		}},
+	}
+}
+
+// writeAttachmentFiles adds the contents of every attachment the user uploaded to the export. A blob that is missing
+// from storage is logged and left out rather than failing an export that is already being sent.
+func (service *Service) writeAttachmentFiles(ctx context.Context, userId uint, out exporter) error {
+	return service.AttachmentRepository.StreamUserAttachments(ctx, userId, func(attachments []database.Attachment) error {
+		for _, attachment := range attachments {
+			blob, err := service.Storage.Open(ctx, attachment.StorageKey)
+			if err != nil {
+				log.Println("Error while opening attachment", attachment.ID, err)
+				continue
+			}
+			err = out.WriteFile(attachmentFile(attachment), blob)
+			blob.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindProfile returns the part of the user's account that is exported, failing before anything has been written
+// when the user cannot be found.
+func (service *Service) FindProfile(ctx context.Context, userId uint) (*Profile, error) {
+	user, err := service.UserRepository.FindUserByID(ctx, userId)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("user does not exist")
+	}
+
+	return &Profile{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}, nil
+}
+
+// Export writes the user's profile followed by every section of their data and the contents of their attachments
+// to out.
+func (service *Service) Export(ctx context.Context, user *Profile, out exporter) error {
+	err := out.BeginSection("profile", profileColumns)
+	if err == nil {
+		err = out.WriteRecord(user, profileRow(*user))
+	}
+	if err == nil {
+		err = out.EndSection()
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, section := range service.sections() {
+		if err = out.BeginSection(section.name, section.columns); err != nil {
+			return err
+		}
+		if err = section.stream(ctx, user.ID, out.WriteRecord); err != nil {
+			return err
+		}
+		if err = out.EndSection(); err != nil {
+			return err
+		}
+	}
+
+	if err = service.writeAttachmentFiles(ctx, user.ID, out); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// exporter writes an export section by section, one record at a time, without holding the records it was given.
+type exporter interface {
+	BeginSection(name string, columns []string) error
+	WriteRecord(record interface{}, row []string) error
+	EndSection() error
+	// WriteFile adds a file, such as the contents of an attachment, to the export under name.
+	WriteFile(name string, contents io.Reader) error
+	Close() error
+}
+
+// jsonExporter writes the export as a single JSON object with an array of records for every section.
+type jsonExporter struct {
+	writer  *bufio.Writer
+	flusher func()
+	first   bool
+}
+
+func newJSONExporter(w io.Writer, flush func()) (*jsonExporter, error) {
+	exporter := &jsonExporter{writer: bufio.NewWriter(w), flusher: flush}
+	exportedAt, err := json.Marshal(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	_, err = exporter.writer.WriteString(`{"exported_at":` + string(exportedAt))
+	return exporter, err
+}
+
+func (exporter *jsonExporter) BeginSection(name string, _ []string) error {
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	exporter.first = true
+	_, err = exporter.writer.WriteString("," + string(key) + ":[")
+	return err
+}
+
+func (exporter *jsonExporter) WriteRecord(record interface{}, _ []string) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if !exporter.first {
+		if err = exporter.writer.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	exporter.first = false
+	_, err = exporter.writer.Write(encoded)
+	return err
+}
+
+// EndSection closes the section's array and sends what has been written so far on to the client.
+func (exporter *jsonExporter) EndSection() error {
+	if err := exporter.writer.WriteByte(']'); err != nil {
+		return err
+	}
+	if err := exporter.writer.Flush(); err != nil {
+		return err
+	}
+	exporter.flusher()
+	return nil
+}
+
+// WriteFile leaves files out of a JSON export, which only lists attachments. Their contents are in the CSV export.
+func (exporter *jsonExporter) WriteFile(_ string, _ io.Reader) error {
+	return nil
+}
+
+func (exporter *jsonExporter) Close() error {
+	if err := exporter.writer.WriteByte('}'); err != nil {
+		return err
+	}
+	return exporter.writer.Flush()
+}
+
+// csvExporter writes the export as a zip archive holding a CSV file for every section.
+type csvExporter struct {
+	archive *zip.Writer
+	writer  *csv.Writer
+	flusher func()
+}
+
+func newCSVExporter(w io.Writer, flush func()) *csvExporter {
+	return &csvExporter{archive: zip.NewWriter(w), flusher: flush}
+}
+
+func (exporter *csvExporter) BeginSection(name string, columns []string) error {
+	file, err := exporter.archive.CreateHeader(&zip.FileHeader{
+		Name:     name + ".csv",
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	exporter.writer = csv.NewWriter(file)
+	return exporter.writer.Write(columns)
+}
+
+func (exporter *csvExporter) WriteRecord(_ interface{}, row []string) error {
+	return exporter.writer.Write(row)
+}
+
+func (exporter *csvExporter) EndSection() error {
+	exporter.writer.Flush()
+	if err := exporter.writer.Error(); err != nil {
+		return err
+	}
+	if err := exporter.archive.Flush(); err != nil {
+		return err
+	}
+	exporter.flusher()
+	return nil
+}
+
+// WriteFile copies the file into the archive next to the CSV files.
+func (exporter *csvExporter) WriteFile(name string, contents io.Reader) error {
+	file, err := exporter.archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, contents); err != nil {
+		return err
+	}
+	if err = exporter.archive.Flush(); err != nil {
+		return err
+	}
+	exporter.flusher()
+	return nil
+}
+
+func (exporter *csvExporter) Close() error {
+	return exporter.archive.Close()
+}
@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	ImportService *Service
+	ImportHandler *Handler
+}
+
+func NewContainer(db *gorm.DB, bus pkg.EventBus) *Container {
+	importService := NewService(
+		database.NewTodoRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		database.NewWorkspaceRepository(db),
+		bus,
+	)
+
+	return &Container{
+		ImportService: importService,
+		ImportHandler: NewHandler(importService),
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.ImportHandler.RegisterRoutes(r)
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and form fields around the uploaded file.
+const multipartOverhead = 1 << 20
+
+// maxImportSize is the size, in bytes, of the largest file that can be imported.
+func maxImportSize() int {
+	return env.FetchInt("IMPORT_MAX_SIZE_KB", 5120) * 1024
+}
+
+type Handler struct {
+	ImportService *Service
+}
+
+func NewHandler(importService *Service) *Handler {
+	return &Handler{
+		ImportService: importService,
+	}
+}
+
+// Import imports the todos of the uploaded file, in the format named by the format form field. Setting dry_run
+// previews the todos that would be created without creating them.
+func (handler *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxImportSize())+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "file is too large", nil)
+			return
+		}
+		utils.RespondWithError(w, http.StatusUnprocessableEntity, "a file is required", nil)
+		return
+	}
+	defer file.Close()
+
+	dryRun := false
+	if value := r.FormValue("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnprocessableEntity, "dry_run must be true or false", nil)
+			return
+		}
+	}
+
+	result, err := handler.ImportService.Import(r.Context(), r.FormValue("format"), file, header.Filename, authDetails.UserId, authDetails.WorkspaceId, dryRun)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if dryRun {
+		utils.RespondWithSuccess(w, http.StatusOK, "Import previewed successfully", result)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Todos imported successfully", result)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/import", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.ImportService.TokenBlacklistRepository, handler.ImportService.SessionRepository))
+		r.Use(middlewares.WorkspaceMiddleware(handler.ImportService.WorkspaceRepository))
+		r.Post("/", handler.Import)
+	})
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"strconv"
+)
+
+// item is an imported checklist item along with the items nested under it.
+type item struct {
+	description string
+	done        bool
+	children    []*item
+}
+
+// outline collects the checklist items of an imported todo, nesting each item under the last one a level up.
+type outline struct {
+	items    []*item
+	open     []*item
+	maxDepth int
+}
+
+func newOutline() *outline {
+	return &outline{maxDepth: env.FetchInt("CHECKLIST_MAX_DEPTH", 3)}
+}
+
+// add adds an item at depth, 1 being a top level item.
+func (outline *outline) add(depth int, description string, done bool) error {
+	if depth > outline.maxDepth {
+		return errors.New("checklist items can only be nested " + strconv.Itoa(outline.maxDepth) + " levels deep")
+	}
+	if depth < 1 || depth > len(outline.open)+1 {
+		return errors.New("checklist item is nested under an item that does not exist")
+	}
+
+	added := &item{description: description, done: done}
+	if depth == 1 {
+		outline.items = append(outline.items, added)
+	} else {
+		parent := outline.open[depth-2]
+		parent.children = append(parent.children, added)
+	}
+	outline.open = append(outline.open[:depth-1], added)
+	return nil
+}
+
+func (outline *outline) empty() bool {
+	return len(outline.items) == 0
+}
+
+// checklists returns the items added so far as checklist items, sub-items nested in Children.
+func (outline *outline) checklists() []database.Checklist {
+	return checklistItems(outline.items)
+}
+
+func checklistItems(items []*item) []database.Checklist {
+	checklists := make([]database.Checklist, 0, len(items))
+	for _, item := range items {
+		checklists = append(checklists, database.Checklist{
+			Description: item.description,
+			Done:        item.done,
+			Children:    checklistItems(item.children),
+		})
+	}
+	return checklists
+}
@@ -0,0 +1,388 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parser reads the todos out of an uploaded file, reporting the rows it had to leave out. An error means the file
+// is not in the format it was uploaded as at all.
+type parser func(file io.Reader, fileName string) ([]database.Todo, []dtos.ImportRowError, error)
+
+var parsers = map[string]parser{
+	"todoist":  parseTodoist,
+	"trello":   parseTrello,
+	"markdown": parseMarkdown,
+}
+
+// withChecklist makes todo a checklist todo when items holds any items, and a text todo otherwise.
+func withChecklist(todo database.Todo, items *outline) database.Todo {
+	if items.empty() {
+		todo.Type = enums.Text
+		if todo.Content == nil {
+			todo.Content = new(string)
+		}
+		return todo
+	}
+
+	todo.Type = enums.Checklist
+	todo.Content = nil
+	todo.Checklists = items.checklists()
+	return todo
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// todoistPriorities maps the priorities of a Todoist export, where 1 is p1 and the most urgent, onto ours.
+var todoistPriorities = map[string]enums.TodoPriority{
+	"1": enums.PriorityUrgent,
+	"2": enums.PriorityHigh,
+	"3": enums.PriorityMedium,
+	"4": enums.PriorityNone,
+}
+
+var todoistDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTodoist reads a Todoist CSV export. Every task becomes a todo and the sub-tasks indented under it become its
+// checklist, nested by their indent. Tasks are completed when the export has a CHECKED column saying they were.
+// Sections and notes are skipped.
+func parseTodoist(file io.Reader, _ string) ([]database.Todo, []dtos.ImportRowError, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("file is not a Todoist CSV export")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["TYPE"]; !ok {
+		return nil, nil, errors.New("file is not a Todoist CSV export")
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return nil, nil, errors.New("file is not a Todoist CSV export")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var todos []database.Todo
+	var rowErrors []dtos.ImportRowError
+	var current *database.Todo
+	var items *outline
+	finish := func() {
+		if current != nil {
+			todos = append(todos, withChecklist(*current, items))
+		}
+		current = nil
+	}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "row could not be read"})
+			continue
+		}
+		if !strings.EqualFold(field(record, "TYPE"), "task") {
+			continue
+		}
+
+		content := field(record, "CONTENT")
+		if content == "" {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "task has no content"})
+			continue
+		}
+
+		indent := 1
+		if value := field(record, "INDENT"); value != "" {
+			indent, err = strconv.Atoi(value)
+			if err != nil || indent < 1 {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "indent must be a positive number"})
+				continue
+			}
+		}
+
+		checked, _ := strconv.ParseBool(field(record, "CHECKED"))
+		if indent > 1 {
+			if current == nil {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "sub-task has no parent task"})
+				continue
+			}
+			if err = items.add(indent-1, content, checked); err != nil {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: err.Error()})
+			}
+			continue
+		}
+
+		finish()
+		todo := database.Todo{
+			Title:     content,
+			Content:   optionalString(field(record, "DESCRIPTION")),
+			Priority:  enums.PriorityNone,
+			Completed: checked,
+		}
+		if value := field(record, "PRIORITY"); value != "" {
+			priority, ok := todoistPriorities[value]
+			if !ok {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "priority must be between 1 and 4"})
+				continue
+			}
+			todo.Priority = priority
+		}
+		if value := field(record, "DATE"); value != "" {
+			dueAt, err := parseTodoistDate(value, field(record, "TIMEZONE"))
+			if err != nil {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: err.Error()})
+				continue
+			}
+			todo.DueAt = dueAt
+		}
+
+		current = &todo
+		items = newOutline()
+	}
+	finish()
+
+	return todos, rowErrors, nil
+}
+
+// parseTodoistDate parses the due date of a Todoist task in its timezone, UTC when it has none. Recurring tasks
+// exported with a due date written out in words are not supported.
+func parseTodoistDate(value string, timezone string) (*time.Time, error) {
+	location := time.UTC
+	if timezone != "" {
+		if loaded, err := time.LoadLocation(timezone); err == nil {
+			location = loaded
+		}
+	}
+
+	for _, layout := range todoistDateLayouts {
+		if dueAt, err := time.ParseInLocation(layout, value, location); err == nil {
+			return &dueAt, nil
+		}
+	}
+	return nil, errors.New("due date " + strconv.Quote(value) + " is not a date")
+}
+
+type trelloBoard struct {
+	Lists      []trelloList      `json:"lists"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+type trelloList struct {
+	ID     string `json:"id"`
+	Closed bool   `json:"closed"`
+}
+
+type trelloCard struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Desc        string     `json:"desc"`
+	IDList      string     `json:"idList"`
+	Closed      bool       `json:"closed"`
+	Due         *time.Time `json:"due"`
+	DueComplete bool       `json:"dueComplete"`
+}
+
+type trelloChecklist struct {
+	IDCard     string            `json:"idCard"`
+	Name       string            `json:"name"`
+	Pos        float64           `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+type trelloCheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// parseTrello reads a Trello board exported as JSON. Every card becomes a todo, archived when the card or its list
+// was closed. A card's checklist becomes its checklist; when it has several, each one becomes a top level item with
+// its items nested under it.
+func parseTrello(file io.Reader, _ string) ([]database.Todo, []dtos.ImportRowError, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(file).Decode(&board); err != nil || board.Cards == nil {
+		return nil, nil, errors.New("file is not a Trello board export")
+	}
+
+	closedLists := make(map[string]bool, len(board.Lists))
+	for _, list := range board.Lists {
+		closedLists[list.ID] = list.Closed
+	}
+
+	cardChecklists := make(map[string][]trelloChecklist)
+	for _, checklist := range board.Checklists {
+		cardChecklists[checklist.IDCard] = append(cardChecklists[checklist.IDCard], checklist)
+	}
+
+	now := time.Now()
+	var todos []database.Todo
+	var rowErrors []dtos.ImportRowError
+	for i, card := range board.Cards {
+		title := strings.TrimSpace(card.Name)
+		if title == "" {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: i + 1, Error: "card has no name"})
+			continue
+		}
+
+		todo := database.Todo{
+			Title:     title,
+			Content:   optionalString(strings.TrimSpace(card.Desc)),
+			Priority:  enums.PriorityNone,
+			DueAt:     card.Due,
+			Completed: card.DueComplete,
+		}
+		if card.Closed || closedLists[card.IDList] {
+			todo.ArchivedAt = &now
+		}
+
+		checklists := cardChecklists[card.ID]
+		sort.SliceStable(checklists, func(a, b int) bool { return checklists[a].Pos < checklists[b].Pos })
+
+		items := newOutline()
+		depth := 1
+		if len(checklists) > 1 {
+			depth = 2
+		}
+		var err error
+		for _, checklist := range checklists {
+			if depth == 2 && err == nil {
+				err = items.add(1, strings.TrimSpace(checklist.Name), false)
+			}
+
+			checkItems := checklist.CheckItems
+			sort.SliceStable(checkItems, func(a, b int) bool { return checkItems[a].Pos < checkItems[b].Pos })
+			for _, checkItem := range checkItems {
+				if name := strings.TrimSpace(checkItem.Name); name != "" && err == nil {
+					err = items.add(depth, name, checkItem.State == "complete")
+				}
+			}
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+		todos = append(todos, withChecklist(todo, items))
+	}
+
+	return todos, rowErrors, nil
+}
+
+var (
+	markdownHeading   = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)(\s+#+)?\s*$`)
+	markdownCheckbox  = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*?)\s*$`)
+	markdownMalformed = regexp.MustCompile(`^\s*[-*+]\s+\[[^\]]*\]`)
+)
+
+// parseMarkdown reads a Markdown checklist. Every heading starts a new checklist todo titled after it, and the
+// "- [ ]" and "- [x]" lines that follow become its items, nested by their indentation. Items before the first
+// heading go into a todo named after the file, and any other line is skipped. A todo is completed when all of its
+// items are checked.
+func parseMarkdown(file io.Reader, fileName string) ([]database.Todo, []dtos.ImportRowError, error) {
+	title := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	if title == "" || title == "." {
+		title = "Imported checklist"
+	}
+
+	var todos []database.Todo
+	var rowErrors []dtos.ImportRowError
+	items := newOutline()
+	var indents []int
+	finish := func() {
+		if !items.empty() {
+			todo := withChecklist(database.Todo{Title: title, Priority: enums.PriorityNone}, items)
+			todo.Completed = allDone(todo.Checklists)
+			todos = append(todos, todo)
+		}
+	}
+
+	//a line can be as long as the largest file that can be imported
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportSize())
+	for row := 1; scanner.Scan(); row++ {
+		line := strings.ReplaceAll(scanner.Text(), "\t", "    ")
+
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			finish()
+			title = match[1]
+			items = newOutline()
+			indents = nil
+			continue
+		}
+
+		match := markdownCheckbox.FindStringSubmatch(line)
+		if match == nil {
+			if markdownMalformed.MatchString(line) {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "checklist items must start with - [ ] or - [x]"})
+			}
+			continue
+		}
+		if match[3] == "" {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: "checklist item has no text"})
+			continue
+		}
+
+		//an item is nested under the closest item above it that is indented less
+		indent := len(match[1])
+		for len(indents) > 0 && indent <= indents[len(indents)-1] {
+			indents = indents[:len(indents)-1]
+		}
+		if err := items.add(len(indents)+1, match[3], match[2] != " "); err != nil {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: row, Error: err.Error()})
+			continue
+		}
+		indents = append(indents, indent)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, errors.New("file is not a Markdown file")
+	}
+	finish()
+
+	return todos, rowErrors, nil
+}
+
+func allDone(items []database.Checklist) bool {
+	for _, item := range items {
+		if !item.Done || (len(item.Children) > 0 && !allDone(item.Children)) {
+			return false
+		}
+	}
+	return len(items) > 0
+}
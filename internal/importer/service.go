@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"io"
+	"log"
+	"time"
+)
+
+type Service struct {
+	TodoRepository           database.TodoRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	WorkspaceRepository      database.WorkspaceRepository
+	EventBus                 pkg.EventBus
+}
+
+func NewService(todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, workspaceRepository database.WorkspaceRepository, eventBus pkg.EventBus) *Service {
+	return &Service{
+		TodoRepository:           todoRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		WorkspaceRepository:      workspaceRepository,
+		EventBus:                 eventBus,
+	}
+}
+
+// ImportResult reports the todos an import created, or would create on a dry run, and the rows it left out.
+type ImportResult struct {
+	DryRun   bool                  `json:"dry_run"`
+	Imported int                   `json:"imported"`
+	Todos    []database.Todo       `json:"todos"`
+	Errors   []dtos.ImportRowError `json:"errors"`
+}
+
+// Import reads the todos out of a file in the given format and creates them in the user's list, or the workspace's
+// when workspaceId is set, all in one transaction. Rows that cannot be imported are reported rather than failing
+// the whole import, and a dry run reports what would be created without creating anything.
+func (service *Service) Import(ctx context.Context, format string, file io.Reader, fileName string, userId uint, workspaceId *uint, dryRun bool) (*ImportResult, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, errors.New("import format must be todoist, trello or markdown")
+	}
+
+	todos, rowErrors, err := parse(file, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	//whether a todo is completed comes from the file, even for checklists whose items are not all checked off
+	now := time.Now()
+	for i := range todos {
+		todos[i].UserID = userId
+		todos[i].WorkspaceID = workspaceId
+		todos[i].StartAt = pkg.UTC(todos[i].StartAt)
+		todos[i].DueAt = pkg.UTC(todos[i].DueAt)
+		if todos[i].Completed {
+			todos[i].CompletedAt = &now
+		}
+	}
+
+	result := &ImportResult{
+		DryRun: dryRun,
+		Todos:  todos,
+		Errors: rowErrors,
+	}
+	if result.Todos == nil {
+		result.Todos = []database.Todo{}
+	}
+	if result.Errors == nil {
+		result.Errors = []dtos.ImportRowError{}
+	}
+	if dryRun || len(todos) == 0 {
+		return result, nil
+	}
+
+	todoIds, err := service.TodoRepository.ImportTodos(ctx, todos)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("unable to import todos, please try again")
+	}
+
+	for i, todoId := range todoIds {
+		result.Todos[i].ID = todoId
+		service.EventBus.Publish(&events.TodoCreatedEvent{
+			TodoId: todoId,
+			UserId: userId,
+		})
+	}
+	result.Imported = len(todoIds)
+	return result, nil
+}
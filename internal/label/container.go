@@ -0,0 +1,43 @@
+package label
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	LabelService *Service
+	LabelHandler *Handler
+	SSEService   *sse.Service
+}
+
+func NewContainer(db *gorm.DB, bus pkg.EventBus, sseService *sse.Service) *Container {
+	labelService := NewService(
+		database.NewLabelRepository(db),
+		database.NewTodoRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		bus,
+	)
+
+	return &Container{
+		LabelService: labelService,
+		LabelHandler: NewHandler(labelService),
+		SSEService:   sseService,
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.LabelHandler.RegisterRoutes(r)
+}
+
+func (uc *Container) RegisterListeners(bus pkg.EventBus) {
+	listener := NewLabelEventListener(uc.SSEService)
+	bus.Subscribe("label.created", listener)
+	bus.Subscribe("label.updated", listener)
+	bus.Subscribe("label.deleted", listener)
+	bus.Subscribe("todo.labels_updated", listener)
+}
@@ -0,0 +1,162 @@
+package label
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	LabelService *Service
+}
+
+func NewHandler(labelService *Service) *Handler {
+	return &Handler{
+		LabelService: labelService,
+	}
+}
+
+func (handler *Handler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	jsonRequest, err := utils.JsonValidate[dtos.CreateLabelDTO](w, r)
+	if err != nil {
+		return
+	}
+
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.UserID = authDetails.UserId
+
+	labelId, err := handler.LabelService.CreateLabel(r.Context(), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusCreated, "Label created successfully", map[string]uint{"id": labelId})
+}
+
+func (handler *Handler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	labelId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "label not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.UpdateLabelDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.LabelService.UpdateLabel(r.Context(), uint(labelId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	labelId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "label not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.LabelService.DeleteLabel(r.Context(), uint(labelId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) FetchLabel(w http.ResponseWriter, r *http.Request) {
+	labelId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "label not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	label, err := handler.LabelService.FetchLabel(r.Context(), uint(labelId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Label fetched successfully", label)
+}
+
+func (handler *Handler) FetchLabels(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	labels, err := handler.LabelService.FetchLabels(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Labels fetched successfully", labels)
+}
+
+func (handler *Handler) AttachLabels(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.AttachLabelsDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.LabelService.AttachLabels(r.Context(), uint(todoId), jsonRequest.LabelIDs, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) DetachLabel(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	labelId, err := strconv.ParseUint(chi.URLParam(r, "labelId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "label not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.LabelService.DetachLabel(r.Context(), uint(todoId), uint(labelId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/labels", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.LabelService.TokenBlacklistRepository, handler.LabelService.SessionRepository))
+		r.Post("/", handler.CreateLabel)
+		r.Get("/", handler.FetchLabels)
+		r.Get("/{id}", handler.FetchLabel)
+		r.Patch("/{id}", handler.UpdateLabel)
+		r.Delete("/{id}", handler.DeleteLabel)
+	})
+
+	r.Route("/todos/{id}/labels", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.LabelService.TokenBlacklistRepository, handler.LabelService.SessionRepository))
+		r.Post("/", handler.AttachLabels)
+		r.Delete("/{labelId}", handler.DetachLabel)
+	})
+}
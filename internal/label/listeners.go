@@ -0,0 +1,32 @@
+package label
+
+import (
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+)
+
+// LabelEventListener forwards label changes to the owner's SSE clients.
+type LabelEventListener struct {
+	SSEService *sse.Service
+}
+
+func (listener *LabelEventListener) Handle(event pkg.Event) {
+	switch e := event.(type) {
+	case *events.LabelCreatedEvent:
+		listener.SSEService.SendMessage(e.UserId, dtos.SSEData{Event: dtos.LabelCreated, Data: e.LabelId})
+	case *events.LabelUpdatedEvent:
+		listener.SSEService.SendMessage(e.UserId, dtos.SSEData{Event: dtos.LabelUpdated, Data: e.LabelId})
+	case *events.LabelDeletedEvent:
+		listener.SSEService.SendMessage(e.UserId, dtos.SSEData{Event: dtos.LabelDeleted, Data: e.LabelId})
+	case *events.TodoLabelsUpdatedEvent:
+		listener.SSEService.SendMessage(e.UserId, dtos.SSEData{Event: dtos.TodoLabelsUpdated, Data: e.TodoId})
+	}
+}
+
+func NewLabelEventListener(sseService *sse.Service) *LabelEventListener {
+	return &LabelEventListener{
+		SSEService: sseService,
+	}
+}
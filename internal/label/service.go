@@ -0,0 +1,161 @@
+package label
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"log"
+	"strings"
+)
+
+type Service struct {
+	LabelRepository          database.LabelRepository
+	TodoRepository           database.TodoRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	EventBus                 pkg.EventBus
+}
+
+func NewService(labelRepository database.LabelRepository, todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, eventBus pkg.EventBus) *Service {
+	return &Service{
+		LabelRepository:          labelRepository,
+		TodoRepository:           todoRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		EventBus:                 eventBus,
+	}
+}
+
+func (service *Service) CreateLabel(ctx context.Context, createLabelDto *dtos.CreateLabelDTO) (uint, error) {
+	createLabelDto.Name = strings.TrimSpace(createLabelDto.Name)
+	_, err := service.LabelRepository.FindLabelByName(ctx, createLabelDto.Name, createLabelDto.UserID)
+	if err == nil {
+		return 0, errors.New("label already exists")
+	}
+
+	labelId, err := service.LabelRepository.CreateLabel(ctx, createLabelDto)
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New("unable to create label, please try again")
+	}
+
+	service.EventBus.Publish(&events.LabelCreatedEvent{
+		LabelId: labelId,
+		UserId:  createLabelDto.UserID,
+	})
+	return labelId, nil
+}
+
+func (service *Service) UpdateLabel(ctx context.Context, labelId uint, updateLabelDto *dtos.UpdateLabelDTO, userId uint) error {
+	_, err := service.LabelRepository.FindLabelByUserId(ctx, labelId, userId)
+	if err != nil {
+		return errors.New("label does not exist")
+	}
+
+	updateLabelDto.Name = strings.TrimSpace(updateLabelDto.Name)
+	existingLabel, err := service.LabelRepository.FindLabelByName(ctx, updateLabelDto.Name, userId)
+	if err == nil && existingLabel.ID != labelId {
+		return errors.New("label already exists")
+	}
+
+	err = service.LabelRepository.UpdateLabel(ctx, labelId, updateLabelDto)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.LabelUpdatedEvent{
+		LabelId: labelId,
+		UserId:  userId,
+	})
+	return nil
+}
+
+func (service *Service) DeleteLabel(ctx context.Context, labelId uint, userId uint) error {
+	_, err := service.LabelRepository.FindLabelByUserId(ctx, labelId, userId)
+	if err != nil {
+		return errors.New("label does not exist")
+	}
+
+	err = service.LabelRepository.DeleteLabel(ctx, labelId)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.LabelDeletedEvent{
+		LabelId: labelId,
+		UserId:  userId,
+	})
+	return nil
+}
+
+func (service *Service) FetchLabel(ctx context.Context, labelId uint, userId uint) (*database.Label, error) {
+	label, err := service.LabelRepository.FindLabelByUserId(ctx, labelId, userId)
+	if err != nil {
+		return nil, errors.New("label does not exist")
+	}
+	return label, nil
+}
+
+func (service *Service) FetchLabels(ctx context.Context, userId uint) ([]database.Label, error) {
+	return service.LabelRepository.FetchLabels(ctx, userId)
+}
+
+func (service *Service) AttachLabels(ctx context.Context, todoId uint, labelIds []uint, userId uint) error {
+	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo does not exist")
+	}
+
+	labelIds, err = service.ValidateUserLabels(ctx, labelIds, userId)
+	if err != nil {
+		return err
+	}
+
+	err = service.TodoRepository.AttachLabels(ctx, todoId, labelIds)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.TodoLabelsUpdatedEvent{
+		TodoId: todoId,
+		UserId: userId,
+	})
+	return nil
+}
+
+func (service *Service) DetachLabel(ctx context.Context, todoId uint, labelId uint, userId uint) error {
+	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo does not exist")
+	}
+
+	_, err = service.LabelRepository.FindLabelByUserId(ctx, labelId, userId)
+	if err != nil {
+		return errors.New("label does not exist")
+	}
+
+	err = service.TodoRepository.DetachLabel(ctx, todoId, labelId)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.TodoLabelsUpdatedEvent{
+		TodoId: todoId,
+		UserId: userId,
+	})
+	return nil
+}
+
+// ValidateUserLabels drops repeated ids from labelIds and makes sure every one of them is a label the user owns.
+func (service *Service) ValidateUserLabels(ctx context.Context, labelIds []uint, userId uint) ([]uint, error) {
+	labelIds = pkg.Unique(labelIds)
+	if len(labelIds) > 0 && service.LabelRepository.CountUserLabels(ctx, labelIds, userId) != int64(len(labelIds)) {
+		return nil, errors.New("label does not exist")
+	}
+	return labelIds, nil
+}
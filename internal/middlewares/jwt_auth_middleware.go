@@ -4,10 +4,13 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/horlerdipo/todo-golang/env"
 	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
 	"github.com/horlerdipo/todo-golang/utils"
 	"golang.org/x/net/context"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type contextKey string
@@ -16,11 +19,18 @@ type AuthDetails struct {
 	UserId            uint
 	JwtToken          string
 	JwtExpirationTime *jwt.NumericDate
+	//the refresh token family the access token was issued with, which identifies its session
+	TokenFamily string
+	//set by WorkspaceMiddleware when the request targets a workspace rather than the user's personal todos
+	WorkspaceId   *uint
+	WorkspaceRole enums.WorkspaceRole
 }
 
 const UserKey contextKey = "user"
 
-func JwtAuthMiddleware(tokenBlacklistRepository database.TokenBlacklistRepository) func(http.Handler) http.Handler {
+// JwtAuthMiddleware authenticates the request's access token. Every token must have been issued for a session, stops
+// working once that session is revoked, and records that the session was seen when used.
+func JwtAuthMiddleware(tokenBlacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			//log.Println("JwtAuthMiddleware hit:", r.URL.Path, r.Header.Get("Authorization"))
@@ -73,6 +83,16 @@ func JwtAuthMiddleware(tokenBlacklistRepository database.TokenBlacklistRepositor
 				return
 			}
 
+			//check that the token was issued for a session and that the session has not been revoked
+			tokenFamily, _ := claim["fid"].(string)
+			if tokenFamily == "" || sessionRepository.CheckSessionRevoked(r.Context(), tokenFamily) {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Unauthenticated", struct{}{})
+				return
+			}
+			if err = sessionRepository.MarkSessionSeen(r.Context(), tokenFamily, time.Now()); err != nil {
+				log.Println(err)
+			}
+
 			//add user details to Context
 			expTime, err := claim.GetExpirationTime()
 			if err != nil {
@@ -84,6 +104,7 @@ func JwtAuthMiddleware(tokenBlacklistRepository database.TokenBlacklistRepositor
 				UserId:            uint(claim["data"].(float64)),
 				JwtToken:          tokenString,
 				JwtExpirationTime: expTime,
+				TokenFamily:       tokenFamily,
 			})
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/utils"
+	"golang.org/x/net/context"
+	"net/http"
+	"strconv"
+)
+
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceMiddleware resolves the active workspace from the X-Workspace-ID header, or the workspace_id query
+// parameter for clients that cannot set headers, and records it on the AuthDetails set by JwtAuthMiddleware.
+// Requests without either keep working on the user's personal todos.
+func WorkspaceMiddleware(workspaceRepository database.WorkspaceRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(WorkspaceHeader)
+			if value == "" {
+				value = r.URL.Query().Get("workspace_id")
+			}
+
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			workspaceId, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+				return
+			}
+
+			authDetails := r.Context().Value(UserKey).(AuthDetails)
+			member, err := workspaceRepository.FindMember(r.Context(), uint(workspaceId), authDetails.UserId)
+			if err != nil {
+				utils.RespondWithError(w, http.StatusForbidden, "you are not a member of this workspace", nil)
+				return
+			}
+
+			authDetails.WorkspaceId = &member.WorkspaceID
+			authDetails.WorkspaceRole = member.Role
+			ctx := context.WithValue(r.Context(), UserKey, authDetails)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package reminder
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Container struct {
+	ReminderService *Service
+	ReminderHandler *Handler
+	SSEService      *sse.Service
+}
+
+func NewContainer(db *gorm.DB, sseService *sse.Service) *Container {
+	reminderService := NewService(
+		database.NewReminderRepository(db),
+		database.NewTodoRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		sseService,
+	)
+
+	return &Container{
+		ReminderService: reminderService,
+		ReminderHandler: NewHandler(reminderService),
+		SSEService:      sseService,
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.ReminderHandler.RegisterRoutes(r)
+}
+
+func (uc *Container) RegisterListeners(bus pkg.EventBus) {
+	bus.Subscribe("todo.rescheduled", NewTodoRescheduledListener(uc.ReminderService.ReminderRepository))
+	bus.Subscribe("todo.recurred", NewTodoRecurredListener(uc.ReminderService.ReminderRepository, uc.ReminderService.TodoRepository))
+}
+
+func (uc *Container) RegisterJobs(scheduler pkg.Scheduler) {
+	interval := time.Duration(env.FetchInt("REMINDER_POLL_INTERVAL_SECONDS", 30)) * time.Second
+	scheduler.Register("reminders.dispatch", interval, uc.ReminderService.DispatchDueReminders)
+}
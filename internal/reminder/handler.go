@@ -0,0 +1,93 @@
+package reminder
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	ReminderService *Service
+}
+
+func NewHandler(reminderService *Service) *Handler {
+	return &Handler{
+		ReminderService: reminderService,
+	}
+}
+
+func (handler *Handler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.CreateReminderDTO](w, r)
+	if err != nil {
+		return
+	}
+
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.TodoID = uint(todoId)
+	jsonRequest.UserID = authDetails.UserId
+
+	reminderId, err := handler.ReminderService.CreateReminder(r.Context(), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusCreated, "Reminder created successfully", map[string]uint{"id": reminderId})
+}
+
+func (handler *Handler) FetchReminders(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	reminders, err := handler.ReminderService.FetchReminders(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusOK, "Reminders fetched successfully", reminders)
+}
+
+func (handler *Handler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	reminderId, err := strconv.ParseUint(chi.URLParam(r, "reminderId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "reminder not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.ReminderService.DeleteReminder(r.Context(), uint(reminderId), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/todos/{id}/reminders", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.ReminderService.TokenBlacklistRepository, handler.ReminderService.SessionRepository))
+		r.Post("/", handler.CreateReminder)
+		r.Get("/", handler.FetchReminders)
+		r.Delete("/{reminderId}", handler.DeleteReminder)
+	})
+}
@@ -0,0 +1,52 @@
+package reminder
+
+import (
+	"context"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"log"
+)
+
+type TodoRescheduledListener struct {
+	ReminderRepository database.ReminderRepository
+}
+
+func (listener *TodoRescheduledListener) Handle(event pkg.Event) {
+	e := event.(*events.TodoRescheduledEvent)
+	err := listener.ReminderRepository.RescheduleOffsetReminders(context.Background(), e.TodoId, e.DueAt)
+	if err != nil {
+		log.Println("Error while rescheduling reminders", err)
+	}
+}
+
+func NewTodoRescheduledListener(reminderRepository database.ReminderRepository) *TodoRescheduledListener {
+	return &TodoRescheduledListener{
+		ReminderRepository: reminderRepository,
+	}
+}
+
+type TodoRecurredListener struct {
+	ReminderRepository database.ReminderRepository
+	TodoRepository     database.TodoRepository
+}
+
+func (listener *TodoRecurredListener) Handle(event pkg.Event) {
+	e := event.(*events.TodoRecurredEvent)
+	nextTodo, err := listener.TodoRepository.FindTodoByUserId(context.Background(), e.NextTodoId, e.UserId, false)
+	if err != nil || nextTodo.DueAt == nil {
+		return
+	}
+
+	err = listener.ReminderRepository.CopyOffsetReminders(context.Background(), e.TodoId, e.NextTodoId, *nextTodo.DueAt)
+	if err != nil {
+		log.Println("Error while copying reminders", err)
+	}
+}
+
+func NewTodoRecurredListener(reminderRepository database.ReminderRepository, todoRepository database.TodoRepository) *TodoRecurredListener {
+	return &TodoRecurredListener{
+		ReminderRepository: reminderRepository,
+		TodoRepository:     todoRepository,
+	}
+}
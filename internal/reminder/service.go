@@ -0,0 +1,145 @@
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"log"
+	"time"
+)
+
+// dispatchBatchSize caps how many reminders a single scheduler run delivers.
+const dispatchBatchSize = 100
+
+// maxDeliveryAttempts is how many times a reminder is tried before it is given up on.
+const maxDeliveryAttempts = 5
+
+// retryBackoff is how long a reminder waits after its first failed delivery, doubling with every failure after that.
+const retryBackoff = time.Minute
+
+type Service struct {
+	ReminderRepository       database.ReminderRepository
+	TodoRepository           database.TodoRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	SSEService               *sse.Service
+	SendEmail                func(emailConfig pkg.SendEmailConfig) error
+}
+
+func NewService(reminderRepository database.ReminderRepository, todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, sseService *sse.Service) *Service {
+	return &Service{
+		ReminderRepository:       reminderRepository,
+		TodoRepository:           todoRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		SSEService:               sseService,
+		SendEmail:                pkg.SendEmail,
+	}
+}
+
+func (service *Service) CreateReminder(ctx context.Context, createReminderDto *dtos.CreateReminderDTO) (uint, error) {
+	todo, err := service.TodoRepository.FindTodoByUserId(ctx, createReminderDto.TodoID, createReminderDto.UserID, false)
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New("todo does not exist")
+	}
+
+	if createReminderDto.OffsetMinutes != nil {
+		if todo.DueAt == nil {
+			return 0, errors.New("todo does not have a due date to be reminded before")
+		}
+		remindAt := todo.DueAt.Add(-time.Duration(*createReminderDto.OffsetMinutes) * time.Minute)
+		createReminderDto.RemindAt = &remindAt
+	}
+
+	if createReminderDto.RemindAt.Before(time.Now()) {
+		return 0, errors.New("reminder time has already passed")
+	}
+
+	reminderId, err := service.ReminderRepository.CreateReminder(ctx, createReminderDto)
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New("unable to create reminder, please try again")
+	}
+	return reminderId, nil
+}
+
+func (service *Service) FetchReminders(ctx context.Context, todoId uint, userId uint) ([]database.Reminder, error) {
+	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	return service.ReminderRepository.FetchTodoReminders(ctx, todoId)
+}
+
+func (service *Service) DeleteReminder(ctx context.Context, reminderId uint, todoId uint, userId uint) error {
+	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo does not exist")
+	}
+
+	return service.ReminderRepository.DeleteReminder(ctx, reminderId, todoId)
+}
+
+// DispatchDueReminders delivers every reminder whose time has come, including ones missed while the server was down.
+// A reminder is only marked sent once it has been emailed. One that fails is tried again after a growing backoff,
+// up to maxDeliveryAttempts times, so failing reminders do not hold up the ones behind them.
+func (service *Service) DispatchDueReminders(ctx context.Context) {
+	reminders, err := service.ReminderRepository.FetchDueReminders(ctx, time.Now(), maxDeliveryAttempts, dispatchBatchSize)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		if err = service.deliver(reminder); err != nil {
+			log.Println("Error while delivering reminder", reminder.ID, err)
+			retryAt := time.Now().Add(retryBackoff << reminder.Attempts)
+			if err = service.ReminderRepository.RecordFailedDelivery(ctx, reminder.ID, err.Error(), retryAt); err != nil {
+				log.Println(err)
+			}
+			continue
+		}
+
+		if _, err = service.ReminderRepository.MarkReminderSent(ctx, reminder.ID, time.Now()); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// deliver pushes the reminder to the user's connected clients and emails it. The push is only made on the first
+// attempt, whether or not the email goes out, so a mail outage does not silence the reminder in the app and clients
+// are not told about it again on every retry.
+func (service *Service) deliver(reminder database.Reminder) error {
+	if reminder.Attempts == 0 {
+		service.SSEService.SendMessage(reminder.UserID, dtos.SSEData{
+			Event: dtos.ReminderDue,
+			Data: map[string]interface{}{
+				"reminder_id": reminder.ID,
+				"todo_id":     reminder.TodoID,
+				"title":       reminder.Todo.Title,
+				"due_at":      reminder.Todo.DueAt,
+				"remind_at":   reminder.RemindAt,
+			},
+		})
+	}
+
+	content := fmt.Sprintf("Hello %s, this is a reminder for your todo \"%s\"", reminder.User.FirstName, reminder.Todo.Title)
+	if reminder.Todo.DueAt != nil {
+		content += " which is due by " + reminder.Todo.DueAt.Format("2006-01-02 15:04:05")
+	}
+
+	return service.SendEmail(pkg.SendEmailConfig{
+		Recipients:  []string{reminder.User.Email},
+		Subject:     "Reminder: " + reminder.Todo.Title,
+		Content:     content,
+		ContentType: "text/plain",
+	})
+}
@@ -0,0 +1,40 @@
+package share
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	ShareService *Service
+	ShareHandler *Handler
+	SSEService   *sse.Service
+}
+
+func NewContainer(db *gorm.DB, bus pkg.EventBus, sseService *sse.Service) *Container {
+	shareService := NewService(
+		database.NewTodoShareRepository(db),
+		database.NewTodoRepository(db),
+		database.NewUserRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		bus,
+	)
+
+	return &Container{
+		ShareService: shareService,
+		ShareHandler: NewHandler(shareService),
+		SSEService:   sseService,
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.ShareHandler.RegisterRoutes(r)
+}
+
+func (uc *Container) RegisterListeners(bus pkg.EventBus) {
+	bus.Subscribe("todo.shared", NewTodoSharedListener(uc.SSEService))
+}
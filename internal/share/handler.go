@@ -0,0 +1,88 @@
+package share
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	ShareService *Service
+}
+
+func NewHandler(shareService *Service) *Handler {
+	return &Handler{
+		ShareService: shareService,
+	}
+}
+
+func (handler *Handler) ShareTodo(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.ShareTodoDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.ShareService.ShareTodo(r.Context(), uint(todoId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Todo shared successfully", nil)
+}
+
+func (handler *Handler) FetchCollaborators(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	collaborators, err := handler.ShareService.FetchCollaborators(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Collaborators fetched successfully", collaborators)
+}
+
+func (handler *Handler) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	collaboratorId, err := strconv.ParseUint(chi.URLParam(r, "userId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "collaborator not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.ShareService.RemoveCollaborator(r.Context(), uint(todoId), uint(collaboratorId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/todos/{id}/collaborators", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.ShareService.TokenBlacklistRepository, handler.ShareService.SessionRepository))
+		r.Post("/", handler.ShareTodo)
+		r.Get("/", handler.FetchCollaborators)
+		r.Delete("/{userId}", handler.RemoveCollaborator)
+	})
+}
@@ -0,0 +1,31 @@
+package share
+
+import (
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+)
+
+// TodoSharedListener tells a user's clients that a todo has been shared with them.
+type TodoSharedListener struct {
+	SSEService *sse.Service
+}
+
+func (listener *TodoSharedListener) Handle(event pkg.Event) {
+	e := event.(*events.TodoSharedEvent)
+	message := dtos.SSEData{
+		Event: dtos.TodoShared,
+		Data: map[string]uint{
+			"todo_id":    e.TodoId,
+			"invited_by": e.InvitedById,
+		},
+	}
+	listener.SSEService.SendMessage(e.UserId, message)
+}
+
+func NewTodoSharedListener(sseService *sse.Service) *TodoSharedListener {
+	return &TodoSharedListener{
+		SSEService: sseService,
+	}
+}
@@ -0,0 +1,107 @@
+package share
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"log"
+	"strings"
+)
+
+type Service struct {
+	TodoShareRepository      database.TodoShareRepository
+	TodoRepository           database.TodoRepository
+	UserRepository           database.UserRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	EventBus                 pkg.EventBus
+}
+
+func NewService(todoShareRepository database.TodoShareRepository, todoRepository database.TodoRepository, userRepository database.UserRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, eventBus pkg.EventBus) *Service {
+	return &Service{
+		TodoShareRepository:      todoShareRepository,
+		TodoRepository:           todoRepository,
+		UserRepository:           userRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		EventBus:                 eventBus,
+	}
+}
+
+// ShareTodo invites the user with the given email to a todo, or changes their role when they are already a collaborator.
+func (service *Service) ShareTodo(ctx context.Context, todoId uint, shareTodoDto *dtos.ShareTodoDTO, userId uint) error {
+	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	if err != nil {
+		return errors.New("todo does not exist")
+	}
+
+	collaborator, err := service.UserRepository.FindUserByEmail(ctx, strings.TrimSpace(shareTodoDto.Email))
+	if err != nil {
+		return errors.New("user does not exist")
+	}
+
+	if collaborator.ID == userId {
+		return errors.New("you cannot share a todo with yourself")
+	}
+
+	err = service.TodoShareRepository.ShareTodo(ctx, todoId, collaborator.ID, shareTodoDto.Role, userId)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.TodoSharedEvent{
+		TodoId:      todoId,
+		UserId:      collaborator.ID,
+		InvitedById: userId,
+	})
+	return nil
+}
+
+func (service *Service) FetchCollaborators(ctx context.Context, todoId uint, userId uint) ([]dtos.Collaborator, error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	shares, err := service.TodoShareRepository.FetchShares(ctx, todoId)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators := make([]dtos.Collaborator, 0, len(shares))
+	for _, share := range shares {
+		collaborators = append(collaborators, dtos.Collaborator{
+			UserID:    share.UserID,
+			FirstName: share.User.FirstName,
+			LastName:  share.User.LastName,
+			Email:     share.User.Email,
+			Role:      share.Role,
+		})
+	}
+	return collaborators, nil
+}
+
+// RemoveCollaborator revokes a collaborator's access. The owner can remove anyone, collaborators can only remove themselves.
+func (service *Service) RemoveCollaborator(ctx context.Context, todoId uint, collaboratorId uint, userId uint) error {
+	_, role, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo does not exist")
+	}
+
+	if role != enums.Owner && collaboratorId != userId {
+		return errors.New("only the owner can remove other collaborators")
+	}
+
+	_, err = service.TodoShareRepository.FindShare(ctx, todoId, collaboratorId)
+	if err != nil {
+		return errors.New("collaborator not found")
+	}
+
+	return service.TodoShareRepository.DeleteShare(ctx, todoId, collaboratorId)
+}
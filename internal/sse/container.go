@@ -13,7 +13,7 @@ type Container struct {
 
 func NewContainer(db *gorm.DB) *Container {
 	tokenBlacklistRepository := database.NewTokenBlacklistRepository(db)
-	service := NewService(tokenBlacklistRepository)
+	service := NewService(tokenBlacklistRepository, database.NewSessionRepository(db))
 
 	return &Container{
 		SSEHandler: NewHandler(service),
@@ -16,7 +16,7 @@ type Handler struct {
 
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
-		r.Use(middlewares.JwtAuthMiddleware(h.SSEService.TokenBlacklistRepository))
+		r.Use(middlewares.JwtAuthMiddleware(h.SSEService.TokenBlacklistRepository, h.SSEService.SessionRepository))
 		r.Get("/sse", h.registerSSE)
 	})
 }
@@ -46,13 +46,15 @@ func (h *Handler) registerSSE(w http.ResponseWriter, r *http.Request) {
 	clientGone := r.Context().Done()
 	keepAlive := time.NewTicker(time.Second * 10)
 	defer keepAlive.Stop()
-	userId := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails).UserId
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	userId := authDetails.UserId
 
 	client := &ConnectedClient{
-		Data:   make(chan dtos.SSEData),
-		Writer: w,
-		Done:   clientGone,
-		Quit:   make(chan struct{}),
+		Data:    make(chan dtos.SSEData),
+		Writer:  w,
+		Done:    clientGone,
+		Quit:    make(chan struct{}),
+		Session: authDetails.TokenFamily,
 	}
 	fmt.Println("Registering SSE client", client)
 	h.SSEService.AddClient(userId, client)
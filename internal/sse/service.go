@@ -13,19 +13,23 @@ type ConnectedClient struct {
 	Writer http.ResponseWriter
 	Quit   chan struct{}
 	Done   <-chan struct{}
+	//the refresh token family of the session the client connected with, so a single device can be disconnected
+	Session string
 }
 
 type Service struct {
 	mutex                    sync.RWMutex
 	ConnectedClients         map[uint][]*ConnectedClient
 	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
 }
 
-func NewService(tokenBlacklistRepository database.TokenBlacklistRepository) *Service {
+func NewService(tokenBlacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository) *Service {
 	connectedClient := make(map[uint][]*ConnectedClient)
 	return &Service{
 		ConnectedClients:         connectedClient,
 		TokenBlacklistRepository: tokenBlacklistRepository,
+		SessionRepository:        sessionRepository,
 	}
 }
 
@@ -38,22 +42,39 @@ func (service *Service) AddClient(userId uint, client *ConnectedClient) {
 }
 
 func (service *Service) RemoveClients(userId uint) {
+	//held throughout so that RemoveSessionClients cannot close the same client a second time
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
 	fmt.Printf("Client before removal %v", service.ConnectedClients)
-	service.mutex.RLock()
-	clients := service.ConnectedClients[userId]
-	service.mutex.RUnlock()
 
-	for _, client := range clients {
+	for _, client := range service.ConnectedClients[userId] {
 		close(client.Quit)
 	}
+	delete(service.ConnectedClients, userId)
+	fmt.Printf("Client removed %v", service.ConnectedClients)
 
-	if _, ok := service.ConnectedClients[userId]; ok {
-		service.mutex.RLock()
-		delete(service.ConnectedClients, userId)
-		service.mutex.RUnlock()
+}
+
+// RemoveSessionClients disconnects the user's clients that connected with the given session, leaving the ones on
+// their other devices connected.
+func (service *Service) RemoveSessionClients(userId uint, session string) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	remaining := make([]*ConnectedClient, 0, len(service.ConnectedClients[userId]))
+	for _, client := range service.ConnectedClients[userId] {
+		if client.Session == session {
+			close(client.Quit)
+			continue
+		}
+		remaining = append(remaining, client)
 	}
-	fmt.Printf("Client removed %v", service.ConnectedClients)
 
+	if len(remaining) == 0 {
+		delete(service.ConnectedClients, userId)
+		return
+	}
+	service.ConnectedClients[userId] = remaining
 }
 
 func (service *Service) SendMessage(userId uint, message dtos.SSEData) {
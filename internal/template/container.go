@@ -0,0 +1,36 @@
+package template
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/label"
+	"github.com/horlerdipo/todo-golang/internal/todo"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	TemplateService *Service
+	TemplateHandler *Handler
+}
+
+// NewContainer wires templates up to the todo service, which creates the todos they are instantiated into, and the
+// label service, which checks the labels they carry.
+func NewContainer(db *gorm.DB, todoService *todo.Service, labelService *label.Service) *Container {
+	templateService := NewService(
+		database.NewTemplateRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		database.NewWorkspaceRepository(db),
+		todoService,
+		labelService,
+	)
+
+	return &Container{
+		TemplateService: templateService,
+		TemplateHandler: NewHandler(templateService),
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.TemplateHandler.RegisterRoutes(r)
+}
@@ -0,0 +1,133 @@
+package template
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	TemplateService *Service
+}
+
+func NewHandler(templateService *Service) *Handler {
+	return &Handler{
+		TemplateService: templateService,
+	}
+}
+
+func (handler *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	jsonRequest, err := utils.JsonValidate[dtos.SaveTemplateDTO](w, r)
+	if err != nil {
+		return
+	}
+
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.UserID = authDetails.UserId
+
+	templateId, err := handler.TemplateService.CreateTemplate(r.Context(), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusCreated, "Template created successfully", map[string]uint{"id": templateId})
+}
+
+func (handler *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "template not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.SaveTemplateDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.UserID = authDetails.UserId
+
+	err = handler.TemplateService.UpdateTemplate(r.Context(), uint(templateId), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "template not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.TemplateService.DeleteTemplate(r.Context(), uint(templateId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) FetchTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "template not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	template, err := handler.TemplateService.FetchTemplate(r.Context(), uint(templateId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Template fetched successfully", template)
+}
+
+func (handler *Handler) FetchTemplates(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	templates, err := handler.TemplateService.FetchTemplates(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Templates fetched successfully", templates)
+}
+
+// InstantiateTemplate creates a todo from a template, in the current workspace when one is selected.
+func (handler *Handler) InstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "template not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	todoId, err := handler.TemplateService.InstantiateTemplate(r.Context(), uint(templateId), authDetails.UserId, authDetails.WorkspaceId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Todo created from template successfully", map[string]uint{"id": todoId})
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/templates", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.TemplateService.TokenBlacklistRepository, handler.TemplateService.SessionRepository))
+		r.Use(middlewares.WorkspaceMiddleware(handler.TemplateService.WorkspaceRepository))
+		r.Post("/", handler.CreateTemplate)
+		r.Get("/", handler.FetchTemplates)
+		r.Get("/{id}", handler.FetchTemplate)
+		r.Put("/{id}", handler.UpdateTemplate)
+		r.Delete("/{id}", handler.DeleteTemplate)
+		r.Post("/{id}/instantiate", handler.InstantiateTemplate)
+	})
+}
@@ -0,0 +1,162 @@
+package template
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/internal/label"
+	"github.com/horlerdipo/todo-golang/internal/todo"
+	"golang.org/x/net/context"
+	"strings"
+	"time"
+)
+
+type Service struct {
+	TemplateRepository       database.TemplateRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	WorkspaceRepository      database.WorkspaceRepository
+	TodoService              *todo.Service
+	LabelService             *label.Service
+}
+
+func NewService(templateRepository database.TemplateRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, workspaceRepository database.WorkspaceRepository, todoService *todo.Service, labelService *label.Service) *Service {
+	return &Service{
+		TemplateRepository:       templateRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		WorkspaceRepository:      workspaceRepository,
+		TodoService:              todoService,
+		LabelService:             labelService,
+	}
+}
+
+func (service *Service) CreateTemplate(ctx context.Context, saveTemplateDto *dtos.SaveTemplateDTO) (uint, error) {
+	if err := service.normaliseTemplate(ctx, saveTemplateDto); err != nil {
+		return 0, err
+	}
+	return service.TemplateRepository.CreateTemplate(ctx, saveTemplateDto)
+}
+
+func (service *Service) UpdateTemplate(ctx context.Context, templateId uint, saveTemplateDto *dtos.SaveTemplateDTO) error {
+	_, err := service.TemplateRepository.FindTemplateByUserId(ctx, templateId, saveTemplateDto.UserID)
+	if err != nil {
+		return errors.New("template does not exist")
+	}
+
+	if err = service.normaliseTemplate(ctx, saveTemplateDto); err != nil {
+		return err
+	}
+	return service.TemplateRepository.UpdateTemplate(ctx, templateId, saveTemplateDto)
+}
+
+func (service *Service) DeleteTemplate(ctx context.Context, templateId uint, userId uint) error {
+	_, err := service.TemplateRepository.FindTemplateByUserId(ctx, templateId, userId)
+	if err != nil {
+		return errors.New("template does not exist")
+	}
+	return service.TemplateRepository.DeleteTemplate(ctx, templateId)
+}
+
+func (service *Service) FetchTemplate(ctx context.Context, templateId uint, userId uint) (*database.Template, error) {
+	template, err := service.TemplateRepository.FindTemplateByUserId(ctx, templateId, userId)
+	if err != nil {
+		return nil, errors.New("template does not exist")
+	}
+	return template, nil
+}
+
+func (service *Service) FetchTemplates(ctx context.Context, userId uint) ([]database.Template, error) {
+	return service.TemplateRepository.FetchTemplates(ctx, userId)
+}
+
+// InstantiateTemplate creates a todo from a template through the todo service, so it is created exactly like one
+// posted to /todos. Its dates are counted from now and labels deleted since the template was saved are left out.
+func (service *Service) InstantiateTemplate(ctx context.Context, templateId uint, userId uint, workspaceId *uint) (uint, error) {
+	template, err := service.TemplateRepository.FindTemplateByUserId(ctx, templateId, userId)
+	if err != nil {
+		return 0, errors.New("template does not exist")
+	}
+
+	labelIds, err := service.existingLabels(ctx, template.LabelIDs, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	return service.TodoService.CreateTodo(ctx, &dtos.CreateTodoDTO{
+		Title:       template.Title,
+		Content:     template.Content,
+		Type:        template.Type,
+		UserID:      userId,
+		WorkspaceID: workspaceId,
+		Checklist:   template.Checklist,
+		StartAt:     offsetFrom(now, template.StartOffsetMinutes),
+		DueAt:       offsetFrom(now, template.DueOffsetMinutes),
+		Priority:    template.Priority,
+		LabelIDs:    labelIds,
+	})
+}
+
+// existingLabels returns the labels out of labelIds that the user still has.
+func (service *Service) existingLabels(ctx context.Context, labelIds []uint, userId uint) ([]uint, error) {
+	if len(labelIds) == 0 {
+		return nil, nil
+	}
+
+	labels, err := service.LabelService.FetchLabels(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[uint]bool, len(labels))
+	for _, label := range labels {
+		owned[label.ID] = true
+	}
+
+	existing := make([]uint, 0, len(labelIds))
+	for _, labelId := range labelIds {
+		if owned[labelId] {
+			existing = append(existing, labelId)
+		}
+	}
+	return existing, nil
+}
+
+// normaliseTemplate tidies a template before it is saved, checking its labels belong to the user saving it and
+// that it does not start after it is due.
+func (service *Service) normaliseTemplate(ctx context.Context, saveTemplateDto *dtos.SaveTemplateDTO) error {
+	saveTemplateDto.Title = strings.TrimSpace(saveTemplateDto.Title)
+
+	if saveTemplateDto.Type == enums.Checklist {
+		saveTemplateDto.Content = nil
+	} else {
+		saveTemplateDto.Checklist = nil
+	}
+
+	if saveTemplateDto.Priority == "" {
+		saveTemplateDto.Priority = enums.PriorityNone
+	}
+
+	start, due := saveTemplateDto.StartOffsetMinutes, saveTemplateDto.DueOffsetMinutes
+	if start != nil && due != nil && *start > *due {
+		return errors.New("start offset cannot be after due offset")
+	}
+
+	labelIds, err := service.LabelService.ValidateUserLabels(ctx, saveTemplateDto.LabelIDs, saveTemplateDto.UserID)
+	if err != nil {
+		return err
+	}
+	saveTemplateDto.LabelIDs = labelIds
+	return nil
+}
+
+// offsetFrom returns the time the given number of minutes after from, or nil when there is no offset.
+func offsetFrom(from time.Time, minutes *int) *time.Time {
+	if minutes == nil {
+		return nil
+	}
+	at := from.Add(time.Duration(*minutes) * time.Minute)
+	return &at
+}
@@ -2,31 +2,39 @@ package todo
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/env"
 	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/label"
 	"github.com/horlerdipo/todo-golang/internal/sse"
 	"github.com/horlerdipo/todo-golang/pkg"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Container struct {
-	TodoService *Service
-	SSEService  *sse.Service
-	TodoHandler *Handler
+	TodoService         *Service
+	SSEService          *sse.Service
+	TodoHandler         *Handler
+	TodoShareRepository database.TodoShareRepository
 }
 
-func NewContainer(db *gorm.DB, bus pkg.EventBus, sseService *sse.Service) *Container {
+func NewContainer(db *gorm.DB, bus pkg.EventBus, sseService *sse.Service, labelService *label.Service) *Container {
 	todoService := NewService(
 		database.NewTodoRepository(db),
 		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		database.NewWorkspaceRepository(db),
+		labelService,
 		bus,
 	)
 
 	todoHandler := NewHandler(todoService)
 
 	return &Container{
-		TodoService: todoService,
-		TodoHandler: todoHandler,
-		SSEService:  sseService,
+		TodoService:         todoService,
+		TodoHandler:         todoHandler,
+		SSEService:          sseService,
+		TodoShareRepository: database.NewTodoShareRepository(db),
 	}
 }
 
@@ -36,4 +44,16 @@ func (uc *Container) RegisterRoutes(r chi.Router) {
 
 func (uc *Container) RegisterListeners(bus pkg.EventBus) {
 	bus.Subscribe("todo.created", NewTodoCreatedListener(uc.TodoService.TodoRepository, uc.SSEService))
+	bus.Subscribe("todo.recurred", NewTodoRecurredListener(uc.TodoShareRepository, uc.SSEService))
+	bus.Subscribe("todo.updated", NewTodoUpdatedListener(uc.TodoShareRepository, uc.SSEService))
+	bus.Subscribe("todo.bulk_updated", NewTodosBulkUpdatedListener(uc.TodoShareRepository, uc.SSEService))
+
+	completionListener := NewTodoCompletionListener(uc.TodoShareRepository, uc.SSEService)
+	bus.Subscribe("todo.completed", completionListener)
+	bus.Subscribe("todo.reopened", completionListener)
+}
+
+func (uc *Container) RegisterJobs(scheduler pkg.Scheduler) {
+	interval := time.Duration(env.FetchInt("TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute
+	scheduler.Register("todos.purge_trash", interval, uc.TodoService.PurgeTrash)
 }
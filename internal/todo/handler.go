@@ -6,8 +6,8 @@ import (
 	"github.com/horlerdipo/todo-golang/internal/middlewares"
 	"github.com/horlerdipo/todo-golang/utils"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 )
 
 type Handler struct {
@@ -28,6 +28,7 @@ func (handler *Handler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 
 	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
 	jsonResponse.UserID = authDetails.UserId
+	jsonResponse.WorkspaceID = authDetails.WorkspaceId
 
 	_, err = handler.TodoService.CreateTodo(r.Context(), &jsonResponse)
 	if err != nil {
@@ -113,6 +114,108 @@ func (handler *Handler) UnPinTodo(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (handler *Handler) ArchiveTodo(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	err = handler.TodoService.ArchiveTodo(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (handler *Handler) UnArchiveTodo(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	err = handler.TodoService.UnArchiveTodo(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (handler *Handler) CompleteTodo(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	err = handler.TodoService.CompleteTodo(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (handler *Handler) ReopenTodo(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	err = handler.TodoService.ReopenTodo(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (handler *Handler) FetchCompletions(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	completions, err := handler.TodoService.FetchCompletions(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusOK, "Todo completions fetched successfully", completions)
+}
+
+func (handler *Handler) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.ReorderDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.TodoService.MoveTodo(r.Context(), uint(todoId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (handler *Handler) AddChecklistItem(w http.ResponseWriter, r *http.Request) {
 	todoId := chi.URLParam(r, "id")
 	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
@@ -128,7 +231,7 @@ func (handler *Handler) AddChecklistItem(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = handler.TodoService.AddChecklistItem(r.Context(), uint(todoIdInt), checklistItem.Item, authDetails.UserId)
+	err = handler.TodoService.AddChecklistItem(r.Context(), uint(todoIdInt), checklistItem.Item, checklistItem.ParentID, authDetails.UserId)
 	if err != nil {
 		utils.RespondWithError(w, 400, err.Error(), nil)
 		return
@@ -215,42 +318,118 @@ func (handler *Handler) UpdateChecklistItemStatus(w http.ResponseWriter, r *http
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (handler *Handler) FetchTodos(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
+func (handler *Handler) MoveChecklistItem(w http.ResponseWriter, r *http.Request) {
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, 400, "todo not found", nil)
+		return
+	}
 
-	page, _ := strconv.Atoi(query.Get("page"))
-	perPage, _ := strconv.Atoi(query.Get("per_page"))
-	sortBy := query.Get("sort_by")
-	order := query.Get("order")
+	checklistItem, err := strconv.ParseUint(chi.URLParam(r, "itemId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, 400, "checklist not found", nil)
+		return
+	}
 
-	filters := make(map[string]string)
-	for key, values := range r.URL.Query() {
-		if strings.HasPrefix(key, "filters[") {
-			field := strings.TrimSuffix(strings.TrimPrefix(key, "filters["), "]")
-			filters[field] = values[0]
-		}
+	jsonRequest, err := utils.JsonValidate[dtos.ReorderDTO](w, r)
+	if err != nil {
+		return
 	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
 
+	err = handler.TodoService.MoveChecklistItem(r.Context(), uint(checklistItem), uint(todoId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BulkUpdateTodos applies one action to several todos, responding with how it went for each of them.
+func (handler *Handler) BulkUpdateTodos(w http.ResponseWriter, r *http.Request) {
+	jsonRequest, err := utils.JsonValidate[dtos.BulkTodoDTO](w, r)
+	if err != nil {
+		return
+	}
 	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
 
-	paginationOptions := dtos.PaginationOptions{
-		Page:    page,
-		PerPage: perPage,
-		SortBy:  sortBy,
-		Order:   dtos.Order(order),
-		Filters: filters,
+	results, err := handler.TodoService.BulkUpdateTodos(r.Context(), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
 	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Bulk action completed", results)
+}
+
+func (handler *Handler) FetchTodos(w http.ResponseWriter, r *http.Request) {
+	handler.ListTodos(w, r, r.URL.Query())
+}
+
+// ListTodos responds with the todos matching query, read the way FetchTodos reads its query string. Saved views
+// list their todos through it with their own query.
+func (handler *Handler) ListTodos(w http.ResponseWriter, r *http.Request, query url.Values) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
 
-	todos, err := handler.TodoService.FetchTodos(r.Context(), paginationOptions, authDetails.UserId)
+	todos, err := handler.TodoService.FetchTodos(r.Context(), dtos.PaginationOptionsFromQuery(query), authDetails.UserId, authDetails.WorkspaceId)
 	if err != nil {
 		utils.RespondWithError(w, 400, err.Error(), nil)
 		return
 	}
 
+	if link := todos.Meta.LinkHeader(r.URL); link != "" {
+		w.Header().Set("Link", link)
+	}
 	utils.RespondWithPaginatedData(w, http.StatusOK, "Todos fetched successfully", todos.Data, todos.Meta)
 	return
 }
 
+func (handler *Handler) FetchTrash(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	todos, err := handler.TodoService.FetchTrash(r.Context(), dtos.PaginationOptionsFromQuery(r.URL.Query()), authDetails.UserId, authDetails.WorkspaceId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+
+	if link := todos.Meta.LinkHeader(r.URL); link != "" {
+		w.Header().Set("Link", link)
+	}
+	utils.RespondWithPaginatedData(w, http.StatusOK, "Trashed todos fetched successfully", todos.Data, todos.Meta)
+}
+
+func (handler *Handler) RestoreTodo(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	err = handler.TodoService.RestoreTodo(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (handler *Handler) PurgeTodo(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "todo not found", nil)
+		return
+	}
+
+	err = handler.TodoService.PurgeTodo(r.Context(), uint(todoId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, 400, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (handler *Handler) FetchTodo(w http.ResponseWriter, r *http.Request) {
 	todoId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
@@ -271,21 +450,37 @@ func (handler *Handler) FetchTodo(w http.ResponseWriter, r *http.Request) {
 
 func (handler *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/todos", func(r chi.Router) {
-		r.Use(middlewares.JwtAuthMiddleware(handler.TodoService.TokenBlacklistRepository))
+		r.Use(middlewares.JwtAuthMiddleware(handler.TodoService.TokenBlacklistRepository, handler.TodoService.SessionRepository))
+		r.Use(middlewares.WorkspaceMiddleware(handler.TodoService.WorkspaceRepository))
 		r.Post("/", handler.CreateTodo)
+		r.Post("/bulk", handler.BulkUpdateTodos)
 		r.Delete("/{id}", handler.DeleteTodo)
 		r.Patch("/{id}", handler.UpdateTodo)
 		r.Patch("/{id}/pin", handler.PinTodo)
 		r.Patch("/{id}/unpin", handler.UnPinTodo)
+		r.Patch("/{id}/archive", handler.ArchiveTodo)
+		r.Patch("/{id}/unarchive", handler.UnArchiveTodo)
+		r.Patch("/{id}/complete", handler.CompleteTodo)
+		r.Patch("/{id}/reopen", handler.ReopenTodo)
+		r.Get("/{id}/completions", handler.FetchCompletions)
+		r.Patch("/{id}/position", handler.MoveTodo)
 		r.Get("/", handler.FetchTodos)
 		r.Get("/{id}", handler.FetchTodo)
 
+		//Trash
+		r.Group(func(r chi.Router) {
+			r.Get("/trash", handler.FetchTrash)
+			r.Post("/{id}/restore", handler.RestoreTodo)
+			r.Delete("/{id}/permanent", handler.PurgeTodo)
+		})
+
 		//Checklist
 		r.Group(func(r chi.Router) {
 			r.Post("/{id}/checklist", handler.AddChecklistItem)
 			r.Delete("/{id}/checklist/{itemId}", handler.DeleteChecklistItem)
 			r.Put("/{id}/checklist/{itemId}", handler.UpdateChecklistItem)
 			r.Patch("/{id}/checklist/{itemId}", handler.UpdateChecklistItemStatus)
+			r.Patch("/{id}/checklist/{itemId}/position", handler.MoveChecklistItem)
 		})
 	})
 }
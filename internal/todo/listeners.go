@@ -1,12 +1,14 @@
 package todo
 
 import (
+	"context"
 	"fmt"
 	"github.com/horlerdipo/todo-golang/internal/database"
 	"github.com/horlerdipo/todo-golang/internal/dtos"
 	"github.com/horlerdipo/todo-golang/internal/events"
 	"github.com/horlerdipo/todo-golang/internal/sse"
 	"github.com/horlerdipo/todo-golang/pkg"
+	"log"
 )
 
 type TodoCreatedListener struct {
@@ -30,3 +32,149 @@ func NewTodoCreatedListener(repository database.TodoRepository, sseService *sse.
 		SSEService:     sseService,
 	}
 }
+
+// TodoRecurredListener tells everyone who can see the next occurrence of a recurring todo that it was created.
+type TodoRecurredListener struct {
+	TodoShareRepository database.TodoShareRepository
+	SSEService          *sse.Service
+}
+
+func (listener *TodoRecurredListener) Handle(event pkg.Event) {
+	e := event.(*events.TodoRecurredEvent)
+	audience, err := listener.TodoShareRepository.FetchAudience(context.Background(), e.NextTodoId)
+	if err != nil {
+		log.Println("Error while fetching todo audience", err)
+		return
+	}
+
+	message := dtos.SSEData{
+		Event: dtos.TodoRecurred,
+		Data: map[string]uint{
+			"todo_id":      e.TodoId,
+			"next_todo_id": e.NextTodoId,
+		},
+	}
+	for _, userId := range audience {
+		listener.SSEService.SendMessage(userId, message)
+	}
+}
+
+func NewTodoRecurredListener(todoShareRepository database.TodoShareRepository, sseService *sse.Service) *TodoRecurredListener {
+	return &TodoRecurredListener{
+		TodoShareRepository: todoShareRepository,
+		SSEService:          sseService,
+	}
+}
+
+// TodoCompletionListener tells every user who can see a todo when it is completed or reopened.
+type TodoCompletionListener struct {
+	TodoShareRepository database.TodoShareRepository
+	SSEService          *sse.Service
+}
+
+func (listener *TodoCompletionListener) Handle(event pkg.Event) {
+	var todoId uint
+	var message dtos.SSEData
+	switch e := event.(type) {
+	case *events.TodoCompletedEvent:
+		todoId = e.TodoId
+		message = dtos.SSEData{Event: dtos.TodoCompleted, Data: e.TodoId}
+	case *events.TodoReopenedEvent:
+		todoId = e.TodoId
+		message = dtos.SSEData{Event: dtos.TodoReopened, Data: e.TodoId}
+	default:
+		return
+	}
+
+	audience, err := listener.TodoShareRepository.FetchAudience(context.Background(), todoId)
+	if err != nil {
+		log.Println("Error while fetching todo audience", err)
+		return
+	}
+	for _, userId := range audience {
+		listener.SSEService.SendMessage(userId, message)
+	}
+}
+
+func NewTodoCompletionListener(todoShareRepository database.TodoShareRepository, sseService *sse.Service) *TodoCompletionListener {
+	return &TodoCompletionListener{
+		TodoShareRepository: todoShareRepository,
+		SSEService:          sseService,
+	}
+}
+
+// TodoUpdatedListener tells every user who can see a todo, its owner and collaborators, that it changed.
+type TodoUpdatedListener struct {
+	TodoShareRepository database.TodoShareRepository
+	SSEService          *sse.Service
+}
+
+func (listener *TodoUpdatedListener) Handle(event pkg.Event) {
+	e := event.(*events.TodoUpdatedEvent)
+	audience, err := listener.TodoShareRepository.FetchAudience(context.Background(), e.TodoId)
+	if err != nil {
+		log.Println("Error while fetching todo audience", err)
+		return
+	}
+
+	message := dtos.SSEData{
+		Event: dtos.TodoUpdated,
+		Data: map[string]uint{
+			"todo_id":    e.TodoId,
+			"updated_by": e.UserId,
+		},
+	}
+	for _, userId := range audience {
+		listener.SSEService.SendMessage(userId, message)
+	}
+}
+
+func NewTodoUpdatedListener(todoShareRepository database.TodoShareRepository, sseService *sse.Service) *TodoUpdatedListener {
+	return &TodoUpdatedListener{
+		TodoShareRepository: todoShareRepository,
+		SSEService:          sseService,
+	}
+}
+
+// TodosBulkUpdatedListener tells everyone who can see the todos of a bulk action which of them changed, in a single
+// message per user rather than one for every todo.
+type TodosBulkUpdatedListener struct {
+	TodoShareRepository database.TodoShareRepository
+	SSEService          *sse.Service
+}
+
+func (listener *TodosBulkUpdatedListener) Handle(event pkg.Event) {
+	e := event.(*events.TodosBulkUpdatedEvent)
+	todoIds := map[uint][]uint{e.UserId: e.TodoIds}
+	for _, todoId := range e.TodoIds {
+		//trashed todos have no audience left besides the user who trashed them
+		audience, err := listener.TodoShareRepository.FetchAudience(context.Background(), todoId)
+		if err != nil {
+			continue
+		}
+
+		for _, userId := range audience {
+			if userId != e.UserId {
+				todoIds[userId] = append(todoIds[userId], todoId)
+			}
+		}
+	}
+
+	for userId, ids := range todoIds {
+		listener.SSEService.SendMessage(userId, dtos.SSEData{
+			Event: dtos.TodosBulkUpdated,
+			Data: map[string]interface{}{
+				"action":     e.Action,
+				"todo_ids":   ids,
+				"updated_by": e.UserId,
+			},
+		})
+	}
+}
+
+func NewTodosBulkUpdatedListener(todoShareRepository database.TodoShareRepository, sseService *sse.Service) *TodosBulkUpdatedListener {
+	return &TodosBulkUpdatedListener{
+		TodoShareRepository: todoShareRepository,
+		SSEService:          sseService,
+	}
+}
@@ -7,22 +7,31 @@ import (
 	"github.com/horlerdipo/todo-golang/internal/dtos"
 	"github.com/horlerdipo/todo-golang/internal/enums"
 	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/internal/label"
 	"github.com/horlerdipo/todo-golang/pkg"
 	"golang.org/x/net/context"
 	"log"
+	"net/url"
 	"strconv"
+	"time"
 )
 
 type Service struct {
 	TodoRepository           database.TodoRepository
 	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	WorkspaceRepository      database.WorkspaceRepository
+	LabelService             *label.Service
 	EventBus                 pkg.EventBus
 }
 
-func NewService(todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, eventBus pkg.EventBus) *Service {
+func NewService(todoRepository database.TodoRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, workspaceRepository database.WorkspaceRepository, labelService *label.Service, eventBus pkg.EventBus) *Service {
 	return &Service{
 		todoRepository,
 		blacklistRepository,
+		sessionRepository,
+		workspaceRepository,
+		labelService,
 		eventBus,
 	}
 }
@@ -32,6 +41,22 @@ func (service *Service) CreateTodo(ctx context.Context, createTodoDto *dtos.Crea
 		createTodoDto.Content = nil
 	}
 
+	if createTodoDto.Priority == "" {
+		createTodoDto.Priority = enums.PriorityNone
+	}
+
+	createTodoDto.StartAt = pkg.UTC(createTodoDto.StartAt)
+	createTodoDto.DueAt = pkg.UTC(createTodoDto.DueAt)
+	if err := validateSchedule(createTodoDto.StartAt, createTodoDto.DueAt); err != nil {
+		return 0, err
+	}
+
+	recurrence, err := normaliseRecurrence(createTodoDto.Recurrence, createTodoDto.DueAt)
+	if err != nil {
+		return 0, err
+	}
+	createTodoDto.Recurrence = recurrence
+
 	todoId, err := service.TodoRepository.CreateTodo(ctx, createTodoDto)
 	if err != nil {
 		log.Println(err)
@@ -45,8 +70,8 @@ func (service *Service) CreateTodo(ctx context.Context, createTodoDto *dtos.Crea
 }
 
 func (service *Service) DeleteTodo(ctx context.Context, todoId uint, userId uint) error {
-	//check if user and to-do exists
-	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	//check if the user may delete the to-do
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
 	if err != nil {
 		log.Println(err)
 		return errors.New("todo does not exist")
@@ -60,47 +85,160 @@ func (service *Service) DeleteTodo(ctx context.Context, todoId uint, userId uint
 	return nil
 }
 
+// UpdateTodo replaces the todo's title, type and content. Its dates, recurrence and priority are only changed when
+// the request sends them, and sending the dates or recurrence as null clears them.
 func (service *Service) UpdateTodo(ctx context.Context, todoId uint, updateTodoDto *dtos.UpdateTodoDTO, userId uint) error {
 
-	todo, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
 	if err != nil {
-		return errors.New("todo does not exist")
+		return err
 	}
 
-	deleteChecklist := false
-	todo.Title = updateTodoDto.Title
+	//leaving out the dates or the recurrence keeps the ones the todo already has, while null clears them
+	startAt := pkg.UTC(updateTodoDto.StartAt.Or(todo.StartAt))
+	dueAt := pkg.UTC(updateTodoDto.DueAt.Or(todo.DueAt))
+	if err := validateSchedule(startAt, dueAt); err != nil {
+		return err
+	}
 
-	//if type is changing from checklist to text, delete checklist
-	//and if type is changing from text to checklist, make content nil
-	if updateTodoDto.Type == enums.Text {
-		deleteChecklist = true
-	} else {
-		updateTodoDto.Content = nil
+	recurrence, err := normaliseRecurrence(updateTodoDto.Recurrence.Or(todo.Recurrence), dueAt)
+	if err != nil {
+		return err
 	}
+	updateTodoDto.StartAt = dtos.NullableOf(startAt)
+	updateTodoDto.DueAt = dtos.NullableOf(dueAt)
+	updateTodoDto.Recurrence = dtos.NullableOf(recurrence)
 
-	err = service.TodoRepository.UpdateTodo(ctx, todoId, updateTodoDto, deleteChecklist)
-	return err
-}
+	//leaving out the priority keeps the one the todo already has
+	if updateTodoDto.Priority == "" {
+		updateTodoDto.Priority = todo.Priority
+	}
 
-func (service *Service) FetchTodos(ctx context.Context, pagination dtos.PaginationOptions, userId uint) (dtos.PaginatedResponse[database.Todo], error) {
+	//checklists have no content of their own
+	if updateTodoDto.Type == enums.Checklist {
+		updateTodoDto.Content = nil
+	}
 
-	pagination.AllowedSortFields = map[string]bool{
-		"id":         true,
-		"title":      true,
-		"created_at": true,
-		"updated_at": true,
+	err = service.TodoRepository.Transaction(ctx, func(repo database.TodoRepository) error {
+		//changing the type turns the content into checklist items and back, like the bulk change_type action
+		if updateTodoDto.Type != todo.Type {
+			if err := repo.ChangeTodoType(ctx, todoId, updateTodoDto.Type); err != nil {
+				return err
+			}
+		}
+		return repo.UpdateTodo(ctx, todoId, updateTodoDto)
+	})
+	if err != nil {
+		return err
 	}
 
-	pagination.AllowedFilters = map[string]dtos.AllowedFilter{
-		"title": {
-			Type: dtos.StringFilter,
-		},
-		"pinned": {
-			Type: dtos.BooleanFilter,
-		},
+	if !sameTime(todo.DueAt, dueAt) {
+		service.EventBus.Publish(&events.TodoRescheduledEvent{
+			TodoId: todoId,
+			UserId: userId,
+			DueAt:  dueAt,
+		})
 	}
 
-	todos, err := service.TodoRepository.FetchAll(ctx, pagination, userId)
+	service.publishUpdated(todoId, userId)
+	return nil
+}
+
+// todoSortFields are the fields FetchTodos sorts by.
+var todoSortFields = map[string]bool{
+	"id":           true,
+	"title":        true,
+	"created_at":   true,
+	"updated_at":   true,
+	"start_at":     true,
+	"due_at":       true,
+	"archived_at":  true,
+	"completed_at": true,
+	"priority":     true,
+	"position":     true,
+}
+
+// todoFilters are the filters FetchTodos lists todos by.
+var todoFilters = map[string]dtos.AllowedFilter{
+	"title": {
+		Type: dtos.StringFilter,
+	},
+	"pinned": {
+		Type: dtos.BooleanFilter,
+	},
+	//one of exclude (the default), include or only
+	"archived": {
+		Type: dtos.StringFilter,
+	},
+	//one of exclude (the default), include or only
+	"shared": {
+		Type: dtos.StringFilter,
+	},
+	"completed": {
+		Type: dtos.BooleanFilter,
+	},
+	"priority": {
+		Type: dtos.StringFilter,
+	},
+	"overdue": {
+		Type: dtos.BooleanFilter,
+	},
+	"due_today": {
+		Type: dtos.BooleanFilter,
+	},
+	"due_this_week": {
+		Type: dtos.BooleanFilter,
+	},
+	"due_from": {
+		Type: dtos.DateFilter,
+	},
+	"due_to": {
+		Type: dtos.DateFilter,
+	},
+	"labels": {
+		Type: dtos.IntegerListFilter,
+	},
+	"labels_all": {
+		Type: dtos.IntegerListFilter,
+	},
+	"content": {
+		Type: dtos.StringFilter,
+	},
+	"occurrence": {
+		Type: dtos.IntegerFilter,
+	},
+	"start_at": {
+		Type: dtos.DateFilter,
+	},
+	"due_at": {
+		Type: dtos.DateFilter,
+	},
+	"completed_at": {
+		Type: dtos.DateFilter,
+	},
+	"created_at": {
+		Type: dtos.DateFilter,
+	},
+	"updated_at": {
+		Type: dtos.DateFilter,
+	},
+}
+
+// ValidateTodoQuery checks that query only asks for the filters and sort FetchTodos lists todos by, so a query can
+// be checked before it is saved rather than when it is run.
+func (service *Service) ValidateTodoQuery(query url.Values) error {
+	pagination := dtos.PaginationOptionsFromQuery(query)
+	pagination.AllowedSortFields = todoSortFields
+	pagination.AllowedFilters = todoFilters
+	return pagination.Validate()
+}
+
+// FetchTodos lists the user's personal todos, or the todos of workspaceId when it is set.
+func (service *Service) FetchTodos(ctx context.Context, pagination dtos.PaginationOptions, userId uint, workspaceId *uint) (dtos.PaginatedResponse[database.Todo], error) {
+	pagination.AllowedSortFields = todoSortFields
+	pagination.AllowedFilters = todoFilters
+
+	todos, err := service.TodoRepository.FetchAll(ctx, pagination, userId, workspaceId)
 	if err != nil {
 		return todos, err
 	}
@@ -108,69 +246,269 @@ func (service *Service) FetchTodos(ctx context.Context, pagination dtos.Paginati
 }
 
 func (service *Service) FetchTodo(ctx context.Context, todoId uint, userId uint) (*database.Todo, error) {
-	todo, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, true)
+	todo, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, true)
 	if err != nil {
 		return todo, err
 	}
+
+	todo.Checklists = checklistTree(todo.Checklists)
 	return todo, err
 }
 
-func (service *Service) AddChecklistItem(ctx context.Context, todoId uint, description string, userId uint) error {
-	todo, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+// AddChecklistItem adds an item to a checklist todo, as a sub-item of parentId when it is set.
+func (service *Service) AddChecklistItem(ctx context.Context, todoId uint, description string, parentId *uint, userId uint) error {
+	todo, err := service.findEditableTodo(ctx, todoId, userId, true)
 	if err != nil {
-		log.Println(err)
-		return errors.New("todo does not exist")
+		return err
 	}
 
 	if todo.Type != enums.Checklist {
 		return errors.New("only todos with type of checklists are supported")
 	}
 
-	_, err = service.TodoRepository.AddChecklistItem(ctx, todoId, description)
-	return err
+	if parentId != nil {
+		depth := checklistDepth(todo.Checklists, *parentId)
+		if depth == 0 {
+			return errors.New("parent checklist not found")
+		}
+
+		maxDepth := env.FetchInt("CHECKLIST_MAX_DEPTH", 3)
+		if depth >= maxDepth {
+			return errors.New("checklist items can only be nested " + strconv.Itoa(maxDepth) + " levels deep")
+		}
+	}
+
+	_, err = service.TodoRepository.AddChecklistItem(ctx, todoId, description, parentId)
+	if err != nil {
+		return err
+	}
+
+	service.syncChecklistCompletion(ctx, todo, userId)
+	service.publishUpdated(todoId, userId)
+	return nil
 }
 
 func (service *Service) DeleteChecklistItem(ctx context.Context, checklistId uint, todoId uint, userId uint) error {
-	todo, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
 	if err != nil {
-		log.Println(err)
-		return errors.New("todo does not exist")
+		return err
 	}
 
 	if todo.Type != enums.Checklist {
 		return errors.New("only todos with type of checklists are supported")
 	}
 
-	return service.TodoRepository.DeleteChecklistItem(ctx, checklistId, todoId)
+	err = service.TodoRepository.DeleteChecklistItem(ctx, checklistId, todoId)
+	if err != nil {
+		return err
+	}
+
+	service.syncChecklistCompletion(ctx, todo, userId)
+	service.publishUpdated(todoId, userId)
+	return nil
 }
 
 func (service *Service) UpdateChecklistItem(ctx context.Context, checklistId uint, description string, todoId uint, userId uint) (uint, error) {
-	todo, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
 	if err != nil {
-		log.Println(err)
-		return 0, errors.New("todo does not exist")
+		return 0, err
 	}
 
 	if todo.Type != enums.Checklist {
 		return 0, errors.New("only todos with type of checklists are supported")
 	}
 
-	return service.TodoRepository.UpdateChecklistItem(ctx, checklistId, todoId, description)
+	checklistId, err = service.TodoRepository.UpdateChecklistItem(ctx, checklistId, todoId, description)
+	if err != nil {
+		return 0, err
+	}
+
+	service.publishUpdated(todoId, userId)
+	return checklistId, nil
 }
 
 func (service *Service) UpdateChecklistItemStatus(ctx context.Context, checklistId uint, done bool, todoId uint, userId uint) (uint, error) {
-	todo, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
 	if err != nil {
-		log.Println(err)
-		return 0, errors.New("todo does not exist")
+		return 0, err
 	}
 
-	log.Printf("Todo: +%v", todo)
 	if todo.Type != enums.Checklist {
 		return 0, errors.New("only todos with type of checklists are supported")
 	}
 
-	return service.TodoRepository.UpdateChecklistItemStatus(ctx, checklistId, todoId, done)
+	checklistId, err = service.TodoRepository.UpdateChecklistItemStatus(ctx, checklistId, todoId, done)
+	if err != nil {
+		return 0, err
+	}
+
+	service.syncChecklistCompletion(ctx, todo, userId)
+	service.publishUpdated(todoId, userId)
+	return checklistId, nil
+}
+
+func (service *Service) MoveTodo(ctx context.Context, todoId uint, reorderDto *dtos.ReorderDTO, userId uint) error {
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
+	if err != nil {
+		return err
+	}
+
+	if isSelf(todoId, reorderDto) {
+		return errors.New("a todo cannot be moved next to itself")
+	}
+
+	return service.TodoRepository.MoveTodo(ctx, todoId, todo.UserID, todo.WorkspaceID, reorderDto.AfterID, reorderDto.BeforeID)
+}
+
+func (service *Service) MoveChecklistItem(ctx context.Context, checklistId uint, todoId uint, reorderDto *dtos.ReorderDTO, userId uint) error {
+	todo, err := service.findEditableTodo(ctx, todoId, userId, true)
+	if err != nil {
+		return err
+	}
+
+	if todo.Type != enums.Checklist {
+		return errors.New("only todos with type of checklists are supported")
+	}
+
+	found := false
+	for _, checklist := range todo.Checklists {
+		if checklist.ID == checklistId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("checklist not found")
+	}
+
+	if isSelf(checklistId, reorderDto) {
+		return errors.New("a checklist item cannot be moved next to itself")
+	}
+
+	err = service.TodoRepository.MoveChecklistItem(ctx, checklistId, todoId, reorderDto.AfterID, reorderDto.BeforeID)
+	if err != nil {
+		return err
+	}
+
+	service.publishUpdated(todoId, userId)
+	return nil
+}
+
+// CompleteTodo completes a text todo. Checklist todos complete themselves once every item is done.
+func (service *Service) CompleteTodo(ctx context.Context, todoId uint, userId uint) error {
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
+	if err != nil {
+		return err
+	}
+
+	if todo.Type == enums.Checklist {
+		return errors.New("checklist todos are completed by checking off their items")
+	}
+
+	return service.complete(ctx, todo, userId)
+}
+
+// ReopenTodo reopens a completed text todo. Checklist todos reopen themselves once an item is unchecked.
+func (service *Service) ReopenTodo(ctx context.Context, todoId uint, userId uint) error {
+	todo, err := service.findEditableTodo(ctx, todoId, userId, false)
+	if err != nil {
+		return err
+	}
+
+	if todo.Type == enums.Checklist {
+		return errors.New("checklist todos are reopened by unchecking one of their items")
+	}
+
+	return service.reopen(ctx, todo, userId)
+}
+
+func (service *Service) FetchCompletions(ctx context.Context, todoId uint, userId uint) ([]database.TodoCompletion, error) {
+	_, _, err := service.TodoRepository.FindTodoForUser(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	return service.TodoRepository.FetchCompletions(ctx, todoId)
+}
+
+// complete marks a todo as completed, recording userId as the one who completed it.
+func (service *Service) complete(ctx context.Context, todo *database.Todo, userId uint) error {
+	completed, err := service.TodoRepository.CompleteTodo(ctx, todo.ID, userId, time.Now())
+	if err != nil || !completed {
+		return err
+	}
+
+	service.EventBus.Publish(&events.TodoCompletedEvent{
+		TodoId: todo.ID,
+		UserId: todo.UserID,
+	})
+	service.publishUpdated(todo.ID, userId)
+
+	//completing an occurrence of a recurring todo schedules the next one
+	if todo.Recurrence != nil {
+		service.spawnNextOccurrence(ctx, todo)
+	}
+	return nil
+}
+
+func (service *Service) reopen(ctx context.Context, todo *database.Todo, userId uint) error {
+	reopened, err := service.TodoRepository.ReopenTodo(ctx, todo.ID)
+	if err != nil || !reopened {
+		return err
+	}
+
+	service.EventBus.Publish(&events.TodoReopenedEvent{
+		TodoId: todo.ID,
+		UserId: todo.UserID,
+	})
+	service.publishUpdated(todo.ID, userId)
+	return nil
+}
+
+// syncChecklistCompletion completes a checklist todo once every item is done and reopens it as soon as one is not.
+func (service *Service) syncChecklistCompletion(ctx context.Context, todo *database.Todo, userId uint) {
+	total, done, err := service.TodoRepository.CountChecklistItems(ctx, todo.ID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	if total > 0 && total == done {
+		err = service.complete(ctx, todo, userId)
+	} else {
+		err = service.reopen(ctx, todo, userId)
+	}
+
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// findEditableTodo finds a todo the user owns or has been shared as an editor.
+func (service *Service) findEditableTodo(ctx context.Context, todoId uint, userId uint, withChecklist bool) (*database.Todo, error) {
+	return editableTodo(ctx, service.TodoRepository, todoId, userId, withChecklist)
+}
+
+// editableTodo is findEditableTodo for a given repository, so bulk actions can look todos up inside their transaction.
+func editableTodo(ctx context.Context, repository database.TodoRepository, todoId uint, userId uint, withChecklist bool) (*database.Todo, error) {
+	todo, role, err := repository.FindTodoForUser(ctx, todoId, userId, withChecklist)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("todo does not exist")
+	}
+
+	if !role.CanEdit() {
+		return nil, errors.New("you do not have permission to edit this todo")
+	}
+	return todo, nil
+}
+
+func (service *Service) publishUpdated(todoId uint, userId uint) {
+	service.EventBus.Publish(&events.TodoUpdatedEvent{
+		TodoId: todoId,
+		UserId: userId,
+	})
 }
 
 func (service *Service) PinTodo(ctx context.Context, todoId uint, userId uint) error {
@@ -181,20 +519,24 @@ func (service *Service) PinTodo(ctx context.Context, todoId uint, userId uint) e
 		return errors.New("you can only pin " + strconv.Itoa(maxPinnedTodos) + " todos")
 	}
 
-	//check if to-do belongs to user
-	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	//check if the user may manage the to-do
+	todo, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
 	if err != nil {
 		log.Println(err)
 		return errors.New("todo does not exist")
 	}
 
+	if todo.ArchivedAt != nil {
+		return errors.New("archived todos cannot be pinned")
+	}
+
 	return service.TodoRepository.PinTodo(ctx, todoId)
 }
 
 func (service *Service) UnPinTodo(ctx context.Context, todoId uint, userId uint) error {
 
-	//check if to-do belongs to user
-	_, err := service.TodoRepository.FindTodoByUserId(ctx, todoId, userId, false)
+	//check if the user may manage the to-do
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
 	if err != nil {
 		log.Println(err)
 		return errors.New("todo does not exist")
@@ -202,3 +544,412 @@ func (service *Service) UnPinTodo(ctx context.Context, todoId uint, userId uint)
 
 	return service.TodoRepository.UnPinTodo(ctx, todoId)
 }
+
+func (service *Service) ArchiveTodo(ctx context.Context, todoId uint, userId uint) error {
+	//check if the user may manage the to-do
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo does not exist")
+	}
+
+	return service.TodoRepository.ArchiveTodo(ctx, todoId)
+}
+
+func (service *Service) UnArchiveTodo(ctx context.Context, todoId uint, userId uint) error {
+	//check if the user may manage the to-do
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, false)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo does not exist")
+	}
+
+	return service.TodoRepository.UnArchiveTodo(ctx, todoId)
+}
+
+// BulkUpdateTodos applies one action to several of the user's todos in a single transaction. Every todo gets its
+// own savepoint, so one that fails is reported in its result without undoing the others.
+func (service *Service) BulkUpdateTodos(ctx context.Context, bulkDto *dtos.BulkTodoDTO, userId uint) ([]dtos.BulkTodoResult, error) {
+	if bulkDto.Action == dtos.BulkAddLabel {
+		if _, err := service.LabelService.ValidateUserLabels(ctx, []uint{bulkDto.LabelID}, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]dtos.BulkTodoResult, 0, len(bulkDto.IDs))
+	updatedIds := make([]uint, 0, len(bulkDto.IDs))
+	var completions []bulkCompletion
+	seen := make(map[uint]bool, len(bulkDto.IDs))
+	err := service.TodoRepository.Transaction(ctx, func(repo database.TodoRepository) error {
+		for _, todoId := range bulkDto.IDs {
+			if seen[todoId] {
+				continue
+			}
+			seen[todoId] = true
+
+			var completion *bulkCompletion
+			err := repo.Transaction(ctx, func(repo database.TodoRepository) error {
+				var err error
+				completion, err = applyBulkAction(ctx, repo, bulkDto, todoId, userId)
+				return err
+			})
+
+			result := dtos.BulkTodoResult{ID: todoId, Success: err == nil}
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				updatedIds = append(updatedIds, todoId)
+				if completion != nil {
+					result.NextTodoID = completion.nextTodoId
+					completions = append(completions, *completion)
+				}
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("unable to apply bulk action, please try again")
+	}
+
+	//the same events a single completion publishes, held back until the transaction has committed
+	for _, completion := range completions {
+		service.EventBus.Publish(&events.TodoCompletedEvent{
+			TodoId: completion.todo.ID,
+			UserId: completion.todo.UserID,
+		})
+		if completion.nextTodoId != 0 {
+			service.EventBus.Publish(&events.TodoRecurredEvent{
+				TodoId:     completion.todo.ID,
+				NextTodoId: completion.nextTodoId,
+				UserId:     completion.todo.UserID,
+			})
+		}
+	}
+
+	if len(updatedIds) > 0 {
+		service.EventBus.Publish(&events.TodosBulkUpdatedEvent{
+			Action:  string(bulkDto.Action),
+			TodoIds: updatedIds,
+			UserId:  userId,
+		})
+	}
+	return results, nil
+}
+
+// bulkCompletion is a todo completed by a bulk action, along with the next occurrence created for it, if any.
+type bulkCompletion struct {
+	todo       *database.Todo
+	nextTodoId uint
+}
+
+// applyBulkAction applies a bulk action to one todo, following the rules of the matching single todo endpoint. It
+// returns the completion when the action completed the todo.
+func applyBulkAction(ctx context.Context, repo database.TodoRepository, bulkDto *dtos.BulkTodoDTO, todoId uint, userId uint) (*bulkCompletion, error) {
+	switch bulkDto.Action {
+	case dtos.BulkComplete, dtos.BulkChangeType:
+		todo, err := editableTodo(ctx, repo, todoId, userId, false)
+		if err != nil {
+			return nil, err
+		}
+		return applyBulkEdit(ctx, repo, bulkDto, todo, userId)
+	case dtos.BulkAddLabel:
+		//labels belong to the user, so like LabelService.AttachLabels only the todo's owner may add them
+		if _, err := repo.FindTodoByUserId(ctx, todoId, userId, false); err != nil {
+			log.Println(err)
+			return nil, errors.New("todo does not exist")
+		}
+		return nil, repo.AttachLabels(ctx, todoId, []uint{bulkDto.LabelID})
+	}
+
+	todo, err := repo.FindManageableTodo(ctx, todoId, userId, false)
+	if err != nil {
+		return nil, errors.New("todo does not exist")
+	}
+
+	switch bulkDto.Action {
+	case dtos.BulkDelete:
+		if err = repo.DeleteTodo(ctx, todoId); err != nil {
+			log.Println(err)
+			return nil, errors.New("unable to delete todo, please try again")
+		}
+		return nil, nil
+	case dtos.BulkPin:
+		if todo.Pinned {
+			return nil, nil
+		}
+		if todo.ArchivedAt != nil {
+			return nil, errors.New("archived todos cannot be pinned")
+		}
+
+		//the count includes the todos pinned earlier in the same batch
+		maxPinnedTodos := env.FetchInt("MAXIMUM_PINNED_TODOS", 1)
+		if int(repo.CountPinnedTodos(ctx, userId)) >= maxPinnedTodos {
+			return nil, errors.New("you can only pin " + strconv.Itoa(maxPinnedTodos) + " todos")
+		}
+		return nil, repo.PinTodo(ctx, todoId)
+	case dtos.BulkUnpin:
+		return nil, repo.UnPinTodo(ctx, todoId)
+	case dtos.BulkArchive:
+		return nil, repo.ArchiveTodo(ctx, todoId)
+	}
+	return nil, errors.New("unsupported bulk action")
+}
+
+// applyBulkEdit applies the bulk actions anyone who can edit the todo may take, collaborators included.
+func applyBulkEdit(ctx context.Context, repo database.TodoRepository, bulkDto *dtos.BulkTodoDTO, todo *database.Todo, userId uint) (*bulkCompletion, error) {
+	switch bulkDto.Action {
+	case dtos.BulkComplete:
+		if todo.Type == enums.Checklist {
+			return nil, errors.New("checklist todos are completed by checking off their items")
+		}
+
+		completed, err := repo.CompleteTodo(ctx, todo.ID, userId, time.Now())
+		if err != nil || !completed {
+			return nil, err
+		}
+		return &bulkCompletion{todo: todo, nextTodoId: createNextOccurrence(ctx, repo, todo)}, nil
+	case dtos.BulkChangeType:
+		if todo.Type == bulkDto.Type {
+			return nil, nil
+		}
+		return nil, repo.ChangeTodoType(ctx, todo.ID, bulkDto.Type)
+	}
+	return nil, errors.New("unsupported bulk action")
+}
+
+// FetchTrash lists the trashed todos of the active workspace the user may restore, or their trashed personal todos.
+func (service *Service) FetchTrash(ctx context.Context, pagination dtos.PaginationOptions, userId uint, workspaceId *uint) (dtos.PaginatedResponse[database.Todo], error) {
+	//most recently trashed first, unless asked otherwise
+	if pagination.SortBy == "" {
+		pagination.SortBy = "deleted_at"
+		if pagination.Order == "" {
+			pagination.Order = dtos.OrderDesc
+		}
+	}
+
+	pagination.AllowedSortFields = map[string]bool{
+		"id":         true,
+		"title":      true,
+		"created_at": true,
+		"deleted_at": true,
+		"due_at":     true,
+	}
+
+	pagination.AllowedFilters = map[string]dtos.AllowedFilter{
+		"title": {
+			Type: dtos.StringFilter,
+		},
+		"labels": {
+			Type: dtos.IntegerListFilter,
+		},
+		"due_at": {
+			Type: dtos.DateFilter,
+		},
+		"deleted_at": {
+			Type: dtos.DateFilter,
+		},
+	}
+
+	return service.TodoRepository.FetchTrashed(ctx, pagination, userId, workspaceId)
+}
+
+func (service *Service) RestoreTodo(ctx context.Context, todoId uint, userId uint) error {
+	todo, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, true)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo is not in the trash")
+	}
+
+	err = service.TodoRepository.RestoreTodo(ctx, todoId)
+	if err != nil {
+		return err
+	}
+
+	//trashed todos do not count towards the pin limit, so a restored todo may no longer fit
+	if todo.Pinned {
+		maxPinnedTodos := env.FetchInt("MAXIMUM_PINNED_TODOS", 1)
+		if int(service.TodoRepository.CountPinnedTodos(ctx, userId)) > maxPinnedTodos {
+			return service.TodoRepository.UnPinTodo(ctx, todoId)
+		}
+	}
+	return nil
+}
+
+func (service *Service) PurgeTodo(ctx context.Context, todoId uint, userId uint) error {
+	_, err := service.TodoRepository.FindManageableTodo(ctx, todoId, userId, true)
+	if err != nil {
+		log.Println(err)
+		return errors.New("todo is not in the trash")
+	}
+
+	storageKeys, err := service.TodoRepository.PurgeTodo(ctx, todoId)
+	if err != nil {
+		return err
+	}
+
+	service.publishPurged(storageKeys)
+	return nil
+}
+
+// PurgeTrash permanently deletes todos that have been in the trash for longer than TRASH_RETENTION_DAYS.
+func (service *Service) PurgeTrash(ctx context.Context) {
+	retentionDays := env.FetchInt("TRASH_RETENTION_DAYS", 30)
+	purged, storageKeys, err := service.TodoRepository.PurgeTrashedTodos(ctx, time.Now().AddDate(0, 0, -retentionDays))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	service.publishPurged(storageKeys)
+
+	if purged > 0 {
+		log.Printf("Purged %d todos from the trash", purged)
+	}
+}
+
+// publishPurged lets the attachment subsystem remove the blobs of todos that were just purged. The purge recorded
+// them as orphaned, so any it misses are swept up later.
+func (service *Service) publishPurged(storageKeys []string) {
+	if len(storageKeys) == 0 {
+		return
+	}
+
+	service.EventBus.Publish(&events.TodoPurgedEvent{
+		StorageKeys: storageKeys,
+	})
+}
+
+// validateSchedule makes sure a todo does not start after it is due.
+func validateSchedule(startAt *time.Time, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && startAt.After(*dueAt) {
+		return errors.New("start date cannot be after due date")
+	}
+	return nil
+}
+
+// checklistTree nests checklist items under their parents, keeping the order they were given in.
+func checklistTree(items []database.Checklist) []database.Checklist {
+	children := make(map[uint][]database.Checklist)
+	ids := make(map[uint]bool, len(items))
+	for _, item := range items {
+		ids[item.ID] = true
+	}
+
+	var roots []database.Checklist
+	for _, item := range items {
+		if item.ParentID != nil && ids[*item.ParentID] {
+			children[*item.ParentID] = append(children[*item.ParentID], item)
+			continue
+		}
+		roots = append(roots, item)
+	}
+
+	var attach func(items []database.Checklist) []database.Checklist
+	attach = func(items []database.Checklist) []database.Checklist {
+		for i := range items {
+			items[i].Children = attach(children[items[i].ID])
+		}
+		if items == nil {
+			return []database.Checklist{}
+		}
+		return items
+	}
+	return attach(roots)
+}
+
+// checklistDepth returns how deeply a checklist item is nested, 1 for a top level item and 0 when it is not found.
+func checklistDepth(items []database.Checklist, checklistId uint) int {
+	parents := make(map[uint]*uint, len(items))
+	for _, item := range items {
+		parents[item.ID] = item.ParentID
+	}
+
+	depth := 0
+	for current := &checklistId; current != nil && depth <= len(items); depth++ {
+		parentId, ok := parents[*current]
+		if !ok {
+			return depth
+		}
+		current = parentId
+	}
+	return depth
+}
+
+func isSelf(id uint, reorderDto *dtos.ReorderDTO) bool {
+	return (reorderDto.AfterID != nil && *reorderDto.AfterID == id) || (reorderDto.BeforeID != nil && *reorderDto.BeforeID == id)
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// normaliseRecurrence validates a recurrence rule and rewrites it into its canonical RRULE form.
+func normaliseRecurrence(recurrence *string, dueAt *time.Time) (*string, error) {
+	if recurrence == nil || *recurrence == "" {
+		return nil, nil
+	}
+
+	if dueAt == nil {
+		return nil, errors.New("recurring todos must have a due date")
+	}
+
+	rule, err := pkg.ParseRecurrenceRule(*recurrence)
+	if err != nil {
+		return nil, errors.New("invalid recurrence: " + err.Error())
+	}
+
+	canonical := rule.String()
+	return &canonical, nil
+}
+
+// spawnNextOccurrence creates the todo for the next occurrence of a completed recurring todo.
+func (service *Service) spawnNextOccurrence(ctx context.Context, todo *database.Todo) {
+	nextTodoId := createNextOccurrence(ctx, service.TodoRepository, todo)
+	if nextTodoId == 0 {
+		return
+	}
+
+	service.EventBus.Publish(&events.TodoRecurredEvent{
+		TodoId:     todo.ID,
+		NextTodoId: nextTodoId,
+		UserId:     todo.UserID,
+	})
+}
+
+// createNextOccurrence creates the todo for the next occurrence of a recurring todo, keeping the gap between its
+// start and due dates. It returns 0 when the todo does not recur again.
+func createNextOccurrence(ctx context.Context, repository database.TodoRepository, todo *database.Todo) uint {
+	if todo.Recurrence == nil || todo.DueAt == nil {
+		return 0
+	}
+
+	rule, err := pkg.ParseRecurrenceRule(*todo.Recurrence)
+	if err != nil {
+		log.Println(err)
+		return 0
+	}
+
+	nextDueAt, ok := rule.Next(*todo.DueAt, todo.Occurrence)
+	if !ok {
+		return 0
+	}
+
+	var nextStartAt *time.Time
+	if todo.StartAt != nil {
+		startAt := nextDueAt.Add(todo.StartAt.Sub(*todo.DueAt))
+		nextStartAt = &startAt
+	}
+
+	nextTodoId, err := repository.CreateNextOccurrence(ctx, todo.ID, nextDueAt.UTC(), pkg.UTC(nextStartAt))
+	if err != nil {
+		log.Println(err)
+		return 0
+	}
+	return nextTodoId
+}
@@ -0,0 +1,36 @@
+package view
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/label"
+	"github.com/horlerdipo/todo-golang/internal/todo"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	ViewService *Service
+	ViewHandler *Handler
+}
+
+// NewContainer wires saved views up to the todo handler, which lists the todos of each view and checks the filters
+// they list them by, and the label service, which checks the labels they filter by.
+func NewContainer(db *gorm.DB, todoHandler *todo.Handler, labelService *label.Service) *Container {
+	viewService := NewService(
+		database.NewViewRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		database.NewWorkspaceRepository(db),
+		labelService,
+		todoHandler.TodoService,
+	)
+
+	return &Container{
+		ViewService: viewService,
+		ViewHandler: NewHandler(viewService, todoHandler),
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.ViewHandler.RegisterRoutes(r)
+}
@@ -0,0 +1,188 @@
+package view
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/internal/todo"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+// pagingParams are taken from the request when listing a view's todos; everything else comes from the view.
+var pagingParams = []string{"page", "per_page", "cursor"}
+
+type Handler struct {
+	ViewService *Service
+	TodoHandler *todo.Handler
+}
+
+func NewHandler(viewService *Service, todoHandler *todo.Handler) *Handler {
+	return &Handler{
+		ViewService: viewService,
+		TodoHandler: todoHandler,
+	}
+}
+
+func (handler *Handler) CreateView(w http.ResponseWriter, r *http.Request) {
+	jsonRequest, err := utils.JsonValidate[dtos.SaveViewDTO](w, r)
+	if err != nil {
+		return
+	}
+
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.UserID = authDetails.UserId
+
+	viewId, err := handler.ViewService.CreateView(r.Context(), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, saveViewErrorStatus(err), err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusCreated, "View created successfully", map[string]uint{"id": viewId})
+}
+
+func (handler *Handler) UpdateView(w http.ResponseWriter, r *http.Request) {
+	viewId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "view not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.SaveViewDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.UserID = authDetails.UserId
+
+	err = handler.ViewService.UpdateView(r.Context(), uint(viewId), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, saveViewErrorStatus(err), err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// saveViewErrorStatus responds to a view whose filters or sort todos cannot be listed by as invalid input.
+func saveViewErrorStatus(err error) int {
+	if errors.Is(err, dtos.ErrInvalidQuery) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+func (handler *Handler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	viewId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "view not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.ViewService.DeleteView(r.Context(), uint(viewId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) FetchView(w http.ResponseWriter, r *http.Request) {
+	viewId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "view not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	view, err := handler.ViewService.FetchView(r.Context(), uint(viewId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "View fetched successfully", view)
+}
+
+func (handler *Handler) FetchViews(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	views, err := handler.ViewService.FetchViews(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Views fetched successfully", views)
+}
+
+func (handler *Handler) MoveView(w http.ResponseWriter, r *http.Request) {
+	viewId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "view not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.ReorderDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.ViewService.MoveView(r.Context(), uint(viewId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) FetchViewTodos(w http.ResponseWriter, r *http.Request) {
+	viewId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "view not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	view, err := handler.ViewService.FetchView(r.Context(), uint(viewId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	handler.listTodos(w, r, view)
+}
+
+// FetchDefaultViewTodos lists the todos of the user's default view, or all of their todos when none is marked.
+func (handler *Handler) FetchDefaultViewTodos(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	handler.listTodos(w, r, handler.ViewService.FetchDefaultView(r.Context(), authDetails.UserId))
+}
+
+// listTodos lists a view's todos the way GET /todos would list them for the view's query string.
+func (handler *Handler) listTodos(w http.ResponseWriter, r *http.Request, view *database.View) {
+	query := view.Query()
+	for _, param := range pagingParams {
+		if r.URL.Query().Has(param) {
+			query.Set(param, r.URL.Query().Get(param))
+		}
+	}
+	handler.TodoHandler.ListTodos(w, r, query)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/views", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.ViewService.TokenBlacklistRepository, handler.ViewService.SessionRepository))
+		r.Use(middlewares.WorkspaceMiddleware(handler.ViewService.WorkspaceRepository))
+		r.Post("/", handler.CreateView)
+		r.Get("/", handler.FetchViews)
+		r.Get("/default/todos", handler.FetchDefaultViewTodos)
+		r.Get("/{id}", handler.FetchView)
+		r.Put("/{id}", handler.UpdateView)
+		r.Delete("/{id}", handler.DeleteView)
+		r.Patch("/{id}/position", handler.MoveView)
+		r.Get("/{id}/todos", handler.FetchViewTodos)
+	})
+}
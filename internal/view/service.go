@@ -0,0 +1,114 @@
+package view
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/label"
+	"github.com/horlerdipo/todo-golang/internal/todo"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+type Service struct {
+	ViewRepository           database.ViewRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	WorkspaceRepository      database.WorkspaceRepository
+	LabelService             *label.Service
+	TodoService              *todo.Service
+}
+
+func NewService(viewRepository database.ViewRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, workspaceRepository database.WorkspaceRepository, labelService *label.Service, todoService *todo.Service) *Service {
+	return &Service{
+		ViewRepository:           viewRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		WorkspaceRepository:      workspaceRepository,
+		LabelService:             labelService,
+		TodoService:              todoService,
+	}
+}
+
+func (service *Service) CreateView(ctx context.Context, saveViewDto *dtos.SaveViewDTO) (uint, error) {
+	if err := service.normaliseView(ctx, saveViewDto); err != nil {
+		return 0, err
+	}
+	return service.ViewRepository.CreateView(ctx, saveViewDto)
+}
+
+func (service *Service) UpdateView(ctx context.Context, viewId uint, saveViewDto *dtos.SaveViewDTO) error {
+	_, err := service.ViewRepository.FindViewByUserId(ctx, viewId, saveViewDto.UserID)
+	if err != nil {
+		return errors.New("view does not exist")
+	}
+
+	if err = service.normaliseView(ctx, saveViewDto); err != nil {
+		return err
+	}
+	return service.ViewRepository.UpdateView(ctx, viewId, saveViewDto)
+}
+
+func (service *Service) DeleteView(ctx context.Context, viewId uint, userId uint) error {
+	_, err := service.ViewRepository.FindViewByUserId(ctx, viewId, userId)
+	if err != nil {
+		return errors.New("view does not exist")
+	}
+	return service.ViewRepository.DeleteView(ctx, viewId)
+}
+
+func (service *Service) FetchView(ctx context.Context, viewId uint, userId uint) (*database.View, error) {
+	view, err := service.ViewRepository.FindViewByUserId(ctx, viewId, userId)
+	if err != nil {
+		return nil, errors.New("view does not exist")
+	}
+	return view, nil
+}
+
+// FetchDefaultView returns the view the user marked as their default list, or an empty view listing every todo
+// when they have not marked one.
+func (service *Service) FetchDefaultView(ctx context.Context, userId uint) *database.View {
+	view, err := service.ViewRepository.FindDefaultView(ctx, userId)
+	if err != nil {
+		return &database.View{UserID: userId}
+	}
+	return view
+}
+
+func (service *Service) FetchViews(ctx context.Context, userId uint) ([]database.View, error) {
+	return service.ViewRepository.FetchViews(ctx, userId)
+}
+
+func (service *Service) MoveView(ctx context.Context, viewId uint, reorderDto *dtos.ReorderDTO, userId uint) error {
+	_, err := service.ViewRepository.FindViewByUserId(ctx, viewId, userId)
+	if err != nil {
+		return errors.New("view does not exist")
+	}
+
+	if (reorderDto.AfterID != nil && *reorderDto.AfterID == viewId) || (reorderDto.BeforeID != nil && *reorderDto.BeforeID == viewId) {
+		return errors.New("a view cannot be moved next to itself")
+	}
+	return service.ViewRepository.MoveView(ctx, viewId, userId, reorderDto.AfterID, reorderDto.BeforeID)
+}
+
+// normaliseView tidies a view before it is saved, checks its labels belong to the user saving it and checks its
+// todos can be listed by the filters and sort it was given.
+func (service *Service) normaliseView(ctx context.Context, saveViewDto *dtos.SaveViewDTO) error {
+	saveViewDto.Name = strings.TrimSpace(saveViewDto.Name)
+	saveViewDto.Search = strings.TrimSpace(saveViewDto.Search)
+
+	labelIds, err := service.LabelService.ValidateUserLabels(ctx, saveViewDto.LabelIDs, saveViewDto.UserID)
+	if err != nil {
+		return err
+	}
+	saveViewDto.LabelIDs = labelIds
+
+	view := database.View{
+		Filters:  saveViewDto.Filters,
+		SortBy:   saveViewDto.SortBy,
+		Order:    saveViewDto.Order,
+		Search:   saveViewDto.Search,
+		LabelIDs: saveViewDto.LabelIDs,
+	}
+	return service.TodoService.ValidateTodoQuery(view.Query())
+}
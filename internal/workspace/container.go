@@ -0,0 +1,39 @@
+package workspace
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"gorm.io/gorm"
+)
+
+type Container struct {
+	WorkspaceService *Service
+	WorkspaceHandler *Handler
+	SSEService       *sse.Service
+}
+
+func NewContainer(db *gorm.DB, bus pkg.EventBus, sseService *sse.Service) *Container {
+	workspaceService := NewService(
+		database.NewWorkspaceRepository(db),
+		database.NewUserRepository(db),
+		database.NewTokenBlacklistRepository(db),
+		database.NewSessionRepository(db),
+		bus,
+	)
+
+	return &Container{
+		WorkspaceService: workspaceService,
+		WorkspaceHandler: NewHandler(workspaceService),
+		SSEService:       sseService,
+	}
+}
+
+func (uc *Container) RegisterRoutes(r chi.Router) {
+	uc.WorkspaceHandler.RegisterRoutes(r)
+}
+
+func (uc *Container) RegisterListeners(bus pkg.EventBus) {
+	bus.Subscribe("workspace.member_added", NewWorkspaceMemberAddedListener(uc.SSEService))
+}
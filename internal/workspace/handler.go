@@ -0,0 +1,205 @@
+package workspace
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/middlewares"
+	"github.com/horlerdipo/todo-golang/utils"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	WorkspaceService *Service
+}
+
+func NewHandler(workspaceService *Service) *Handler {
+	return &Handler{
+		WorkspaceService: workspaceService,
+	}
+}
+
+func (handler *Handler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	jsonRequest, err := utils.JsonValidate[dtos.CreateWorkspaceDTO](w, r)
+	if err != nil {
+		return
+	}
+
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+	jsonRequest.OwnerID = authDetails.UserId
+
+	workspaceId, err := handler.WorkspaceService.CreateWorkspace(r.Context(), &jsonRequest)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.RespondWithSuccess(w, http.StatusCreated, "Workspace created successfully", map[string]uint{"id": workspaceId})
+}
+
+func (handler *Handler) FetchWorkspaces(w http.ResponseWriter, r *http.Request) {
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	workspaces, err := handler.WorkspaceService.FetchWorkspaces(r.Context(), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Workspaces fetched successfully", workspaces)
+}
+
+func (handler *Handler) FetchWorkspace(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	workspace, err := handler.WorkspaceService.FetchWorkspace(r.Context(), uint(workspaceId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Workspace fetched successfully", workspace)
+}
+
+func (handler *Handler) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.UpdateWorkspaceDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.WorkspaceService.UpdateWorkspace(r.Context(), uint(workspaceId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) DeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.WorkspaceService.DeleteWorkspace(r.Context(), uint(workspaceId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) FetchMembers(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	members, err := handler.WorkspaceService.FetchMembers(r.Context(), uint(workspaceId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusOK, "Members fetched successfully", members)
+}
+
+func (handler *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.AddWorkspaceMemberDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.WorkspaceService.AddMember(r.Context(), uint(workspaceId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	utils.RespondWithSuccess(w, http.StatusCreated, "Member added successfully", nil)
+}
+
+func (handler *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+
+	memberId, err := strconv.ParseUint(chi.URLParam(r, "userId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "member not found", nil)
+		return
+	}
+
+	jsonRequest, err := utils.JsonValidate[dtos.UpdateWorkspaceMemberDTO](w, r)
+	if err != nil {
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.WorkspaceService.UpdateMemberRole(r.Context(), uint(workspaceId), uint(memberId), &jsonRequest, authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	workspaceId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "workspace not found", nil)
+		return
+	}
+
+	memberId, err := strconv.ParseUint(chi.URLParam(r, "userId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "member not found", nil)
+		return
+	}
+	authDetails := r.Context().Value(middlewares.UserKey).(middlewares.AuthDetails)
+
+	err = handler.WorkspaceService.RemoveMember(r.Context(), uint(workspaceId), uint(memberId), authDetails.UserId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/workspaces", func(r chi.Router) {
+		r.Use(middlewares.JwtAuthMiddleware(handler.WorkspaceService.TokenBlacklistRepository, handler.WorkspaceService.SessionRepository))
+		r.Post("/", handler.CreateWorkspace)
+		r.Get("/", handler.FetchWorkspaces)
+		r.Get("/{id}", handler.FetchWorkspace)
+		r.Patch("/{id}", handler.UpdateWorkspace)
+		r.Delete("/{id}", handler.DeleteWorkspace)
+
+		//Members
+		r.Get("/{id}/members", handler.FetchMembers)
+		r.Post("/{id}/members", handler.AddMember)
+		r.Patch("/{id}/members/{userId}", handler.UpdateMember)
+		r.Delete("/{id}/members/{userId}", handler.RemoveMember)
+	})
+}
@@ -0,0 +1,31 @@
+package workspace
+
+import (
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+)
+
+// WorkspaceMemberAddedListener tells a user's clients that they have been added to a workspace.
+type WorkspaceMemberAddedListener struct {
+	SSEService *sse.Service
+}
+
+func (listener *WorkspaceMemberAddedListener) Handle(event pkg.Event) {
+	e := event.(*events.WorkspaceMemberAddedEvent)
+	message := dtos.SSEData{
+		Event: dtos.WorkspaceJoined,
+		Data: map[string]uint{
+			"workspace_id": e.WorkspaceId,
+			"added_by":     e.AddedById,
+		},
+	}
+	listener.SSEService.SendMessage(e.UserId, message)
+}
+
+func NewWorkspaceMemberAddedListener(sseService *sse.Service) *WorkspaceMemberAddedListener {
+	return &WorkspaceMemberAddedListener{
+		SSEService: sseService,
+	}
+}
@@ -0,0 +1,190 @@
+package workspace
+
+import (
+	"errors"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"golang.org/x/net/context"
+	"log"
+	"strings"
+)
+
+type Service struct {
+	WorkspaceRepository      database.WorkspaceRepository
+	UserRepository           database.UserRepository
+	TokenBlacklistRepository database.TokenBlacklistRepository
+	SessionRepository        database.SessionRepository
+	EventBus                 pkg.EventBus
+}
+
+func NewService(workspaceRepository database.WorkspaceRepository, userRepository database.UserRepository, blacklistRepository database.TokenBlacklistRepository, sessionRepository database.SessionRepository, eventBus pkg.EventBus) *Service {
+	return &Service{
+		WorkspaceRepository:      workspaceRepository,
+		UserRepository:           userRepository,
+		TokenBlacklistRepository: blacklistRepository,
+		SessionRepository:        sessionRepository,
+		EventBus:                 eventBus,
+	}
+}
+
+func (service *Service) CreateWorkspace(ctx context.Context, createWorkspaceDto *dtos.CreateWorkspaceDTO) (uint, error) {
+	createWorkspaceDto.Name = strings.TrimSpace(createWorkspaceDto.Name)
+	workspaceId, err := service.WorkspaceRepository.CreateWorkspace(ctx, createWorkspaceDto)
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New("unable to create workspace, please try again")
+	}
+	return workspaceId, nil
+}
+
+func (service *Service) FetchWorkspaces(ctx context.Context, userId uint) ([]database.Workspace, error) {
+	return service.WorkspaceRepository.FetchWorkspaces(ctx, userId)
+}
+
+func (service *Service) FetchWorkspace(ctx context.Context, workspaceId uint, userId uint) (*database.Workspace, error) {
+	workspace, err := service.WorkspaceRepository.FindWorkspaceForUser(ctx, workspaceId, userId)
+	if err != nil {
+		return nil, errors.New("workspace does not exist")
+	}
+	return workspace, nil
+}
+
+func (service *Service) UpdateWorkspace(ctx context.Context, workspaceId uint, updateWorkspaceDto *dtos.UpdateWorkspaceDTO, userId uint) error {
+	workspace, err := service.FetchWorkspace(ctx, workspaceId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !workspace.Role.CanManage() {
+		return errors.New("only owners and admins can update the workspace")
+	}
+
+	updateWorkspaceDto.Name = strings.TrimSpace(updateWorkspaceDto.Name)
+	return service.WorkspaceRepository.UpdateWorkspace(ctx, workspaceId, updateWorkspaceDto)
+}
+
+// DeleteWorkspace deletes a workspace, handing its todos back to whoever created them.
+func (service *Service) DeleteWorkspace(ctx context.Context, workspaceId uint, userId uint) error {
+	workspace, err := service.FetchWorkspace(ctx, workspaceId, userId)
+	if err != nil {
+		return err
+	}
+
+	if workspace.Role != enums.WorkspaceOwner {
+		return errors.New("only the owner can delete the workspace")
+	}
+	return service.WorkspaceRepository.DeleteWorkspace(ctx, workspaceId)
+}
+
+func (service *Service) FetchMembers(ctx context.Context, workspaceId uint, userId uint) ([]dtos.WorkspaceMember, error) {
+	_, err := service.FetchWorkspace(ctx, workspaceId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := service.WorkspaceRepository.FetchMembers(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceMembers := make([]dtos.WorkspaceMember, 0, len(members))
+	for _, member := range members {
+		workspaceMembers = append(workspaceMembers, dtos.WorkspaceMember{
+			UserID:    member.UserID,
+			FirstName: member.User.FirstName,
+			LastName:  member.User.LastName,
+			Email:     member.User.Email,
+			Role:      member.Role,
+		})
+	}
+	return workspaceMembers, nil
+}
+
+// AddMember adds the user with the given email to a workspace. Admins can add members, only the owner can add admins.
+func (service *Service) AddMember(ctx context.Context, workspaceId uint, addMemberDto *dtos.AddWorkspaceMemberDTO, userId uint) error {
+	workspace, err := service.FetchWorkspace(ctx, workspaceId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !workspace.Role.CanManage() {
+		return errors.New("only owners and admins can add members")
+	}
+
+	if addMemberDto.Role == enums.WorkspaceAdmin && workspace.Role != enums.WorkspaceOwner {
+		return errors.New("only the owner can add admins")
+	}
+
+	user, err := service.UserRepository.FindUserByEmail(ctx, strings.TrimSpace(addMemberDto.Email))
+	if err != nil {
+		return errors.New("user does not exist")
+	}
+
+	_, err = service.WorkspaceRepository.FindMember(ctx, workspaceId, user.ID)
+	if err == nil {
+		return errors.New("user is already a member of this workspace")
+	}
+
+	err = service.WorkspaceRepository.AddMember(ctx, workspaceId, user.ID, addMemberDto.Role)
+	if err != nil {
+		return err
+	}
+
+	service.EventBus.Publish(&events.WorkspaceMemberAddedEvent{
+		WorkspaceId: workspaceId,
+		UserId:      user.ID,
+		AddedById:   userId,
+	})
+	return nil
+}
+
+// UpdateMemberRole promotes a member to admin or demotes an admin to member. Only the owner can change roles.
+func (service *Service) UpdateMemberRole(ctx context.Context, workspaceId uint, memberId uint, updateMemberDto *dtos.UpdateWorkspaceMemberDTO, userId uint) error {
+	workspace, err := service.FetchWorkspace(ctx, workspaceId, userId)
+	if err != nil {
+		return err
+	}
+
+	if workspace.Role != enums.WorkspaceOwner {
+		return errors.New("only the owner can change member roles")
+	}
+
+	member, err := service.WorkspaceRepository.FindMember(ctx, workspaceId, memberId)
+	if err != nil {
+		return errors.New("member not found")
+	}
+
+	if member.Role == enums.WorkspaceOwner {
+		return errors.New("the owner's role cannot be changed")
+	}
+	return service.WorkspaceRepository.UpdateMemberRole(ctx, workspaceId, memberId, updateMemberDto.Role)
+}
+
+// RemoveMember removes a member from a workspace. Members can leave on their own, owners and admins can remove
+// members, and only the owner can remove admins. The owner can never be removed.
+func (service *Service) RemoveMember(ctx context.Context, workspaceId uint, memberId uint, userId uint) error {
+	workspace, err := service.FetchWorkspace(ctx, workspaceId, userId)
+	if err != nil {
+		return err
+	}
+
+	member, err := service.WorkspaceRepository.FindMember(ctx, workspaceId, memberId)
+	if err != nil {
+		return errors.New("member not found")
+	}
+
+	switch {
+	case member.Role == enums.WorkspaceOwner:
+		return errors.New("the owner cannot be removed from the workspace")
+	case memberId == userId:
+	case !workspace.Role.CanManage():
+		return errors.New("only owners and admins can remove members")
+	case member.Role == enums.WorkspaceAdmin && workspace.Role != enums.WorkspaceOwner:
+		return errors.New("only the owner can remove admins")
+	}
+
+	return service.WorkspaceRepository.RemoveMember(ctx, workspaceId, memberId)
+}
@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+)
+
+// rankAlphabet is ordered the same way in every collation, so ranks sort correctly even where string
+// comparison is case-insensitive.
+const rankAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const rankBase = len(rankAlphabet)
+
+// ErrNoRankBetween is returned when two ranks are equal, or in the wrong order, so nothing fits between them.
+var ErrNoRankBetween = errors.New("there is no rank between the given ranks")
+
+// RankBetween returns a rank that sorts strictly between before and after. Ranks are read as base 36
+// fractions, so there is always room for another one between two different ranks and moving an item only
+// ever rewrites that item. An empty before means the start of the list, an empty after means the end.
+func RankBetween(before string, after string) (string, error) {
+	if !validRank(before) || !validRank(after) {
+		return "", errors.New("invalid rank")
+	}
+
+	if after != "" && before >= after {
+		return "", ErrNoRankBetween
+	}
+
+	var rank strings.Builder
+	bounded := after != ""
+	for i := 0; ; i++ {
+		low := 0
+		if i < len(before) {
+			low = strings.IndexByte(rankAlphabet, before[i])
+		}
+
+		high := rankBase
+		if bounded {
+			if i >= len(after) {
+				return "", ErrNoRankBetween
+			}
+			high = strings.IndexByte(rankAlphabet, after[i])
+		}
+
+		if low == high {
+			rank.WriteByte(rankAlphabet[low])
+			continue
+		}
+
+		middle := (low + high) / 2
+		if middle > low {
+			rank.WriteByte(rankAlphabet[middle])
+			return rank.String(), nil
+		}
+
+		//the digits are adjacent, so keep low and find room in the next digit where after no longer applies
+		rank.WriteByte(rankAlphabet[low])
+		bounded = false
+	}
+}
+
+// SpreadRanks returns count ascending ranks spaced evenly across the whole range, as short as they can be.
+// It is used to rebalance a list whose ranks have collided.
+func SpreadRanks(count int) []string {
+	width := 1
+	space := rankBase
+	for space <= count {
+		width++
+		space *= rankBase
+	}
+
+	ranks := make([]string, 0, count)
+	for i := 1; i <= count; i++ {
+		value := i * space / (count + 1)
+		digits := make([]byte, width)
+		for d := width - 1; d >= 0; d-- {
+			digits[d] = rankAlphabet[value%rankBase]
+			value /= rankBase
+		}
+		ranks = append(ranks, strings.TrimRight(string(digits), "0"))
+	}
+	return ranks
+}
+
+func validRank(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankAlphabet, rank[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rank, "0")
+}
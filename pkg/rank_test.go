@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		before    string
+		after     string
+		wantError error
+	}{
+		{before: "", after: ""},
+		{before: "i", after: ""},
+		{before: "", after: "i"},
+		{before: "a", after: "b"},
+		{before: "a", after: "a1"},
+		{before: "az", after: "b"},
+		{before: "zzz", after: ""},
+		{before: "", after: "001"},
+		{before: "b", after: "a", wantError: ErrNoRankBetween},
+		{before: "a", after: "a", wantError: ErrNoRankBetween},
+	}
+
+	for _, tt := range tests {
+		rank, err := RankBetween(tt.before, tt.after)
+		if tt.wantError != nil {
+			if !errors.Is(err, tt.wantError) {
+				t.Errorf("RankBetween(%q, %q) should have returned %v, got %v", tt.before, tt.after, tt.wantError, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("RankBetween(%q, %q) returned unexpected error: %v", tt.before, tt.after, err)
+			continue
+		}
+
+		if rank <= tt.before || (tt.after != "" && rank >= tt.after) {
+			t.Errorf("expected RankBetween(%q, %q) to sort between them, got %q", tt.before, tt.after, rank)
+		}
+	}
+
+	if _, err := RankBetween("A", ""); err == nil {
+		t.Error("RankBetween with a rank outside the alphabet should have been an error")
+	}
+}
+
+// test that repeatedly inserting at the same spot keeps finding room
+func TestRankBetween_RepeatedInserts(t *testing.T) {
+	t.Parallel()
+
+	before, after := "a", "b"
+	for i := 0; i < 200; i++ {
+		rank, err := RankBetween(before, after)
+		if err != nil {
+			t.Fatalf("insert %d between %q and %q failed: %v", i, before, after, err)
+		}
+		after = rank
+	}
+}
+
+func TestSpreadRanks(t *testing.T) {
+	t.Parallel()
+
+	for _, count := range []int{1, 5, 35, 36, 500} {
+		ranks := SpreadRanks(count)
+		if len(ranks) != count {
+			t.Fatalf("expected %d ranks, got %d", count, len(ranks))
+		}
+
+		if !sort.StringsAreSorted(ranks) {
+			t.Errorf("expected ranks for %d items to be sorted", count)
+		}
+
+		for i := 1; i < len(ranks); i++ {
+			if ranks[i] == ranks[i-1] {
+				t.Errorf("expected ranks for %d items to be unique, %q repeats", count, ranks[i])
+			}
+		}
+
+		if _, err := RankBetween("", ranks[0]); err != nil {
+			t.Errorf("expected room before the first of %d ranks: %v", count, err)
+		}
+	}
+}
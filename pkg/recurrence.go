@@ -0,0 +1,238 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	Daily   RecurrenceFrequency = "DAILY"
+	Weekly  RecurrenceFrequency = "WEEKLY"
+	Monthly RecurrenceFrequency = "MONTHLY"
+	Yearly  RecurrenceFrequency = "YEARLY"
+)
+
+// maxRecurrenceLookahead bounds how many candidate periods Next inspects before giving up,
+// e.g. a MONTHLY rule on the 31st only needs to skip a handful of short months.
+const maxRecurrenceLookahead = 400
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var recurrenceUntilLayouts = []string{
+	"20060102T150405Z",
+	"20060102T150405",
+	"20060102",
+}
+
+// RecurrenceRule is the subset of an iCalendar RRULE (RFC 5545) that todos support:
+// FREQ, INTERVAL, BYDAY (DAILY and WEEKLY only), UNTIL and COUNT.
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency
+	Interval  int
+	Weekdays  []time.Weekday
+	Until     *time.Time
+	Count     int
+}
+
+func ParseRecurrenceRule(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, errors.New("recurrence rule is empty")
+	}
+
+	recurrence := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part: %s", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			frequency := RecurrenceFrequency(strings.ToUpper(value))
+			if frequency != Daily && frequency != Weekly && frequency != Monthly && frequency != Yearly {
+				return nil, fmt.Errorf("unsupported recurrence frequency: %s", value)
+			}
+			recurrence.Frequency = frequency
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval: %s", value)
+			}
+			recurrence.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := recurrenceWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("invalid recurrence weekday: %s", day)
+				}
+				recurrence.Weekdays = append(recurrence.Weekdays, weekday)
+			}
+		case "UNTIL":
+			until, err := parseRecurrenceUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			recurrence.Until = &until
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid recurrence count: %s", value)
+			}
+			recurrence.Count = count
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part: %s", key)
+		}
+	}
+
+	if recurrence.Frequency == "" {
+		return nil, errors.New("recurrence rule must have a FREQ")
+	}
+
+	if recurrence.Until != nil && recurrence.Count > 0 {
+		return nil, errors.New("recurrence rule cannot have both UNTIL and COUNT")
+	}
+
+	if len(recurrence.Weekdays) > 0 && recurrence.Frequency != Daily && recurrence.Frequency != Weekly {
+		return nil, errors.New("BYDAY is only supported for DAILY and WEEKLY recurrences")
+	}
+
+	return recurrence, nil
+}
+
+func parseRecurrenceUntil(value string) (time.Time, error) {
+	for _, layout := range recurrenceUntilLayouts {
+		location := time.Local
+		if strings.HasSuffix(layout, "Z") {
+			location = time.UTC
+		}
+
+		until, err := time.ParseInLocation(layout, value, location)
+		if err != nil {
+			continue
+		}
+
+		// a date-only UNTIL includes the whole day
+		if layout == "20060102" {
+			until = until.Add(24*time.Hour - time.Second)
+		}
+		return until, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid recurrence until: %s", value)
+}
+
+// String renders the rule back into its canonical RRULE form.
+func (rule *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(rule.Frequency)}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+
+	if len(rule.Weekdays) > 0 {
+		days := make([]string, 0, len(rule.Weekdays))
+		for _, weekday := range rule.Weekdays {
+			days = append(days, strings.ToUpper(weekday.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if rule.Until != nil {
+		parts = append(parts, "UNTIL="+rule.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	if rule.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence that follows previous, where occurrence is the 1-based position
+// of previous in the series. It returns false once the series is exhausted by COUNT or UNTIL.
+func (rule *RecurrenceRule) Next(previous time.Time, occurrence int) (time.Time, bool) {
+	if rule.Count > 0 && occurrence >= rule.Count {
+		return time.Time{}, false
+	}
+
+	next, ok := rule.next(previous)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if rule.Until != nil && next.After(*rule.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (rule *RecurrenceRule) next(previous time.Time) (time.Time, bool) {
+	switch rule.Frequency {
+	case Daily:
+		for i := 1; i <= maxRecurrenceLookahead; i++ {
+			candidate := previous.AddDate(0, 0, i*rule.Interval)
+			if rule.matchesWeekday(candidate) {
+				return candidate, true
+			}
+		}
+	case Weekly:
+		if len(rule.Weekdays) == 0 {
+			return previous.AddDate(0, 0, 7*rule.Interval), true
+		}
+
+		weekStart := mondayOf(previous)
+		for i := 1; i <= 7*rule.Interval+7; i++ {
+			candidate := previous.AddDate(0, 0, i)
+			weeksApart := int(mondayOf(candidate).Sub(weekStart).Hours()+12) / (24 * 7)
+			if weeksApart%rule.Interval == 0 && rule.matchesWeekday(candidate) {
+				return candidate, true
+			}
+		}
+	case Monthly:
+		// months that do not have the day (e.g. the 31st) are skipped, as RFC 5545 requires
+		for i := 1; i <= maxRecurrenceLookahead; i++ {
+			candidate := previous.AddDate(0, i*rule.Interval, 0)
+			if candidate.Day() == previous.Day() {
+				return candidate, true
+			}
+		}
+	case Yearly:
+		for i := 1; i <= maxRecurrenceLookahead; i++ {
+			candidate := previous.AddDate(i*rule.Interval, 0, 0)
+			if candidate.Day() == previous.Day() {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (rule *RecurrenceRule) matchesWeekday(t time.Time) bool {
+	if len(rule.Weekdays) == 0 {
+		return true
+	}
+
+	for _, weekday := range rule.Weekdays {
+		if t.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func mondayOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	return midnight.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
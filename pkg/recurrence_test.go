@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule      string
+		canonical string
+		wantError bool
+	}{
+		{rule: "FREQ=DAILY", canonical: "FREQ=DAILY"},
+		{rule: "RRULE:FREQ=weekly;INTERVAL=2;BYDAY=MO,we", canonical: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{rule: "FREQ=MONTHLY;COUNT=3", canonical: "FREQ=MONTHLY;COUNT=3"},
+		{rule: "FREQ=YEARLY;UNTIL=20301231T000000Z", canonical: "FREQ=YEARLY;UNTIL=20301231T000000Z"},
+		{rule: "", wantError: true},
+		{rule: "INTERVAL=2", wantError: true},
+		{rule: "FREQ=HOURLY", wantError: true},
+		{rule: "FREQ=DAILY;INTERVAL=0", wantError: true},
+		{rule: "FREQ=WEEKLY;BYDAY=XX", wantError: true},
+		{rule: "FREQ=MONTHLY;BYDAY=MO", wantError: true},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20301231", wantError: true},
+		{rule: "FREQ=DAILY;BYMONTH=2", wantError: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseRecurrenceRule(tt.rule)
+		if tt.wantError {
+			if err == nil {
+				t.Errorf("ParseRecurrenceRule(%q) should have been an error", tt.rule)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseRecurrenceRule(%q) returned unexpected error: %v", tt.rule, err)
+			continue
+		}
+
+		if rule.String() != tt.canonical {
+			t.Errorf("expected ParseRecurrenceRule(%q) to render as %q, got %q", tt.rule, tt.canonical, rule.String())
+		}
+	}
+}
+
+func TestRecurrenceRule_Next(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday
+	start := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule       string
+		previous   time.Time
+		occurrence int
+		expected   time.Time
+		exhausted  bool
+	}{
+		{rule: "FREQ=DAILY", previous: start, expected: time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=DAILY;INTERVAL=3", previous: start, expected: time.Date(2025, time.January, 4, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", previous: time.Date(2025, time.January, 3, 9, 0, 0, 0, time.UTC), expected: time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=WEEKLY", previous: start, expected: time.Date(2025, time.January, 8, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,FR", previous: start, expected: time.Date(2025, time.January, 3, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", previous: time.Date(2025, time.January, 3, 9, 0, 0, 0, time.UTC), expected: time.Date(2025, time.January, 13, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=MONTHLY", previous: start, expected: time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=MONTHLY", previous: time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC), expected: time.Date(2025, time.March, 31, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=YEARLY", previous: time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), expected: time.Date(2028, time.February, 29, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=DAILY;COUNT=3", previous: start, occurrence: 2, expected: time.Date(2025, time.January, 2, 9, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=DAILY;COUNT=3", previous: start, occurrence: 3, exhausted: true},
+		{rule: "FREQ=WEEKLY;UNTIL=20250105", previous: start, exhausted: true},
+		{rule: "FREQ=WEEKLY;UNTIL=20250108", previous: start, expected: time.Date(2025, time.January, 8, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseRecurrenceRule(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseRecurrenceRule(%q) returned unexpected error: %v", tt.rule, err)
+		}
+
+		occurrence := tt.occurrence
+		if occurrence == 0 {
+			occurrence = 1
+		}
+
+		next, ok := rule.Next(tt.previous, occurrence)
+		if tt.exhausted {
+			if ok {
+				t.Errorf("expected %q to be exhausted after %v, got %v", tt.rule, tt.previous, next)
+			}
+			continue
+		}
+
+		if !ok {
+			t.Errorf("expected %q to have an occurrence after %v", tt.rule, tt.previous)
+			continue
+		}
+
+		if !next.Equal(tt.expected) {
+			t.Errorf("expected %q after %v to be %v, got %v", tt.rule, tt.previous, tt.expected, next)
+		}
+	}
+}
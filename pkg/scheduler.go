@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultJobInterval is used for jobs registered with an interval that is not positive, which a ticker cannot run on.
+const defaultJobInterval = time.Minute
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context)
+}
+
+type Scheduler interface {
+	Register(name string, interval time.Duration, run func(ctx context.Context))
+	Start(ctx context.Context)
+}
+
+type SchedulerImpl struct {
+	jobs    []Job
+	mutex   sync.Mutex
+	started bool
+}
+
+func (scheduler *SchedulerImpl) Register(name string, interval time.Duration, run func(ctx context.Context)) {
+	if interval <= 0 {
+		log.Printf("Job %s cannot run every %v, running it every %v instead", name, interval, defaultJobInterval)
+		interval = defaultJobInterval
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	scheduler.jobs = append(scheduler.jobs, Job{
+		Name:     name,
+		Interval: interval,
+		Run:      run,
+	})
+	log.Printf("Registering %s job to run every %v", name, interval)
+}
+
+// Start runs every registered job once straight away, so work missed while the process was down
+// is caught up on boot, and then on its interval until ctx is cancelled.
+func (scheduler *SchedulerImpl) Start(ctx context.Context) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if scheduler.started {
+		return
+	}
+	scheduler.started = true
+
+	for _, job := range scheduler.jobs {
+		go scheduler.run(ctx, job)
+	}
+}
+
+func (scheduler *SchedulerImpl) run(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (scheduler *SchedulerImpl) runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Job %s panicked: %v", job.Name, recovered)
+		}
+	}()
+	job.Run(ctx)
+}
+
+func NewScheduler() Scheduler {
+	return &SchedulerImpl{}
+}
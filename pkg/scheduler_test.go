@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// test that Register adds the job without running it
+func TestSchedulerImpl_Register(t *testing.T) {
+	t.Parallel()
+
+	scheduler := NewScheduler().(*SchedulerImpl)
+	var runs atomic.Int32
+	scheduler.Register("counter", time.Hour, func(ctx context.Context) {
+		runs.Add(1)
+	})
+
+	if len(scheduler.jobs) != 1 {
+		t.Fatalf("expected 1 registered job, got %v", len(scheduler.jobs))
+	}
+
+	if runs.Load() != 0 {
+		t.Errorf("expected job not to run before Start, ran %v times", runs.Load())
+	}
+}
+
+// test that jobs registered with an interval a ticker cannot run on fall back to the default interval
+func TestSchedulerImpl_RegisterInvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	scheduler := NewScheduler().(*SchedulerImpl)
+	scheduler.Register("zero", 0, func(ctx context.Context) {})
+	scheduler.Register("negative", -time.Second, func(ctx context.Context) {})
+
+	for _, job := range scheduler.jobs {
+		if job.Interval != defaultJobInterval {
+			t.Errorf("expected job %s to run every %v, got %v", job.Name, defaultJobInterval, job.Interval)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduler.Start(ctx)
+}
+
+// test that Start runs jobs immediately, then on their interval, and stops when the context is cancelled
+func TestSchedulerImpl_Start(t *testing.T) {
+	t.Parallel()
+
+	scheduler := NewScheduler()
+	runs := make(chan struct{}, 10)
+	scheduler.Register("counter", 10*time.Millisecond, func(ctx context.Context) {
+		runs <- struct{}{}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Start(ctx)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("expected job to have run %v times", i+1)
+		}
+	}
+
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	for len(runs) > 0 {
+		<-runs
+	}
+	time.Sleep(30 * time.Millisecond)
+	if len(runs) != 0 {
+		t.Errorf("expected job to stop running after the context was cancelled")
+	}
+}
+
+// test that a panicking job does not stop the scheduler
+func TestSchedulerImpl_StartRecoversPanics(t *testing.T) {
+	t.Parallel()
+
+	scheduler := NewScheduler()
+	var runs atomic.Int32
+	scheduler.Register("panicking", 10*time.Millisecond, func(ctx context.Context) {
+		runs.Add(1)
+		panic("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduler.Start(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if runs.Load() < 2 {
+		t.Errorf("expected panicking job to keep running, ran %v times", runs.Load())
+	}
+}
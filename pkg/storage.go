@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidStorageKey is returned for keys that are empty or would escape the storage root.
+var ErrInvalidStorageKey = errors.New("invalid storage key")
+
+// Storage keeps blobs, such as uploaded files, under slash separated keys like "todos/1/abc.png".
+type Storage interface {
+	// Put writes the contents of reader under key, replacing anything already there, and returns the bytes written.
+	Put(ctx context.Context, key string, reader io.Reader) (int64, error)
+	// Open returns the blob under key. It is seekable so it can be served with range requests.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the blob under key. Deleting a key that does not exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStorage keeps blobs as files under Root on the local disk.
+type LocalStorage struct {
+	Root string
+}
+
+func (storage *LocalStorage) Put(ctx context.Context, key string, reader io.Reader) (int64, error) {
+	path, err := storage.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	//write to a temporary file first so a failed upload never leaves half a blob behind
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+
+	written, err := io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return written, os.Rename(file.Name(), path)
+}
+
+func (storage *LocalStorage) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (storage *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (storage *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if key == "" || cleaned == "/" || strings.Contains(key, "..") {
+		return "", ErrInvalidStorageKey
+	}
+	return filepath.Join(storage.Root, filepath.FromSlash(cleaned)), nil
+}
+
+func NewLocalStorage(root string) Storage {
+	return &LocalStorage{Root: root}
+}
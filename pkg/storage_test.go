@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// test that a blob can be written, read back and deleted
+func TestLocalStorage_PutOpenDelete(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	storage := NewLocalStorage(root)
+	ctx := context.Background()
+
+	written, err := storage.Put(ctx, "todos/1/file.txt", strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("unexpected error while writing: %v", err)
+	}
+	if written != 11 {
+		t.Errorf("expected 11 bytes to be written, got %v", written)
+	}
+
+	file, err := storage.Open(ctx, "todos/1/file.txt")
+	if err != nil {
+		t.Fatalf("unexpected error while opening: %v", err)
+	}
+
+	if _, err = file.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("unexpected error while seeking: %v", err)
+	}
+	contents, _ := io.ReadAll(file)
+	file.Close()
+	if string(contents) != "world" {
+		t.Errorf("expected to read %q after seeking, got %q", "world", contents)
+	}
+
+	if err = storage.Delete(ctx, "todos/1/file.txt"); err != nil {
+		t.Fatalf("unexpected error while deleting: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(root, "todos", "1", "file.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the file to be removed from disk, got %v", err)
+	}
+
+	if err = storage.Delete(ctx, "todos/1/file.txt"); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+	}
+}
+
+// test that keys cannot reach outside the storage root
+func TestLocalStorage_InvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	storage := NewLocalStorage(t.TempDir())
+	for _, key := range []string{"", "/", "../secret", "todos/../../secret"} {
+		if _, err := storage.Put(context.Background(), key, strings.NewReader("x")); !errors.Is(err, ErrInvalidStorageKey) {
+			t.Errorf("expected key %q to be rejected, got %v", key, err)
+		}
+	}
+}
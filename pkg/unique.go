@@ -0,0 +1,14 @@
+package pkg
+
+// Unique returns values without repeats, keeping the first of each in the order they were given.
+func Unique[T comparable](values []T) []T {
+	seen := make(map[T]bool, len(values))
+	unique := make([]T, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
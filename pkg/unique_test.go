@@ -0,0 +1,20 @@
+package pkg
+
+import (
+	"slices"
+	"testing"
+)
+
+// test that Unique drops repeats while keeping the order values first appeared in
+func TestUnique(t *testing.T) {
+	t.Parallel()
+
+	got := Unique([]uint{3, 1, 3, 2, 1})
+	if want := []uint{3, 1, 2}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := Unique([]int64(nil)); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty slice, got %#v", got)
+	}
+}
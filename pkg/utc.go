@@ -0,0 +1,13 @@
+package pkg
+
+import "time"
+
+// UTC returns t in UTC, or nil when t is nil. Times are stored in UTC because SQLite compares them as text, which
+// only orders them correctly when they all carry the same offset.
+func UTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
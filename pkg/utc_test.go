@@ -0,0 +1,24 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+// test that UTC keeps the instant while moving it to UTC, and leaves nil alone
+func TestUTC(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 18, 1, 58, 0, 0, time.FixedZone("+05:00", 5*60*60))
+	got := UTC(&at)
+	if got.Location() != time.UTC || !got.Equal(at) {
+		t.Errorf("expected %v in UTC, got %v", at, got)
+	}
+	if got.Hour() != 20 || got.Day() != 17 {
+		t.Errorf("expected 2026-10-17 20:58 UTC, got %v", got)
+	}
+
+	if got := UTC(nil); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
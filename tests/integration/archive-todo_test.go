@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestArchiveTodo(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		archived           bool
+		otherUser          bool
+		expectedStatusCode int
+		expectedMsg        string
+		expectArchived     bool
+	}{
+		{
+			name:               "archive",
+			path:               "archive",
+			expectedStatusCode: http.StatusOK,
+			expectArchived:     true,
+		},
+		{
+			name:               "unarchive",
+			path:               "unarchive",
+			archived:           true,
+			expectedStatusCode: http.StatusOK,
+			expectArchived:     false,
+		},
+		{
+			name:               "archiving another user's todo",
+			path:               "archive",
+			otherUser:          true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "todo does not exist",
+			expectArchived:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			owner := user
+			if tt.otherUser {
+				owner = SeedUser(t, database.User{Email: "another@gmail.com"})
+			}
+
+			input := database.Todo{Pinned: true}
+			if tt.archived {
+				archivedAt := time.Now()
+				input = database.Todo{ArchivedAt: &archivedAt}
+			}
+			todo := SeedTodo(t, input, owner.ID)
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/%s", todo.ID, tt.path), authToken, nil)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			updatedTodo := database.Todo{}
+			require.NoError(t, TestServerInstance.DB.First(&updatedTodo, todo.ID).Error)
+			assert.Equal(t, tt.expectArchived, updatedTodo.ArchivedAt != nil)
+			if tt.expectArchived {
+				assert.False(t, updatedTodo.Pinned, "archiving should unpin the todo")
+			}
+		})
+	}
+}
+
+func TestPinTodo_ArchivedTodo(t *testing.T) {
+	user, authToken := setupTest(t)
+	archivedAt := time.Now()
+	todo := SeedTodo(t, database.Todo{ArchivedAt: &archivedAt}, user.ID)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/pin", todo.ID), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "archived todos cannot be pinned", response.Message)
+}
+
+func TestFetchTodos_ArchivedFilter(t *testing.T) {
+	user, authToken := setupTest(t)
+	archivedAt := time.Now()
+	activeTodo := SeedTodo(t, struct{}{}, user.ID)
+	archivedTodo := SeedTodo(t, database.Todo{ArchivedAt: &archivedAt}, user.ID)
+
+	tests := []struct {
+		description string
+		query       string
+		expectedIds []uint
+	}{
+		{
+			description: "archived todos are hidden by default",
+			query:       "",
+			expectedIds: []uint{activeTodo.ID},
+		},
+		{
+			description: "archived todos can be included",
+			query:       "filters[archived]=include",
+			expectedIds: []uint{activeTodo.ID, archivedTodo.ID},
+		},
+		{
+			description: "only archived todos",
+			query:       "filters[archived]=only",
+			expectedIds: []uint{archivedTodo.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			response := fetchTodos(t, authToken, tt.query)
+			assert.ElementsMatch(t, tt.expectedIds, todoIds(response.Data))
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+var registerAttachmentListeners sync.Once
+
+func uploadAttachment(t *testing.T, authToken string, todoId uint, fileName string, contents []byte) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/todos/%d/attachments", TestServerInstance.Server.URL, todoId), body)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	return resp, response
+}
+
+func downloadAttachment(t *testing.T, authToken string, todoId uint, attachmentId uint, rangeHeader string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/todos/%d/attachments/%d", TestServerInstance.Server.URL, todoId, attachmentId), nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func storedAttachment(t *testing.T, todoId uint) database.Attachment {
+	t.Helper()
+	attachment := database.Attachment{}
+	require.NoError(t, TestServerInstance.DB.Where("todo_id = ?", todoId).First(&attachment).Error)
+	return attachment
+}
+
+func blobPath(attachment database.Attachment) string {
+	return filepath.Join(env.FetchString("ATTACHMENT_STORAGE_PATH"), filepath.FromSlash(attachment.StorageKey))
+}
+
+func TestUploadAttachment(t *testing.T) {
+	png := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte("a"), 100)...)
+
+	tests := []struct {
+		name               string
+		fileName           string
+		contents           []byte
+		as                 enums.ShareRole
+		expectedStatusCode int
+		expectedMsg        string
+		expectedType       string
+	}{
+		{
+			name:               "png screenshot",
+			fileName:           "screenshot.png",
+			contents:           png,
+			as:                 enums.Owner,
+			expectedStatusCode: http.StatusCreated,
+			expectedType:       "image/png",
+		},
+		{
+			name:               "pdf uploaded by an editor",
+			fileName:           "spec.pdf",
+			contents:           []byte("%PDF-1.4\n%âãÏÓ\n1 0 obj\n"),
+			as:                 enums.Editor,
+			expectedStatusCode: http.StatusCreated,
+			expectedType:       "application/pdf",
+		},
+		{
+			name:               "content type is sniffed, not taken from the name",
+			fileName:           "script.png",
+			contents:           []byte("<html><script>alert(1)</script></html>"),
+			as:                 enums.Owner,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "files of type text/html cannot be attached",
+		},
+		{
+			name:               "too large",
+			fileName:           "huge.png",
+			contents:           append(append([]byte{}, pngHeader...), bytes.Repeat([]byte("a"), 65*1024)...),
+			as:                 enums.Owner,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "file is too large, attachments can be at most 64 KB",
+		},
+		{
+			name:               "viewers cannot upload",
+			fileName:           "screenshot.png",
+			contents:           png,
+			as:                 enums.Viewer,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "you do not have permission to edit this todo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, authToken := setupTest(t)
+			todo := SeedTodo(t, database.Todo{}, owner.ID)
+			if tt.as != enums.Owner {
+				collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com"})
+				shareTodo(t, todo.ID, collaborator.ID, tt.as)
+				authToken = GenerateTestJwtToken(t, collaborator.ID)
+			}
+
+			resp, response := uploadAttachment(t, authToken, todo.ID, tt.fileName, tt.contents)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response["message"])
+			}
+
+			if tt.expectedStatusCode != http.StatusCreated {
+				var count int64
+				TestServerInstance.DB.Model(&database.Attachment{}).Where("todo_id = ?", todo.ID).Count(&count)
+				assert.Zero(t, count)
+				return
+			}
+
+			attachment := storedAttachment(t, todo.ID)
+			assert.Equal(t, tt.fileName, attachment.FileName)
+			assert.Equal(t, tt.expectedType, attachment.ContentType)
+			assert.Equal(t, int64(len(tt.contents)), attachment.Size)
+
+			stored, err := os.ReadFile(blobPath(attachment))
+			require.NoError(t, err)
+			assert.Equal(t, tt.contents, stored)
+		})
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	owner, authToken := setupTest(t)
+	viewer := SeedUser(t, database.User{Email: "viewer@gmail.com"})
+	stranger := SeedUser(t, database.User{Email: "stranger@gmail.com"})
+	todo := SeedTodo(t, database.Todo{}, owner.ID)
+	shareTodo(t, todo.ID, viewer.ID, enums.Viewer)
+
+	contents := append(append([]byte{}, pngHeader...), []byte("0123456789")...)
+	resp, _ := uploadAttachment(t, authToken, todo.ID, "shot.png", contents)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	attachment := storedAttachment(t, todo.ID)
+
+	resp, body := downloadAttachment(t, GenerateTestJwtToken(t, viewer.ID), todo.ID, attachment.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename=shot.png`)
+	assert.Equal(t, contents, body)
+
+	resp, body = downloadAttachment(t, authToken, todo.ID, attachment.ID, "bytes=8-11")
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, fmt.Sprintf("bytes 8-11/%d", len(contents)), resp.Header.Get("Content-Range"))
+	assert.Equal(t, "0123", string(body))
+
+	resp, _ = downloadAttachment(t, GenerateTestJwtToken(t, stranger.ID), todo.ID, attachment.ID, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d/attachments", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	listed := response.Data.([]interface{})
+	require.Len(t, listed, 1)
+	assert.NotContains(t, listed[0], "storage_key")
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	owner, authToken := setupTest(t)
+	editor := SeedUser(t, database.User{Email: "editor@gmail.com"})
+	todo := SeedTodo(t, database.Todo{}, owner.ID)
+	shareTodo(t, todo.ID, editor.ID, enums.Editor)
+
+	resp, _ := uploadAttachment(t, authToken, todo.ID, "shot.png", pngHeader)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	attachment := storedAttachment(t, todo.ID)
+	path := fmt.Sprintf("/todos/%d/attachments/%d", todo.ID, attachment.ID)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, path, GenerateTestJwtToken(t, editor.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "you do not have permission to delete this attachment", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, path, authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, err := os.Stat(blobPath(attachment))
+	assert.True(t, os.IsNotExist(err), "the blob should be removed with the attachment")
+}
+
+func TestDeleteAttachment_WorkspaceAdminsModerate(t *testing.T) {
+	owner, _ := setupTest(t)
+	admin := SeedUser(t, database.User{Email: "admin@gmail.com"})
+	member := SeedUser(t, database.User{Email: "member@gmail.com"})
+	author := SeedUser(t, database.User{Email: "author@gmail.com"})
+	workspace := SeedWorkspace(t, owner.ID, map[uint]enums.WorkspaceRole{
+		admin.ID:  enums.WorkspaceAdmin,
+		member.ID: enums.WorkspaceMember,
+		author.ID: enums.WorkspaceMember,
+	})
+	todo := SeedTodo(t, database.Todo{WorkspaceID: &workspace.ID}, author.ID)
+
+	resp, _ := uploadAttachment(t, GenerateTestJwtToken(t, author.ID), todo.ID, "shot.png", pngHeader)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	attachment := storedAttachment(t, todo.ID)
+	path := fmt.Sprintf("/todos/%d/attachments/%d", todo.ID, attachment.ID)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, path, GenerateTestJwtToken(t, member.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "you do not have permission to delete this attachment", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, path, GenerateTestJwtToken(t, admin.ID), nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, err := os.Stat(blobPath(attachment))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPurgeTodo_RemovesAttachmentBlobs(t *testing.T) {
+	registerAttachmentListeners.Do(func() {
+		TestServerInstance.App.AttachmentContainer.RegisterListeners(TestServerInstance.App.EventBus)
+	})
+
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{}, user.ID)
+	resp, _ := uploadAttachment(t, authToken, todo.ID, "shot.png", pngHeader)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	attachment := storedAttachment(t, todo.ID)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	_, err := os.Stat(blobPath(attachment))
+	require.NoError(t, err, "trashing a todo should keep its attachments")
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/permanent", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(blobPath(attachment))
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+
+	var count int64
+	TestServerInstance.DB.Unscoped().Model(&database.Attachment{}).Where("todo_id = ?", todo.ID).Count(&count)
+	assert.Zero(t, count)
+	assert.Eventually(t, func() bool {
+		return orphanedBlobCount(t, attachment.StorageKey) == 0
+	}, time.Second, 10*time.Millisecond, "a removed blob should no longer be orphaned")
+}
+
+// failingStorage is storage that can no longer delete anything.
+type failingStorage struct {
+	pkg.Storage
+}
+
+func (failingStorage) Delete(context.Context, string) error {
+	return errors.New("storage is unavailable")
+}
+
+func orphanedBlobCount(t *testing.T, storageKey string) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, TestServerInstance.DB.Model(&database.OrphanedBlob{}).Where("storage_key = ?", storageKey).Count(&count).Error)
+	return count
+}
+
+func TestSweepOrphanedBlobs_RetriesFailedDeletions(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{}, user.ID)
+	resp, _ := uploadAttachment(t, authToken, todo.ID, "shot.png", pngHeader)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	attachment := storedAttachment(t, todo.ID)
+
+	attachmentService := TestServerInstance.App.AttachmentContainer.AttachmentService
+	storage := attachmentService.Storage
+	defer func() { attachmentService.Storage = storage }()
+
+	//the record goes, the blob is left orphaned until it can be removed from storage
+	require.NoError(t, attachmentService.AttachmentRepository.DeleteAttachment(context.Background(), attachment.ID))
+	assert.Equal(t, int64(1), orphanedBlobCount(t, attachment.StorageKey))
+
+	attachmentService.Storage = failingStorage{Storage: storage}
+	attachmentService.SweepOrphanedBlobs(context.Background())
+	_, err := os.Stat(blobPath(attachment))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), orphanedBlobCount(t, attachment.StorageKey))
+
+	attachmentService.Storage = storage
+	attachmentService.SweepOrphanedBlobs(context.Background())
+	_, err = os.Stat(blobPath(attachment))
+	assert.True(t, os.IsNotExist(err))
+	assert.Zero(t, orphanedBlobCount(t, attachment.StorageKey))
+}
@@ -0,0 +1,325 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func bulkUpdateTodos(t *testing.T, authToken string, request map[string]interface{}) []dtos.BulkTodoResult {
+	t.Helper()
+
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, TestServerInstance.Server.URL+"/todos/bulk", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response utils.JsonResponse[[]dtos.BulkTodoResult]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "Bulk action completed", response.Message)
+	return response.Data
+}
+
+func findTodo(t *testing.T, todoId uint) database.Todo {
+	t.Helper()
+
+	var todo database.Todo
+	require.NoError(t, TestServerInstance.DB.Unscoped().First(&todo, todoId).Error)
+	return todo
+}
+
+func TestBulkTodos_Validation(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, struct{}{}, user.ID)
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	otherLabel := SeedLabel(t, struct{}{}, other.ID)
+
+	tests := []struct {
+		name               string
+		request            map[string]interface{}
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "missing ids",
+			request:            map[string]interface{}{"action": "pin"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "unknown action",
+			request:            map[string]interface{}{"ids": []uint{todo.ID}, "action": "explode"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "add label without a label",
+			request:            map[string]interface{}{"ids": []uint{todo.ID}, "action": "add_label"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "change type without a type",
+			request:            map[string]interface{}{"ids": []uint{todo.ID}, "action": "change_type"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "someone else's label",
+			request:            map[string]interface{}{"ids": []uint{todo.ID}, "action": "add_label", "label_id": otherLabel.ID},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "label does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/todos/bulk", authToken, tt.request)
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+}
+
+func TestBulkTodos_PinRespectsLimit(t *testing.T) {
+	user, authToken := setupTest(t)
+	maxPinnedTodos := env.FetchInt("MAXIMUM_PINNED_TODOS", 1)
+	pinned := SeedTodo(t, database.Todo{Pinned: true}, user.ID)
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	theirs := SeedTodo(t, struct{}{}, other.ID)
+
+	ids := []uint{pinned.ID, theirs.ID}
+	for i := 0; i < maxPinnedTodos+1; i++ {
+		ids = append(ids, SeedTodo(t, struct{}{}, user.ID).ID)
+	}
+
+	results := bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": ids, "action": "pin"})
+	require.Len(t, results, len(ids))
+
+	assert.Equal(t, dtos.BulkTodoResult{ID: pinned.ID, Success: true}, results[0])
+	assert.Equal(t, dtos.BulkTodoResult{ID: theirs.ID, Error: "todo does not exist"}, results[1])
+	limitMsg := "you can only pin " + strconv.Itoa(maxPinnedTodos) + " todos"
+	for i, result := range results[2:] {
+		if i < maxPinnedTodos-1 {
+			assert.True(t, result.Success, result.Error)
+			assert.True(t, findTodo(t, result.ID).Pinned)
+		} else {
+			assert.Equal(t, dtos.BulkTodoResult{ID: result.ID, Error: limitMsg}, result)
+			assert.False(t, findTodo(t, result.ID).Pinned)
+		}
+	}
+	assert.False(t, findTodo(t, theirs.ID).Pinned)
+}
+
+// eventRecorder keeps the events it is subscribed to so tests can check what was published.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []pkg.Event
+}
+
+func (recorder *eventRecorder) Handle(event pkg.Event) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.events = append(recorder.events, event)
+}
+
+func (recorder *eventRecorder) has(matches func(event pkg.Event) bool) bool {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for _, event := range recorder.events {
+		if matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBulkTodos_Actions(t *testing.T) {
+	recorder := &eventRecorder{}
+	TestServerInstance.App.EventBus.Subscribe("todo.completed", recorder)
+	TestServerInstance.App.EventBus.Subscribe("todo.recurred", recorder)
+
+	user, authToken := setupTest(t)
+	label := SeedLabel(t, struct{}{}, user.ID)
+	weekly := "FREQ=WEEKLY"
+	dueAt := time.Now().Add(24 * time.Hour)
+
+	text := SeedTodo(t, struct{}{}, user.ID)
+	recurring := SeedTodo(t, database.Todo{Recurrence: &weekly, DueAt: &dueAt}, user.ID)
+	checklist := SeedTodo(t, database.Todo{Type: enums.Checklist, Content: new(string)}, user.ID)
+	SeedChecklist(t, struct{}{}, checklist.ID)
+
+	t.Run("complete", func(t *testing.T) {
+		results := bulkUpdateTodos(t, authToken, map[string]interface{}{
+			"ids":    []uint{text.ID, recurring.ID, checklist.ID, text.ID},
+			"action": "complete",
+		})
+
+		// the recurrence moves on to the next occurrence
+		var next database.Todo
+		require.NoError(t, TestServerInstance.DB.Where("title = ?", recurring.Title).Where("id <> ?", recurring.ID).First(&next).Error)
+		assert.Equal(t, &weekly, next.Recurrence)
+		assert.Nil(t, findTodo(t, recurring.ID).Recurrence)
+
+		assert.Equal(t, []dtos.BulkTodoResult{
+			{ID: text.ID, Success: true},
+			{ID: recurring.ID, Success: true, NextTodoID: next.ID},
+			{ID: checklist.ID, Error: "checklist todos are completed by checking off their items"},
+		}, results)
+		assert.True(t, findTodo(t, text.ID).Completed)
+		assert.False(t, findTodo(t, checklist.ID).Completed)
+
+		var completions int64
+		TestServerInstance.DB.Model(&database.TodoCompletion{}).Where("todo_id IN ?", []uint{text.ID, recurring.ID}).Count(&completions)
+		assert.Equal(t, int64(2), completions)
+
+		// listeners hear about the completions the way they do from the single endpoint
+		assert.Eventually(t, func() bool {
+			return recorder.has(func(event pkg.Event) bool {
+				e, ok := event.(*events.TodoCompletedEvent)
+				return ok && e.TodoId == text.ID && e.UserId == user.ID
+			}) && recorder.has(func(event pkg.Event) bool {
+				e, ok := event.(*events.TodoRecurredEvent)
+				return ok && e.TodoId == recurring.ID && e.NextTodoId == next.ID
+			})
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("add label", func(t *testing.T) {
+		results := bulkUpdateTodos(t, authToken, map[string]interface{}{
+			"ids":      []uint{text.ID, checklist.ID},
+			"action":   "add_label",
+			"label_id": label.ID,
+		})
+
+		assert.Equal(t, []dtos.BulkTodoResult{{ID: text.ID, Success: true}, {ID: checklist.ID, Success: true}}, results)
+		for _, todo := range []*database.Todo{text, checklist} {
+			assert.Equal(t, int64(1), TestServerInstance.DB.Model(todo).Association("Labels").Count())
+		}
+	})
+
+	t.Run("change type", func(t *testing.T) {
+		results := bulkUpdateTodos(t, authToken, map[string]interface{}{
+			"ids":    []uint{text.ID, checklist.ID},
+			"action": "change_type",
+			"type":   "checklist",
+		})
+
+		assert.Equal(t, []dtos.BulkTodoResult{{ID: text.ID, Success: true}, {ID: checklist.ID, Success: true}}, results)
+		changed := findTodo(t, text.ID)
+		assert.Equal(t, enums.Checklist, changed.Type)
+		assert.Nil(t, changed.Content)
+
+		bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{checklist.ID}, "action": "change_type", "type": "text"})
+		assert.Equal(t, enums.Text, findTodo(t, checklist.ID).Type)
+		var items int64
+		TestServerInstance.DB.Model(&database.Checklist{}).Where("todo_id = ?", checklist.ID).Count(&items)
+		assert.Zero(t, items)
+	})
+
+	t.Run("archive and delete", func(t *testing.T) {
+		bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{text.ID}, "action": "pin"})
+		results := bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{text.ID}, "action": "archive"})
+		assert.Equal(t, []dtos.BulkTodoResult{{ID: text.ID, Success: true}}, results)
+		archived := findTodo(t, text.ID)
+		assert.NotNil(t, archived.ArchivedAt)
+		assert.False(t, archived.Pinned)
+
+		results = bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{text.ID, recurring.ID}, "action": "delete"})
+		assert.Equal(t, []dtos.BulkTodoResult{{ID: text.ID, Success: true}, {ID: recurring.ID, Success: true}}, results)
+		assert.True(t, findTodo(t, text.ID).DeletedAt.Valid)
+		assert.True(t, findTodo(t, recurring.ID).DeletedAt.Valid)
+
+		// trashed todos are no longer the user's to act on
+		results = bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{text.ID}, "action": "unpin"})
+		assert.Equal(t, []dtos.BulkTodoResult{{ID: text.ID, Error: "todo does not exist"}}, results)
+	})
+}
+
+func TestBulkTodos_ChangeTypeKeepsContent(t *testing.T) {
+	user, authToken := setupTest(t)
+	content := "Pack\n\n- [x] Passport\n    - [ ] Charger\n[X] Tickets"
+	todo := SeedTodo(t, database.Todo{Content: &content}, user.ID)
+
+	assertItems := func(t *testing.T) {
+		t.Helper()
+		changed := fetchTodo(t, authToken, todo.ID)
+		assert.Equal(t, enums.Checklist, changed.Type)
+		assert.Nil(t, changed.Content)
+		require.Equal(t, []string{"Pack", "Passport", "Tickets"}, descriptions(changed.Checklists))
+		assert.Equal(t, []bool{false, true, true}, []bool{changed.Checklists[0].Done, changed.Checklists[1].Done, changed.Checklists[2].Done})
+		require.Equal(t, []string{"Charger"}, descriptions(changed.Checklists[1].Children))
+		assert.False(t, changed.Checklists[1].Children[0].Done)
+	}
+
+	// every line of the content becomes an item
+	results := bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{todo.ID}, "action": "change_type", "type": "checklist"})
+	require.Equal(t, []dtos.BulkTodoResult{{ID: todo.ID, Success: true}}, results)
+	assertItems(t)
+
+	// and the items are written back out as content
+	results = bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{todo.ID}, "action": "change_type", "type": "text"})
+	require.Equal(t, []dtos.BulkTodoResult{{ID: todo.ID, Success: true}}, results)
+	text := findTodo(t, todo.ID)
+	assert.Equal(t, enums.Text, text.Type)
+	require.NotNil(t, text.Content)
+	assert.Equal(t, "- [ ] Pack\n- [x] Passport\n  - [ ] Charger\n- [x] Tickets", *text.Content)
+
+	bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{todo.ID}, "action": "change_type", "type": "checklist"})
+	assertItems(t)
+}
+
+func TestBulkTodos_SharedTodos(t *testing.T) {
+	user, authToken := setupTest(t)
+	owner := SeedUser(t, database.User{Email: "owner@gmail.com"})
+	label := SeedLabel(t, struct{}{}, user.ID)
+	editable := SeedTodo(t, struct{}{}, owner.ID)
+	viewable := SeedTodo(t, struct{}{}, owner.ID)
+	shareTodo(t, editable.ID, user.ID, enums.Editor)
+	shareTodo(t, viewable.ID, user.ID, enums.Viewer)
+
+	// editors can complete and convert a todo the way the single endpoints let them
+	for _, request := range []map[string]interface{}{
+		{"action": "complete"},
+		{"action": "change_type", "type": "checklist"},
+	} {
+		request["ids"] = []uint{editable.ID, viewable.ID}
+		results := bulkUpdateTodos(t, authToken, request)
+		assert.Equal(t, []dtos.BulkTodoResult{
+			{ID: editable.ID, Success: true},
+			{ID: viewable.ID, Error: "you do not have permission to edit this todo"},
+		}, results, request["action"])
+	}
+
+	// but like the single endpoint, only the owner can label a todo
+	results := bulkUpdateTodos(t, authToken, map[string]interface{}{"ids": []uint{editable.ID, viewable.ID}, "action": "add_label", "label_id": label.ID})
+	assert.Equal(t, []dtos.BulkTodoResult{
+		{ID: editable.ID, Error: "todo does not exist"},
+		{ID: viewable.ID, Error: "todo does not exist"},
+	}, results)
+
+	shared := findTodo(t, editable.ID)
+	assert.True(t, shared.Completed)
+	assert.Equal(t, enums.Checklist, shared.Type)
+	assert.Zero(t, TestServerInstance.DB.Model(shared).Association("Labels").Count())
+	assert.False(t, findTodo(t, viewable.ID).Completed)
+}
@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func createCalendarFeed(t *testing.T, authToken string) (string, string) {
+	t.Helper()
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/calendar/feed", authToken, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	assert.Equal(t, "Calendar feed created successfully", response.Message)
+	data := response.Data.(map[string]interface{})
+	return data["token"].(string), data["url"].(string)
+}
+
+func fetchCalendar(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+// unfold joins the folded lines of an iCalendar file back together so that properties can be matched whole.
+func unfold(ics string) string {
+	return strings.ReplaceAll(ics, "\r\n ", "")
+}
+
+func TestCalendarFeed(t *testing.T) {
+	user, authToken := setupTest(t)
+	label := SeedLabel(t, database.Label{Name: "work, urgent"}, user.ID)
+	dueAt := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	startAt := dueAt.Add(-2 * time.Hour)
+	completedAt := dueAt.Add(-time.Hour)
+	content := "Bring the slides; and the notes"
+	weekly := "FREQ=WEEKLY"
+
+	report := SeedTodo(t, database.Todo{Title: "Quarterly report", Content: &content, Priority: enums.PriorityUrgent, StartAt: &startAt, DueAt: &dueAt, Recurrence: &weekly}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Model(report).Association("Labels").Append(label))
+	done := SeedTodo(t, database.Todo{Title: "Book flights", DueAt: &dueAt, Completed: true, CompletedAt: &completedAt}, user.ID)
+	packing := SeedTodo(t, database.Todo{Title: "Pack", Type: enums.Checklist, DueAt: &dueAt}, user.ID)
+	clothes := SeedChecklist(t, database.Checklist{Description: "Clothes", Done: true, Position: "a"}, packing.ID)
+	SeedChecklist(t, database.Checklist{Description: "Socks", Done: true, Position: "a", ParentID: &clothes.ID}, packing.ID)
+	SeedChecklist(t, database.Checklist{Description: "Charger", Position: "b"}, packing.ID)
+	longTitle := strings.Repeat("Résumé review ", 10)
+	SeedTodo(t, database.Todo{Title: longTitle, DueAt: &dueAt}, user.ID)
+	undated := SeedTodo(t, database.Todo{Title: "Someday"}, user.ID)
+	archivedAt := time.Now()
+	archived := SeedTodo(t, database.Todo{Title: "Old", DueAt: &dueAt, ArchivedAt: &archivedAt}, user.ID)
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	theirs := SeedTodo(t, database.Todo{Title: "Not yours", DueAt: &dueAt}, other.ID)
+
+	token, url := createCalendarFeed(t, authToken)
+	assert.Equal(t, TestServerInstance.Server.URL+"/calendar/"+token+".ics", url)
+
+	resp, body := fetchCalendar(t, url)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+	// long lines are folded without splitting a character
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+
+	ics := unfold(body)
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 4, strings.Count(ics, "BEGIN:VTODO"))
+	assert.Contains(t, ics, "SUMMARY:"+longTitle+"\r\n")
+	assert.NotContains(t, ics, "BEGIN:VEVENT")
+	for _, todo := range []*database.Todo{undated, archived, theirs} {
+		assert.NotContains(t, ics, todo.Title)
+	}
+
+	assert.Contains(t, ics, "UID:todo-"+strconv.Itoa(int(report.ID))+"@todo-golang\r\n")
+	assert.Contains(t, ics, "SUMMARY:Quarterly report\r\n")
+	assert.Contains(t, ics, `DESCRIPTION:Bring the slides\; and the notes`+"\r\n")
+	assert.Contains(t, ics, "DTSTART:20300102T130405Z\r\n")
+	assert.Contains(t, ics, "DUE:20300102T150405Z\r\n")
+	assert.Contains(t, ics, "PRIORITY:1\r\n")
+	assert.NotContains(t, ics, "RRULE", "occurrences are materialised as their own todos")
+	assert.Contains(t, ics, `CATEGORIES:work\, urgent`+"\r\n")
+
+	assert.Contains(t, ics, "STATUS:COMPLETED\r\nCOMPLETED:20300102T140405Z\r\n")
+	assert.Contains(t, ics, `DESCRIPTION:2 of 3 items done\n[x] Clothes\n  [x] Socks\n[ ] Charger`+"\r\n")
+	assert.Contains(t, ics, "STATUS:NEEDS-ACTION\r\nPERCENT-COMPLETE:66\r\n")
+	assert.Contains(t, ics, "SUMMARY:"+done.Title)
+
+	var feed database.CalendarFeed
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).First(&feed).Error)
+	assert.NotNil(t, feed.LastFetchedAt)
+	assert.NotEqual(t, token, feed.TokenHash)
+
+	// events are opt in, and run from when a todo starts until it is due
+	resp, body = fetchCalendar(t, url+"?events=true")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	ics = unfold(body)
+	assert.Equal(t, 4, strings.Count(ics, "BEGIN:VEVENT"))
+	assert.Contains(t, ics, "UID:event-"+strconv.Itoa(int(report.ID))+"@todo-golang\r\n")
+	assert.Contains(t, ics, "DTSTART:20300102T130405Z\r\nDTEND:20300102T150405Z\r\nTRANSP:TRANSPARENT\r\n")
+
+	resp, _ = fetchCalendar(t, url+"?events=sometimes")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestCalendarFeed_RotateAndDelete(t *testing.T) {
+	_, authToken := setupTest(t)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, "/calendar/feed", authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "calendar feed does not exist", response.Message)
+
+	_, oldUrl := createCalendarFeed(t, authToken)
+	resp, _ = fetchCalendar(t, oldUrl)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// rotating the token turns the old URL off
+	_, newUrl := createCalendarFeed(t, authToken)
+	assert.NotEqual(t, oldUrl, newUrl)
+	resp, _ = fetchCalendar(t, oldUrl)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = fetchCalendar(t, newUrl)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, response = sendAuthenticatedRequest(t, http.MethodGet, "/calendar/feed", authToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, response.Data.(map[string]interface{}), "token_hash")
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, "/calendar/feed", authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = fetchCalendar(t, newUrl)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, response = sendAuthenticatedRequest(t, http.MethodDelete, "/calendar/feed", authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "calendar feed does not exist", response.Message)
+
+	// a feed can be turned back on after it was deleted
+	_, url := createCalendarFeed(t, authToken)
+	resp, _ = fetchCalendar(t, url)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
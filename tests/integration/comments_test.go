@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func SeedComment(t *testing.T, todoId uint, userId uint, body string) *database.Comment {
+	t.Helper()
+	comment := database.Comment{TodoID: todoId, UserID: userId, Body: body}
+	require.NoError(t, TestServerInstance.DB.Create(&comment).Error)
+	return &comment
+}
+
+func TestAddComment(t *testing.T) {
+	tests := []struct {
+		name               string
+		as                 string
+		body               string
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "owner comments",
+			as:                 "owner",
+			body:               "Looks good",
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "viewers can comment",
+			as:                 "viewer",
+			body:               "Can I help?",
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "strangers cannot comment",
+			as:                 "stranger",
+			body:               "Hello",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "todo does not exist",
+		},
+		{
+			name:               "empty body",
+			as:                 "owner",
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, authToken := setupTest(t)
+			viewer := SeedUser(t, database.User{Email: "viewer@gmail.com"})
+			stranger := SeedUser(t, database.User{Email: "stranger@gmail.com"})
+			todo := SeedTodo(t, database.Todo{}, owner.ID)
+			shareTodo(t, todo.ID, viewer.ID, enums.Viewer)
+
+			author := map[string]*database.User{"owner": owner, "viewer": viewer, "stranger": stranger}[tt.as]
+			if tt.as != "owner" {
+				authToken = GenerateTestJwtToken(t, author.ID)
+			}
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/comments", todo.ID), authToken, map[string]string{"body": tt.body})
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			if tt.expectedStatusCode == http.StatusCreated {
+				comment := database.Comment{}
+				require.NoError(t, TestServerInstance.DB.Where("todo_id = ?", todo.ID).First(&comment).Error)
+				assert.Equal(t, tt.body, comment.Body)
+				assert.Equal(t, author.ID, comment.UserID)
+			}
+		})
+	}
+}
+
+func TestFetchComments(t *testing.T) {
+	owner, authToken := setupTest(t)
+	collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com", FirstName: "Jane"})
+	todo := SeedTodo(t, database.Todo{}, owner.ID)
+	otherTodo := SeedTodo(t, database.Todo{}, owner.ID)
+	shareTodo(t, todo.ID, collaborator.ID, enums.Editor)
+
+	first := SeedComment(t, todo.ID, owner.ID, "first")
+	second := SeedComment(t, todo.ID, collaborator.ID, "second")
+	third := SeedComment(t, todo.ID, owner.ID, "third")
+	SeedComment(t, otherTodo.ID, owner.ID, "elsewhere")
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d/comments?per_page=2", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	comments := response.Data.([]interface{})
+	require.Len(t, comments, 2)
+	assert.Equal(t, float64(first.ID), comments[0].(map[string]interface{})["id"])
+	assert.Equal(t, float64(second.ID), comments[1].(map[string]interface{})["id"])
+	assert.Equal(t, "Jane", comments[1].(map[string]interface{})["author"].(map[string]interface{})["first_name"])
+
+	resp, response = sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d/comments?per_page=2&page=2", todo.ID), GenerateTestJwtToken(t, collaborator.ID), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	comments = response.Data.([]interface{})
+	require.Len(t, comments, 1)
+	assert.Equal(t, float64(third.ID), comments[0].(map[string]interface{})["id"])
+}
+
+func TestUpdateAndDeleteComment(t *testing.T) {
+	tests := []struct {
+		name               string
+		method             string
+		as                 string
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "author edits",
+			method:             http.MethodPatch,
+			as:                 "author",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "todo owner cannot edit someone else's comment",
+			method:             http.MethodPatch,
+			as:                 "owner",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only the author can edit this comment",
+		},
+		{
+			name:               "author deletes",
+			method:             http.MethodDelete,
+			as:                 "author",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "todo owner deletes someone else's comment",
+			method:             http.MethodDelete,
+			as:                 "owner",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "other collaborators cannot delete",
+			method:             http.MethodDelete,
+			as:                 "editor",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "you do not have permission to delete this comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, authToken := setupTest(t)
+			author := SeedUser(t, database.User{Email: "author@gmail.com"})
+			editor := SeedUser(t, database.User{Email: "editor@gmail.com"})
+			todo := SeedTodo(t, database.Todo{}, owner.ID)
+			shareTodo(t, todo.ID, author.ID, enums.Viewer)
+			shareTodo(t, todo.ID, editor.ID, enums.Editor)
+			comment := SeedComment(t, todo.ID, author.ID, "original")
+
+			switch tt.as {
+			case "author":
+				authToken = GenerateTestJwtToken(t, author.ID)
+			case "editor":
+				authToken = GenerateTestJwtToken(t, editor.ID)
+			}
+
+			resp, response := sendAuthenticatedRequest(t, tt.method, fmt.Sprintf("/todos/%d/comments/%d", todo.ID, comment.ID), authToken, map[string]string{"body": "edited"})
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			updated := database.Comment{}
+			err := TestServerInstance.DB.First(&updated, comment.ID).Error
+			switch {
+			case tt.method == http.MethodDelete && tt.expectedStatusCode == http.StatusNoContent:
+				assert.Error(t, err)
+			case tt.method == http.MethodPatch && tt.expectedStatusCode == http.StatusNoContent:
+				require.NoError(t, err)
+				assert.Equal(t, "edited", updated.Body)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "original", updated.Body)
+			}
+		})
+	}
+}
+
+func TestDeleteComment_WorkspaceAdminsModerate(t *testing.T) {
+	owner, _ := setupTest(t)
+	admin := SeedUser(t, database.User{Email: "admin@gmail.com"})
+	member := SeedUser(t, database.User{Email: "member@gmail.com"})
+	author := SeedUser(t, database.User{Email: "author@gmail.com"})
+	workspace := SeedWorkspace(t, owner.ID, map[uint]enums.WorkspaceRole{
+		admin.ID:  enums.WorkspaceAdmin,
+		member.ID: enums.WorkspaceMember,
+		author.ID: enums.WorkspaceMember,
+	})
+	todo := SeedTodo(t, database.Todo{WorkspaceID: &workspace.ID}, author.ID)
+	comment := SeedComment(t, todo.ID, author.ID, "original")
+	path := fmt.Sprintf("/todos/%d/comments/%d", todo.ID, comment.ID)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, path, GenerateTestJwtToken(t, member.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "you do not have permission to delete this comment", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, path, GenerateTestJwtToken(t, admin.ID), nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Error(t, TestServerInstance.DB.First(&database.Comment{}, comment.ID).Error)
+}
+
+func TestPurgeTodo_RemovesComments(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{}, user.ID)
+	SeedComment(t, todo.ID, user.ID, "bye")
+	require.NoError(t, TestServerInstance.DB.Delete(&database.Todo{}, todo.ID).Error)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/permanent", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var count int64
+	TestServerInstance.DB.Unscoped().Model(&database.Comment{}).Where("todo_id = ?", todo.ID).Count(&count)
+	assert.Zero(t, count)
+}
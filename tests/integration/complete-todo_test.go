@@ -0,0 +1,220 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/internal/events"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/internal/todo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCompleteAndReopenTodo(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, struct{}{}, user.ID)
+
+	//completing twice only records one completion
+	for i := 0; i < 2; i++ {
+		resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/complete", todo.ID), authToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	completedTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.First(&completedTodo, todo.ID).Error)
+	assert.True(t, completedTodo.Completed)
+	assert.NotNil(t, completedTodo.CompletedAt)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/reopen", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reopenedTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.First(&reopenedTodo, todo.ID).Error)
+	assert.False(t, reopenedTodo.Completed)
+	assert.Nil(t, reopenedTodo.CompletedAt)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/complete", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d/completions", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, response.Data, 2)
+}
+
+func TestCompleteTodo_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		todoType    enums.TodoType
+		otherUser   bool
+		expectedMsg string
+	}{
+		{
+			name:        "completing a checklist todo",
+			path:        "complete",
+			todoType:    enums.Checklist,
+			expectedMsg: "checklist todos are completed by checking off their items",
+		},
+		{
+			name:        "reopening a checklist todo",
+			path:        "reopen",
+			todoType:    enums.Checklist,
+			expectedMsg: "checklist todos are reopened by unchecking one of their items",
+		},
+		{
+			name:        "completing another user's todo",
+			path:        "complete",
+			todoType:    enums.Text,
+			otherUser:   true,
+			expectedMsg: "todo does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			owner := user
+			if tt.otherUser {
+				owner = SeedUser(t, database.User{Email: "another@gmail.com"})
+			}
+			todo := SeedTodo(t, database.Todo{Type: tt.todoType}, owner.ID)
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/%s", todo.ID, tt.path), authToken, nil)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, tt.expectedMsg, response.Message)
+		})
+	}
+}
+
+func TestChecklistTodoCompletesWithItsItems(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	first := SeedChecklist(t, struct{}{}, todo.ID)
+	second := SeedChecklist(t, struct{}{}, todo.ID)
+
+	setItemStatus := func(item *database.Checklist, done bool) {
+		resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, item.ID), authToken, dtos.ChecklistStatus{Done: done})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+	isCompleted := func() bool {
+		current := database.Todo{}
+		require.NoError(t, TestServerInstance.DB.First(&current, todo.ID).Error)
+		return current.Completed
+	}
+
+	setItemStatus(first, true)
+	assert.False(t, isCompleted(), "todo should stay open while an item is not done")
+
+	setItemStatus(second, true)
+	assert.True(t, isCompleted(), "todo should complete once every item is done")
+
+	setItemStatus(second, false)
+	assert.False(t, isCompleted(), "todo should reopen once an item is unchecked")
+
+	setItemStatus(second, true)
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/checklist", todo.ID), authToken, dtos.ChecklistItem{Item: "one more thing"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.False(t, isCompleted(), "adding an unchecked item should reopen the todo")
+
+	var completions int64
+	TestServerInstance.DB.Model(&database.TodoCompletion{}).Where("todo_id = ?", todo.ID).Count(&completions)
+	assert.Equal(t, int64(2), completions)
+}
+
+func TestCompletingRecurringTextTodoSpawnsNextOccurrence(t *testing.T) {
+	user, authToken := setupTest(t)
+	dueAt := time.Date(2025, time.March, 7, 17, 0, 0, 0, time.Local)
+	recurrence := "FREQ=DAILY"
+	todo := SeedTodo(t, database.Todo{DueAt: &dueAt, Recurrence: &recurrence}, user.ID)
+	label := SeedLabel(t, struct{}{}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Model(todo).Association("Labels").Append(label))
+	collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com"})
+	shareTodo(t, todo.ID, collaborator.ID, enums.Editor)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/complete", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	nextTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.Preload("Labels").Where("id <> ?", todo.ID).First(&nextTodo).Error)
+	assert.False(t, nextTodo.Completed)
+	require.NotNil(t, nextTodo.DueAt)
+	assert.True(t, dueAt.AddDate(0, 0, 1).Equal(*nextTodo.DueAt))
+
+	//the next occurrence keeps the labels and collaborators of the one before it
+	require.Len(t, nextTodo.Labels, 1)
+	assert.Equal(t, label.ID, nextTodo.Labels[0].ID)
+	share := database.TodoShare{}
+	require.NoError(t, TestServerInstance.DB.Where("todo_id = ?", nextTodo.ID).First(&share).Error)
+	assert.Equal(t, collaborator.ID, share.UserID)
+	assert.Equal(t, enums.Editor, share.Role)
+}
+
+func TestTodoCompletionListener_NotifiesCollaborators(t *testing.T) {
+	user, _ := setupTest(t)
+	sharedTodo := SeedTodo(t, struct{}{}, user.ID)
+	collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com"})
+	shareTodo(t, sharedTodo.ID, collaborator.ID, enums.Viewer)
+
+	sseService := TestServerInstance.App.SSEContainer.SSEService
+	clients := make(map[uint]*sse.ConnectedClient)
+	for _, userId := range []uint{user.ID, collaborator.ID} {
+		clients[userId] = &sse.ConnectedClient{
+			Data: make(chan dtos.SSEData, 2),
+			Quit: make(chan struct{}),
+			Done: make(chan struct{}),
+		}
+		sseService.AddClient(userId, clients[userId])
+		defer sseService.RemoveClients(userId)
+	}
+
+	listener := todo.NewTodoCompletionListener(TestServerInstance.App.TodoContainer.TodoShareRepository, sseService)
+	listener.Handle(&events.TodoCompletedEvent{TodoId: sharedTodo.ID, UserId: user.ID})
+	listener.Handle(&events.TodoReopenedEvent{TodoId: sharedTodo.ID, UserId: user.ID})
+
+	for _, client := range clients {
+		require.Len(t, client.Data, 2)
+		assert.Equal(t, dtos.TodoCompleted, (<-client.Data).Event)
+		assert.Equal(t, dtos.TodoReopened, (<-client.Data).Event)
+	}
+}
+
+func TestFetchTodos_CompletedFilter(t *testing.T) {
+	user, authToken := setupTest(t)
+	yesterday := time.Now().AddDate(0, 0, -1)
+	openTodo := SeedTodo(t, database.Todo{DueAt: &yesterday}, user.ID)
+	completedTodo := SeedTodo(t, database.Todo{DueAt: &yesterday, Completed: true, CompletedAt: &yesterday}, user.ID)
+
+	tests := []struct {
+		description string
+		query       string
+		expectedIds []uint
+	}{
+		{
+			description: "completed todos",
+			query:       "filters[completed]=true",
+			expectedIds: []uint{completedTodo.ID},
+		},
+		{
+			description: "open todos",
+			query:       "filters[completed]=false",
+			expectedIds: []uint{openTodo.ID},
+		},
+		{
+			description: "completed todos are never overdue",
+			query:       "filters[overdue]=true",
+			expectedIds: []uint{openTodo.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			response := fetchTodos(t, authToken, tt.query)
+			assert.ElementsMatch(t, tt.expectedIds, todoIds(response.Data))
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fetchExport(t *testing.T, authToken string, query string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+"/export"+query, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+// seedExportData gives the user a todo with a label, more checklist items than fit in a single export batch, a comment
+// and a share, a trashed todo, a workspace and a view, plus a todo belonging to someone else that must not be exported.
+func seedExportData(t *testing.T, user *database.User) (*database.Todo, *database.Label) {
+	t.Helper()
+
+	label := SeedLabel(t, struct{}{}, user.ID)
+	todo := SeedTodo(t, database.Todo{Title: "Quarterly, \"big\" report"}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Model(todo).Association("Labels").Append(label))
+
+	items := make([]database.Checklist, 0, 501)
+	for i := 0; i < 501; i++ {
+		items = append(items, database.Checklist{Description: "item " + strconv.Itoa(i), TodoID: todo.ID})
+	}
+	require.NoError(t, TestServerInstance.DB.CreateInBatches(&items, 100).Error)
+	require.NoError(t, TestServerInstance.DB.Create(&database.Comment{TodoID: todo.ID, UserID: user.ID, Body: "On it"}).Error)
+
+	trashed := SeedTodo(t, database.Todo{Title: "Trashed"}, user.ID)
+	SeedChecklist(t, struct{}{}, trashed.ID)
+	deletedAt := time.Now()
+	require.NoError(t, TestServerInstance.DB.Model(&database.Checklist{}).Where("todo_id = ?", trashed.ID).Update("deleted_at", deletedAt).Error)
+	require.NoError(t, TestServerInstance.DB.Model(&database.Todo{}).Where("id = ?", trashed.ID).Update("deleted_at", deletedAt).Error)
+
+	SeedWorkspace(t, user.ID, nil)
+	require.NoError(t, TestServerInstance.DB.Create(&database.View{Name: "Urgent", UserID: user.ID, Filters: map[string]string{"priority": "high"}}).Error)
+
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	shareTodo(t, todo.ID, other.ID, enums.Viewer)
+	theirs := SeedTodo(t, database.Todo{Title: "Not yours"}, other.ID)
+	SeedChecklist(t, struct{}{}, theirs.ID)
+	return todo, label
+}
+
+func TestExport_JSON(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo, label := seedExportData(t, user)
+
+	resp, body := fetchExport(t, authToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "todo-export.json")
+
+	var export struct {
+		ExportedAt     string                   `json:"exported_at"`
+		Profile        []map[string]interface{} `json:"profile"`
+		Todos          []database.Todo          `json:"todos"`
+		ChecklistItems []database.Checklist     `json:"checklist_items"`
+		Labels         []database.Label         `json:"labels"`
+		Comments       []database.Comment       `json:"comments"`
+		Templates      []database.Template      `json:"templates"`
+		Workspaces     []database.Workspace     `json:"workspaces"`
+		Shares         []database.TodoShare     `json:"shares"`
+	}
+	require.NoError(t, json.Unmarshal(body, &export))
+
+	assert.NotEmpty(t, export.ExportedAt)
+	require.Len(t, export.Profile, 1)
+	assert.Equal(t, user.Email, export.Profile[0]["email"])
+	assert.NotContains(t, export.Profile[0], "password")
+	assert.NotContains(t, export.Profile[0], "reset_token")
+
+	require.Len(t, export.Todos, 2)
+	assert.Equal(t, todo.Title, export.Todos[0].Title)
+	require.Len(t, export.Todos[0].Labels, 1)
+	assert.Equal(t, label.ID, export.Todos[0].Labels[0].ID)
+	assert.Equal(t, "Trashed", export.Todos[1].Title)
+	assert.Len(t, export.ChecklistItems, 502)
+	assert.Len(t, export.Labels, 1)
+	require.Len(t, export.Comments, 1)
+	assert.Equal(t, "On it", export.Comments[0].Body)
+	assert.Empty(t, export.Templates)
+	require.Len(t, export.Workspaces, 1)
+	assert.Equal(t, "Team board", export.Workspaces[0].Name)
+	require.Len(t, export.Shares, 1)
+	assert.Equal(t, todo.ID, export.Shares[0].TodoID)
+
+	var deletedAt struct {
+		Todos []struct {
+			DeletedAt *time.Time `json:"deleted_at"`
+		} `json:"todos"`
+	}
+	require.NoError(t, json.Unmarshal(body, &deletedAt))
+	assert.Nil(t, deletedAt.Todos[0].DeletedAt)
+	assert.NotNil(t, deletedAt.Todos[1].DeletedAt)
+}
+
+func TestExport_CSV(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo, label := seedExportData(t, user)
+	png := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte("a"), 100)...)
+	uploadResp, _ := uploadAttachment(t, authToken, todo.ID, "../diagram.png", png)
+	require.Equal(t, http.StatusCreated, uploadResp.StatusCode)
+	attachment := storedAttachment(t, todo.ID)
+
+	resp, body := fetchExport(t, authToken, "?format=csv")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	files := make(map[string][][]string)
+	blobs := make(map[string][]byte)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		if strings.HasSuffix(file.Name, ".csv") {
+			rows, err := csv.NewReader(reader).ReadAll()
+			require.NoError(t, err)
+			files[file.Name] = rows
+		} else {
+			contents, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			blobs[file.Name] = contents
+		}
+		_ = reader.Close()
+	}
+
+	for _, name := range []string{"profile.csv", "todos.csv", "checklist_items.csv", "completions.csv", "labels.csv", "comments.csv", "reminders.csv", "attachments.csv", "views.csv", "templates.csv", "workspaces.csv", "shares.csv"} {
+		assert.Contains(t, files, name)
+	}
+
+	assert.Equal(t, []string{"id", "first_name", "last_name", "email", "created_at"}, files["profile.csv"][0])
+	assert.Equal(t, user.Email, files["profile.csv"][1][3])
+
+	todos := files["todos.csv"]
+	require.Len(t, todos, 3)
+	assert.Equal(t, "deleted_at", todos[0][18])
+	assert.Equal(t, strconv.Itoa(int(todo.ID)), todos[1][0])
+	assert.Equal(t, todo.Title, todos[1][1])
+	assert.Equal(t, strconv.Itoa(int(label.ID)), todos[1][15])
+	assert.Empty(t, todos[1][18])
+	assert.Equal(t, "Trashed", todos[2][1])
+	assert.NotEmpty(t, todos[2][18])
+
+	// a header followed by every item, the trashed todo's included
+	assert.Len(t, files["checklist_items.csv"], 503)
+	assert.Len(t, files["reminders.csv"], 1)
+	require.Len(t, files["workspaces.csv"], 2)
+	assert.Equal(t, "Team board", files["workspaces.csv"][1][1])
+	require.Len(t, files["shares.csv"], 2)
+	assert.Equal(t, strconv.Itoa(int(todo.ID)), files["shares.csv"][1][1])
+	require.Len(t, files["views.csv"], 2)
+	assert.Equal(t, `{"priority":"high"}`, files["views.csv"][1][2])
+
+	attachments := files["attachments.csv"]
+	require.Len(t, attachments, 2)
+	file := "attachments/" + strconv.Itoa(int(attachment.ID)) + "/diagram.png"
+	assert.Equal(t, file, attachments[1][6])
+	assert.Equal(t, png, blobs[file])
+}
+
+func TestExport_RejectsUnknownFormats(t *testing.T) {
+	_, authToken := setupTest(t)
+
+	resp, body := fetchExport(t, authToken, "?format=xml")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), "export format must be json or csv")
+}
@@ -1,8 +1,18 @@
 package integration
 
 import (
+	"encoding/json"
+	"github.com/horlerdipo/todo-golang/env"
 	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupFetchTodoTest(t *testing.T, todoCount int) (*database.User, string, []*database.Todo) {
@@ -17,3 +27,352 @@ func setupFetchTodoTest(t *testing.T, todoCount int) (*database.User, string, []
 }
 
 func TestFetchTodos(t *testing.T) {}
+
+func fetchTodos(t *testing.T, authToken string, query string) dtos.PaginatedResponse[database.Todo] {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+"/todos?"+query, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response dtos.PaginatedResponse[database.Todo]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response
+}
+
+func todoIds(todos []database.Todo) []uint {
+	ids := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return ids
+}
+
+func TestFetchTodos_DueDateFilters(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	laterToday := now.Add(time.Minute)
+	if laterToday.Day() != now.Day() {
+		t.Skip("too close to midnight to seed a todo due later today")
+	}
+	nextMonth := now.AddDate(0, 1, 0)
+
+	overdueTodo := SeedTodo(t, database.Todo{DueAt: &yesterday}, user.ID)
+	dueTodayTodo := SeedTodo(t, database.Todo{DueAt: &laterToday}, user.ID)
+	dueLaterTodo := SeedTodo(t, database.Todo{DueAt: &nextMonth}, user.ID)
+	undatedTodo := SeedTodo(t, struct{}{}, user.ID)
+
+	tests := []struct {
+		description string
+		query       string
+		expectedIds []uint
+	}{
+		{
+			description: "overdue todos",
+			query:       "filters[overdue]=true",
+			expectedIds: []uint{overdueTodo.ID},
+		},
+		{
+			description: "todos that are not overdue",
+			query:       "filters[overdue]=false",
+			expectedIds: []uint{dueTodayTodo.ID, dueLaterTodo.ID, undatedTodo.ID},
+		},
+		{
+			description: "todos due today",
+			query:       "filters[due_today]=true",
+			expectedIds: []uint{dueTodayTodo.ID},
+		},
+		{
+			description: "todos due within a range",
+			query:       "filters[due_from]=" + now.Format("2006-01-02") + "&filters[due_to]=" + nextMonth.AddDate(0, 0, 1).Format("2006-01-02"),
+			expectedIds: []uint{dueTodayTodo.ID, dueLaterTodo.ID},
+		},
+		{
+			description: "todos sorted by due date",
+			query:       "filters[due_from]=" + yesterday.AddDate(0, 0, -1).Format("2006-01-02") + "&sort_by=due_at&order=desc",
+			expectedIds: []uint{dueLaterTodo.ID, dueTodayTodo.ID, overdueTodo.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			response := fetchTodos(t, authToken, tt.query)
+			if strings.Contains(tt.query, "sort_by") {
+				assert.Equal(t, tt.expectedIds, todoIds(response.Data))
+			} else {
+				assert.ElementsMatch(t, tt.expectedIds, todoIds(response.Data))
+			}
+			assert.Equal(t, len(tt.expectedIds), response.Meta.TotalCount)
+		})
+	}
+}
+
+// test that due dates sent with an offset are compared by the instant they fall on rather than by their text
+func TestFetchTodos_DueDatesWithAnOffset(t *testing.T) {
+	_, authToken, _ := setupFetchTodoTest(t, 0)
+
+	createTodoDueAt := func(dueAt time.Time) uint {
+		t.Helper()
+		resp, _ := sendAuthenticatedRequest(t, http.MethodPost, "/todos", authToken, map[string]string{
+			"title":   "todo",
+			"content": "content",
+			"type":    "text",
+			"due_at":  dueAt.Format(time.RFC3339),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var todoId uint
+		TestServerInstance.DB.Model(&database.Todo{}).Order("id desc").Limit(1).Pluck("id", &todoId)
+		return todoId
+	}
+
+	now := time.Now().Truncate(time.Second)
+	//an hour ago, written ahead of UTC so its text reads later than now
+	overdueTodo := createTodoDueAt(now.Add(-time.Hour).In(time.FixedZone("+05:00", 5*60*60)))
+	//in an hour, written behind UTC so its text reads earlier than now
+	upcomingTodo := createTodoDueAt(now.Add(time.Hour).In(time.FixedZone("-05:00", -5*60*60)))
+
+	var stored database.Todo
+	require.NoError(t, TestServerInstance.DB.First(&stored, overdueTodo).Error)
+	assert.True(t, stored.DueAt.Equal(now.Add(-time.Hour)))
+	assert.Equal(t, time.UTC, stored.DueAt.Location())
+
+	assert.Equal(t, []uint{overdueTodo}, todoIds(fetchTodos(t, authToken, "filters[overdue]=true").Data))
+	assert.Equal(t, []uint{upcomingTodo}, todoIds(fetchTodos(t, authToken, "filters[overdue]=false").Data))
+
+	dueFrom := url.QueryEscape(now.In(time.FixedZone("+05:00", 5*60*60)).Format(time.RFC3339))
+	assert.Equal(t, []uint{upcomingTodo}, todoIds(fetchTodos(t, authToken, "filters[due_from]="+dueFrom).Data))
+}
+
+func TestFetchTodos_FilterOperators(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	march := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	april := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	milk := SeedTodo(t, database.Todo{Title: "Buy Milk", DueAt: &march, Priority: enums.PriorityHigh}, user.ID)
+	oatMilk := SeedTodo(t, database.Todo{Title: "Buy oat milk", DueAt: &april, Priority: enums.PriorityLow}, user.ID)
+	percent := SeedTodo(t, database.Todo{Title: "Save 100% of receipts", Priority: enums.PriorityNone}, user.ID)
+
+	tests := []struct {
+		description string
+		query       string
+		expectedIds []uint
+	}{
+		{
+			description: "titles containing a word, case-insensitively",
+			query:       "filters[title][like]=milk",
+			expectedIds: []uint{milk.ID, oatMilk.ID},
+		},
+		{
+			description: "like wildcards are matched literally",
+			query:       "filters[title][like]=100%25",
+			expectedIds: []uint{percent.ID},
+		},
+		{
+			description: "titles not containing a word",
+			query:       "filters[title][not_like]=oat",
+			expectedIds: []uint{milk.ID, percent.ID},
+		},
+		{
+			description: "priorities in a list",
+			query:       "filters[priority][in]=high,none",
+			expectedIds: []uint{milk.ID, percent.ID},
+		},
+		{
+			description: "priorities not in a list",
+			query:       "filters[priority][not_in]=high,none",
+			expectedIds: []uint{oatMilk.ID},
+		},
+		{
+			description: "not equal",
+			query:       "filters[priority][not]=low",
+			expectedIds: []uint{milk.ID, percent.ID},
+		},
+		{
+			description: "due after a date",
+			query:       "filters[due_at][gt]=2025-04-01",
+			expectedIds: []uint{oatMilk.ID},
+		},
+		{
+			description: "due between two dates",
+			query:       "filters[due_at][between]=2025-03-01,2025-03-31",
+			expectedIds: []uint{milk.ID},
+		},
+		{
+			description: "without a due date",
+			query:       "filters[due_at][null]=true",
+			expectedIds: []uint{percent.ID},
+		},
+		{
+			description: "with a due date",
+			query:       "filters[due_at][null]=false",
+			expectedIds: []uint{milk.ID, oatMilk.ID},
+		},
+		{
+			description: "operators combine with plain filters",
+			query:       "filters[title][like]=milk&filters[priority]=low",
+			expectedIds: []uint{oatMilk.ID},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			response := fetchTodos(t, authToken, test.query)
+			assert.ElementsMatch(t, test.expectedIds, todoIds(response.Data))
+			assert.Equal(t, len(test.expectedIds), response.Meta.TotalCount)
+		})
+	}
+}
+
+func TestFetchTodos_RejectsUnsupportedFilterOperators(t *testing.T) {
+	_, authToken, _ := setupFetchTodoTest(t, 1)
+
+	for _, query := range []string{
+		"filters[pinned][like]=true",
+		"filters[due_at][between]=2025-03-01",
+		"filters[overdue][not]=true",
+		"filters[due_at][near]=2025-03-01",
+	} {
+		resp, response := sendAuthenticatedRequest(t, http.MethodGet, "/todos?"+query, authToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		assert.False(t, response.Status, query)
+	}
+}
+
+// fetchTodoPage fetches a page of todos in cursor mode, returning it along with its Link header.
+func fetchTodoPage(t *testing.T, authToken string, query string) (dtos.PaginatedResponse[database.Todo], string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+"/todos?"+query, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response dtos.PaginatedResponse[database.Todo]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response, resp.Header.Get("Link")
+}
+
+func TestFetchTodos_CursorPagination(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	seeded := make([]uint, 0, 5)
+	for i := 0; i < 5; i++ {
+		seeded = append(seeded, SeedTodo(t, struct{}{}, user.ID).ID)
+	}
+
+	first, link := fetchTodoPage(t, authToken, "cursor=&per_page=2")
+	assert.Equal(t, seeded[:2], todoIds(first.Data))
+	assert.Equal(t, 5, first.Meta.TotalCount)
+	assert.Nil(t, first.Meta.PrevCursor)
+	require.NotNil(t, first.Meta.NextCursor)
+	assert.Contains(t, link, `rel="next"`)
+	assert.NotContains(t, link, `rel="prev"`)
+
+	// a todo created while paging neither shifts nor repeats the todos on later pages
+	SeedTodo(t, struct{}{}, user.ID)
+
+	second, link := fetchTodoPage(t, authToken, "per_page=2&cursor="+url.QueryEscape(*first.Meta.NextCursor))
+	assert.Equal(t, seeded[2:4], todoIds(second.Data))
+	require.NotNil(t, second.Meta.PrevCursor)
+	assert.Contains(t, link, `rel="prev"`)
+
+	previous, _ := fetchTodoPage(t, authToken, "per_page=2&cursor="+url.QueryEscape(*second.Meta.PrevCursor))
+	assert.Equal(t, seeded[:2], todoIds(previous.Data))
+	assert.Nil(t, previous.Meta.PrevCursor)
+	require.NotNil(t, previous.Meta.NextCursor)
+
+	// following the Link header keeps the query string, per_page included
+	nextUrl := strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+	next, _ := fetchTodoPage(t, authToken, strings.SplitN(nextUrl, "?", 2)[1])
+	assert.Len(t, next.Data, 2)
+	assert.Equal(t, seeded[4], next.Data[0].ID)
+	assert.Nil(t, next.Meta.NextCursor)
+}
+
+func TestFetchTodos_CursorPaginationBySortField(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	early := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	late := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	undated := SeedTodo(t, database.Todo{Priority: enums.PriorityLow}, user.ID)
+	lateTodo := SeedTodo(t, database.Todo{DueAt: &late, Priority: enums.PriorityUrgent}, user.ID)
+	earlyTodo := SeedTodo(t, database.Todo{DueAt: &early, Priority: enums.PriorityLow}, user.ID)
+	alsoLate := SeedTodo(t, database.Todo{DueAt: &late, Priority: enums.PriorityHigh}, user.ID)
+
+	tests := []struct {
+		description string
+		query       string
+		expectedIds []uint
+	}{
+		{
+			description: "due date ascending, undated first",
+			query:       "sort_by=due_at&order=asc",
+			expectedIds: []uint{undated.ID, earlyTodo.ID, lateTodo.ID, alsoLate.ID},
+		},
+		{
+			description: "due date descending, undated last",
+			query:       "sort_by=due_at&order=desc",
+			expectedIds: []uint{alsoLate.ID, lateTodo.ID, earlyTodo.ID, undated.ID},
+		},
+		{
+			description: "priority by rank",
+			query:       "sort_by=priority&order=desc",
+			expectedIds: []uint{lateTodo.ID, alsoLate.ID, earlyTodo.ID, undated.ID},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			forwards := make([]uint, 0)
+			page, _ := fetchTodoPage(t, authToken, test.query+"&per_page=1&cursor=")
+			for {
+				forwards = append(forwards, todoIds(page.Data)...)
+				if page.Meta.NextCursor == nil || len(forwards) > len(test.expectedIds) {
+					break
+				}
+				page, _ = fetchTodoPage(t, authToken, "per_page=1&cursor="+url.QueryEscape(*page.Meta.NextCursor))
+			}
+			assert.Equal(t, test.expectedIds, forwards)
+
+			backwards := todoIds(page.Data)
+			for page.Meta.PrevCursor != nil && len(backwards) <= len(test.expectedIds) {
+				page, _ = fetchTodoPage(t, authToken, "per_page=1&cursor="+url.QueryEscape(*page.Meta.PrevCursor))
+				backwards = append(todoIds(page.Data), backwards...)
+			}
+			assert.Equal(t, test.expectedIds, backwards)
+		})
+	}
+}
+
+func TestFetchTodos_RejectsInvalidCursors(t *testing.T) {
+	_, authToken, _ := setupFetchTodoTest(t, 3)
+
+	first, _ := fetchTodoPage(t, authToken, "cursor=&per_page=1")
+	require.NotNil(t, first.Meta.NextCursor)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, "/todos?cursor="+url.QueryEscape(*first.Meta.NextCursor+"x"), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid cursor", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/todos?cursor=not-a-cursor", authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// cursors are not signed with the key that signs access tokens
+	forged, err := dtos.Cursor{SortBy: "id", Order: dtos.OrderAsc, Value: json.RawMessage("0")}.Encode(env.FetchString("JWT_SECRET"))
+	require.NoError(t, err)
+	resp, response = sendAuthenticatedRequest(t, http.MethodGet, "/todos?cursor="+url.QueryEscape(forged), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid cursor", response.Message)
+}
@@ -0,0 +1,310 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type importResult struct {
+	DryRun   bool                  `json:"dry_run"`
+	Imported int                   `json:"imported"`
+	Todos    []database.Todo       `json:"todos"`
+	Errors   []dtos.ImportRowError `json:"errors"`
+}
+
+func importTodos(t *testing.T, authToken string, fields map[string]string, fileName string, contents string) (*http.Response, utils.JsonResponse[importResult]) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, TestServerInstance.Server.URL+"/import", body)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response utils.JsonResponse[importResult]
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	return resp, response
+}
+
+// importedTodos returns the user's todos with their checklists nested the way the API returns them.
+func importedTodos(t *testing.T, authToken string, userId uint) []database.Todo {
+	t.Helper()
+
+	var ids []uint
+	require.NoError(t, TestServerInstance.DB.Model(&database.Todo{}).Where("user_id = ?", userId).Order("position asc").Pluck("id", &ids).Error)
+
+	todos := make([]database.Todo, 0, len(ids))
+	for _, id := range ids {
+		todos = append(todos, fetchTodo(t, authToken, id))
+	}
+	return todos
+}
+
+func descriptions(items []database.Checklist) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.Description)
+	}
+	return result
+}
+
+const todoistExport = `TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE
+section,Work,,,,,,,,
+task,Ship the release,Cut and tag it,1,1,Ada (1),,2030-03-01,en,UTC
+task,Write changelog,,4,2,Ada (1),,,en,UTC
+task,Proofread,,4,3,Ada (1),,,en,UTC
+task,Tag,,4,2,Ada (1),,,en,UTC
+task,Water plants,,3,1,Ada (1),,every day,en,UTC
+task,Orphaned step,,4,2,Ada (1),,,en,UTC
+task,Call mum,Sunday evening,9,1,Ada (1),,,en,UTC
+task,Buy milk,,4,1,Ada (1),,,en,UTC
+`
+
+func TestImport_Todoist(t *testing.T) {
+	user, authToken := setupTest(t)
+
+	resp, response := importTodos(t, authToken, map[string]string{"format": "todoist"}, "export.csv", todoistExport)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	assert.Equal(t, "Todos imported successfully", response.Message)
+	assert.Equal(t, 2, response.Data.Imported)
+	assert.Equal(t, []dtos.ImportRowError{
+		{Row: 7, Error: `due date "every day" is not a date`},
+		{Row: 8, Error: "sub-task has no parent task"},
+		{Row: 9, Error: "priority must be between 1 and 4"},
+	}, response.Data.Errors)
+
+	todos := importedTodos(t, authToken, user.ID)
+	require.Len(t, todos, 2)
+
+	release := todos[0]
+	assert.Equal(t, "Ship the release", release.Title)
+	assert.Equal(t, enums.Checklist, release.Type)
+	assert.Nil(t, release.Content)
+	assert.Equal(t, enums.PriorityUrgent, release.Priority)
+	require.NotNil(t, release.DueAt)
+	assert.True(t, release.DueAt.Equal(time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, []string{"Write changelog", "Tag"}, descriptions(release.Checklists))
+	assert.Equal(t, []string{"Proofread"}, descriptions(release.Checklists[0].Children))
+
+	milk := todos[1]
+	assert.Equal(t, "Buy milk", milk.Title)
+	assert.Equal(t, enums.Text, milk.Type)
+	assert.Equal(t, enums.PriorityNone, milk.Priority)
+}
+
+const trelloExport = `{
+  "name": "Launch",
+  "lists": [{"id": "l1", "name": "Doing", "closed": false}, {"id": "l2", "name": "Old", "closed": true}],
+  "cards": [
+    {"id": "c1", "name": "Landing page", "desc": "", "idList": "l1", "closed": false, "due": "2030-05-01T09:00:00.000Z", "dueComplete": false},
+    {"id": "c2", "name": "Press kit", "desc": "Logos and screenshots", "idList": "l1", "closed": false, "due": null, "dueComplete": true},
+    {"id": "c3", "name": "  ", "desc": "", "idList": "l1", "closed": false},
+    {"id": "c4", "name": "Old idea", "desc": "Maybe later", "idList": "l2", "closed": false}
+  ],
+  "checklists": [
+    {"id": "k2", "idCard": "c1", "name": "Launch", "pos": 2, "checkItems": [{"name": "Go live", "state": "incomplete", "pos": 1}]},
+    {"id": "k1", "idCard": "c1", "name": "Copy", "pos": 1, "checkItems": [
+      {"name": "Proofread", "state": "complete", "pos": 2},
+      {"name": "Write", "state": "complete", "pos": 1}
+    ]}
+  ]
+}`
+
+func TestImport_Trello(t *testing.T) {
+	user, authToken := setupTest(t)
+
+	resp, response := importTodos(t, authToken, map[string]string{"format": "trello"}, "board.json", trelloExport)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	assert.Equal(t, 3, response.Data.Imported)
+	assert.Equal(t, []dtos.ImportRowError{{Row: 3, Error: "card has no name"}}, response.Data.Errors)
+
+	var todos []database.Todo
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).Order("position asc").Find(&todos).Error)
+	require.Len(t, todos, 3)
+
+	landing := fetchTodo(t, authToken, todos[0].ID)
+	assert.Equal(t, enums.Checklist, landing.Type)
+	require.NotNil(t, landing.DueAt)
+	assert.True(t, landing.DueAt.Equal(time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC)))
+	require.Equal(t, []string{"Copy", "Launch"}, descriptions(landing.Checklists))
+	assert.Equal(t, []string{"Write", "Proofread"}, descriptions(landing.Checklists[0].Children))
+	assert.Equal(t, []string{"Go live"}, descriptions(landing.Checklists[1].Children))
+	assert.False(t, landing.Completed)
+
+	pressKit := todos[1]
+	assert.Equal(t, enums.Text, pressKit.Type)
+	assert.Equal(t, "Logos and screenshots", *pressKit.Content)
+	assert.True(t, pressKit.Completed)
+	var completions int64
+	TestServerInstance.DB.Model(&database.TodoCompletion{}).Where("todo_id = ?", pressKit.ID).Count(&completions)
+	assert.Equal(t, int64(1), completions)
+
+	// cards in archived lists are imported archived
+	assert.Equal(t, "Old idea", todos[2].Title)
+	assert.NotNil(t, todos[2].ArchivedAt)
+}
+
+const markdownChecklist = `Things to pack
+
+- [x] Passport
+- [ ] Charger
+
+## Clothes
+* [x] Socks
+    - [x] Wool socks
+* [X] Jumper
+- [?] Hat
+
+# Empty heading
+`
+
+func TestImport_MarkdownDryRun(t *testing.T) {
+	user, authToken := setupTest(t)
+
+	resp, response := importTodos(t, authToken, map[string]string{"format": "markdown", "dry_run": "true"}, "holiday.md", markdownChecklist)
+	require.Equal(t, http.StatusOK, resp.StatusCode, response.Message)
+	assert.Equal(t, "Import previewed successfully", response.Message)
+	assert.True(t, response.Data.DryRun)
+	assert.Zero(t, response.Data.Imported)
+	assert.Equal(t, []dtos.ImportRowError{{Row: 10, Error: "checklist items must start with - [ ] or - [x]"}}, response.Data.Errors)
+
+	preview := response.Data.Todos
+	require.Len(t, preview, 2)
+	assert.Equal(t, "holiday", preview[0].Title)
+	assert.Equal(t, []string{"Passport", "Charger"}, descriptions(preview[0].Checklists))
+	assert.False(t, preview[0].Completed)
+	assert.Equal(t, "Clothes", preview[1].Title)
+	assert.Equal(t, []string{"Socks", "Jumper"}, descriptions(preview[1].Checklists))
+	assert.Equal(t, []string{"Wool socks"}, descriptions(preview[1].Checklists[0].Children))
+	assert.True(t, preview[1].Completed)
+
+	// nothing is created on a dry run
+	var count int64
+	TestServerInstance.DB.Model(&database.Todo{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Zero(t, count)
+
+	resp, response = importTodos(t, authToken, map[string]string{"format": "markdown"}, "holiday.md", markdownChecklist)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	assert.Equal(t, 2, response.Data.Imported)
+
+	todos := importedTodos(t, authToken, user.ID)
+	require.Len(t, todos, 2)
+	assert.Equal(t, []string{"Socks", "Jumper"}, descriptions(todos[1].Checklists))
+	assert.Equal(t, []string{"Wool socks"}, descriptions(todos[1].Checklists[0].Children))
+	assert.True(t, todos[1].Completed)
+}
+
+func TestImport_KeepsCompletionFromTheFile(t *testing.T) {
+	_, authToken := setupTest(t)
+
+	board := `{
+  "lists": [{"id": "l1", "closed": false}],
+  "cards": [
+    {"id": "c1", "name": "Done early", "idList": "l1", "dueComplete": true},
+    {"id": "c2", "name": "Still open", "idList": "l1", "dueComplete": false}
+  ],
+  "checklists": [
+    {"idCard": "c1", "name": "Steps", "checkItems": [{"name": "Skipped", "state": "incomplete"}]},
+    {"idCard": "c2", "name": "Steps", "checkItems": [{"name": "Checked", "state": "complete"}]}
+  ]
+}`
+	resp, response := importTodos(t, authToken, map[string]string{"format": "trello", "dry_run": "true"}, "board.json", board)
+	require.Equal(t, http.StatusOK, resp.StatusCode, response.Message)
+	require.Len(t, response.Data.Todos, 2)
+	assert.True(t, response.Data.Todos[0].Completed)
+	assert.False(t, response.Data.Todos[1].Completed)
+
+	export := "TYPE,CONTENT,INDENT,CHECKED\ntask,Done early,1,true\ntask,Skipped,2,false\ntask,Still open,1,false\ntask,Checked,2,true\n"
+	resp, response = importTodos(t, authToken, map[string]string{"format": "todoist", "dry_run": "true"}, "export.csv", export)
+	require.Equal(t, http.StatusOK, resp.StatusCode, response.Message)
+	require.Len(t, response.Data.Todos, 2)
+	assert.True(t, response.Data.Todos[0].Completed)
+	assert.False(t, response.Data.Todos[0].Checklists[0].Done)
+	assert.False(t, response.Data.Todos[1].Completed)
+	assert.True(t, response.Data.Todos[1].Checklists[0].Done)
+}
+
+func TestImport_MarkdownLongLines(t *testing.T) {
+	_, authToken := setupTest(t)
+
+	long := strings.Repeat("a", 100*1024)
+	resp, response := importTodos(t, authToken, map[string]string{"format": "markdown", "dry_run": "true"}, "notes.md", "- [ ] Short\n"+long+"\n- [x] Also short\n")
+	require.Equal(t, http.StatusOK, resp.StatusCode, response.Message)
+	require.Len(t, response.Data.Todos, 1)
+	assert.Equal(t, []string{"Short", "Also short"}, descriptions(response.Data.Todos[0].Checklists))
+}
+
+func TestImport_RejectsInvalidFiles(t *testing.T) {
+	_, authToken := setupTest(t)
+
+	tests := []struct {
+		name               string
+		fields             map[string]string
+		contents           string
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "unknown format",
+			fields:             map[string]string{"format": "asana"},
+			contents:           "- [ ] Something",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "import format must be todoist, trello or markdown",
+		},
+		{
+			name:               "not a trello board",
+			fields:             map[string]string{"format": "trello"},
+			contents:           "- [ ] Something",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "file is not a Trello board export",
+		},
+		{
+			name:               "not a todoist export",
+			fields:             map[string]string{"format": "todoist"},
+			contents:           "title,notes\nSomething,else\n",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "file is not a Todoist CSV export",
+		},
+		{
+			name:               "invalid dry run",
+			fields:             map[string]string{"format": "markdown", "dry_run": "maybe"},
+			contents:           "- [ ] Something",
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedMsg:        "dry_run must be true or false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := importTodos(t, authToken, tt.fields, "upload.txt", tt.contents)
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedMsg, response.Message)
+		})
+	}
+}
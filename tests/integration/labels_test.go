@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func SeedLabel[T any](t *testing.T, input T, userId uint) *database.Label {
+	t.Helper()
+
+	label := database.Label{
+		Name:   "work",
+		Colour: "#ff0000",
+		UserID: userId,
+	}
+
+	mergeStruct[T](t, &label, input)
+	result := TestServerInstance.DB.Create(&label)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	return &label
+}
+
+func TestCreateLabel(t *testing.T) {
+	tests := []struct {
+		name               string
+		request            map[string]string
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "success",
+			request:            map[string]string{"name": "personal", "colour": "#00ff00"},
+			expectedStatusCode: http.StatusCreated,
+			expectedMsg:        "Label created successfully",
+		},
+		{
+			name:               "duplicate name",
+			request:            map[string]string{"name": "Work", "colour": "#00ff00"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "label already exists",
+		},
+		{
+			name:               "invalid colour",
+			request:            map[string]string{"name": "personal", "colour": "green"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			SeedLabel(t, struct{}{}, user.ID)
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/labels", authToken, tt.request)
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+}
+
+func TestUpdateAndDeleteLabel(t *testing.T) {
+	user, authToken := setupTest(t)
+	label := SeedLabel(t, struct{}{}, user.ID)
+	todo := SeedTodo(t, struct{}{}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Model(todo).Association("Labels").Append(label))
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/labels/%d", label.ID), authToken, map[string]string{"name": "office", "colour": "#0000ff"})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	updatedLabel := database.Label{}
+	require.NoError(t, TestServerInstance.DB.First(&updatedLabel, label.ID).Error)
+	assert.Equal(t, "office", updatedLabel.Name)
+	assert.Equal(t, "#0000ff", updatedLabel.Colour)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/labels/%d", label.ID), authToken, nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, int64(0), TestServerInstance.DB.Model(todo).Association("Labels").Count())
+
+	anotherUser := SeedUser(t, database.User{Email: "another@gmail.com"})
+	anotherLabel := SeedLabel(t, struct{}{}, anotherUser.ID)
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/labels/%d", anotherLabel.ID), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "label does not exist", response.Message)
+}
+
+func TestAttachAndDetachTodoLabels(t *testing.T) {
+	user, authToken := setupTest(t)
+	work := SeedLabel(t, struct{}{}, user.ID)
+	urgent := SeedLabel(t, database.Label{Name: "urgent"}, user.ID)
+	todo := SeedTodo(t, struct{}{}, user.ID)
+
+	anotherUser := SeedUser(t, database.User{Email: "another@gmail.com"})
+	anotherLabel := SeedLabel(t, struct{}{}, anotherUser.ID)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/labels", todo.ID), authToken, map[string][]uint{"label_ids": {work.ID, anotherLabel.ID}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "label does not exist", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/labels", todo.ID), authToken, map[string][]uint{"label_ids": {work.ID, urgent.ID, work.ID}})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, int64(2), TestServerInstance.DB.Model(todo).Association("Labels").Count())
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/labels/%d", todo.ID, work.ID), authToken, nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var labels []database.Label
+	require.NoError(t, TestServerInstance.DB.Model(todo).Association("Labels").Find(&labels))
+	require.Len(t, labels, 1)
+	assert.Equal(t, urgent.ID, labels[0].ID)
+}
+
+func TestFetchTodos_LabelFilters(t *testing.T) {
+	user, authToken := setupTest(t)
+	work := SeedLabel(t, struct{}{}, user.ID)
+	urgent := SeedLabel(t, database.Label{Name: "urgent"}, user.ID)
+
+	workTodo := SeedTodo(t, struct{}{}, user.ID)
+	urgentTodo := SeedTodo(t, struct{}{}, user.ID)
+	urgentWorkTodo := SeedTodo(t, struct{}{}, user.ID)
+	SeedTodo(t, struct{}{}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Model(workTodo).Association("Labels").Append(work))
+	require.NoError(t, TestServerInstance.DB.Model(urgentTodo).Association("Labels").Append(urgent))
+	require.NoError(t, TestServerInstance.DB.Model(urgentWorkTodo).Association("Labels").Append(work, urgent))
+
+	tests := []struct {
+		description string
+		query       string
+		expectedIds []uint
+	}{
+		{
+			description: "todos with a label",
+			query:       fmt.Sprintf("filters[labels]=%d", work.ID),
+			expectedIds: []uint{workTodo.ID, urgentWorkTodo.ID},
+		},
+		{
+			description: "todos with any of the labels",
+			query:       fmt.Sprintf("filters[labels]=%d,%d", work.ID, urgent.ID),
+			expectedIds: []uint{workTodo.ID, urgentTodo.ID, urgentWorkTodo.ID},
+		},
+		{
+			description: "todos with all of the labels",
+			query:       fmt.Sprintf("filters[labels_all]=%d,%d", work.ID, urgent.ID),
+			expectedIds: []uint{urgentWorkTodo.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			response := fetchTodos(t, authToken, tt.query)
+			assert.ElementsMatch(t, tt.expectedIds, todoIds(response.Data))
+		})
+	}
+
+	response := fetchTodos(t, authToken, fmt.Sprintf("filters[labels_all]=%d", urgent.ID))
+	for _, todo := range response.Data {
+		assert.NotEmpty(t, todo.Labels, "expected labels to be preloaded")
+	}
+}
@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func fetchTodo(t *testing.T, authToken string, todoId uint) database.Todo {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/todos/%d", TestServerInstance.Server.URL, todoId), nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data database.Todo `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response.Data
+}
+
+func TestAddChecklistSubItem(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	parent := SeedChecklist(t, database.Checklist{Description: "plan"}, todo.ID)
+	SeedChecklist(t, database.Checklist{Description: "ship"}, todo.ID)
+
+	otherTodo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	otherItem := SeedChecklist(t, struct{}{}, otherTodo.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/checklist", todo.ID), authToken, dtos.ChecklistItem{Item: "draft", ParentID: &parent.ID})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	fetched := fetchTodo(t, authToken, todo.ID)
+	require.Len(t, fetched.Checklists, 2, "sub-items should be nested under their parent")
+	assert.Equal(t, "plan", fetched.Checklists[0].Description)
+	require.Len(t, fetched.Checklists[0].Children, 1)
+	assert.Equal(t, "draft", fetched.Checklists[0].Children[0].Description)
+	assert.Equal(t, database.ChecklistProgress{Done: 0, Total: 3}, fetched.Progress)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/checklist", todo.ID), authToken, dtos.ChecklistItem{Item: "draft", ParentID: &otherItem.ID})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "parent checklist not found", response.Message)
+}
+
+func TestAddChecklistSubItem_MaxDepth(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	parent := SeedChecklist(t, struct{}{}, todo.ID)
+	for depth := 2; depth <= 3; depth++ {
+		parent = SeedChecklist(t, database.Checklist{ParentID: &parent.ID}, todo.ID)
+	}
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/checklist", todo.ID), authToken, dtos.ChecklistItem{Item: "too deep", ParentID: &parent.ID})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "checklist items can only be nested 3 levels deep", response.Message)
+}
+
+func TestCheckingChecklistItemChecksSubItems(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	parent := SeedChecklist(t, struct{}{}, todo.ID)
+	child := SeedChecklist(t, database.Checklist{ParentID: &parent.ID}, todo.ID)
+	grandchild := SeedChecklist(t, database.Checklist{ParentID: &child.ID}, todo.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, parent.ID), authToken, dtos.ChecklistStatus{Done: true})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var done []uint
+	TestServerInstance.DB.Model(&database.Checklist{}).Where("done = ?", true).Order("id").Pluck("id", &done)
+	assert.Equal(t, []uint{parent.ID, child.ID, grandchild.ID}, done)
+
+	completedTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.First(&completedTodo, todo.ID).Error)
+	assert.True(t, completedTodo.Completed)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, parent.ID), authToken, dtos.ChecklistStatus{Done: false})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	TestServerInstance.DB.Model(&database.Checklist{}).Where("done = ?", true).Order("id").Pluck("id", &done)
+	assert.Equal(t, []uint{child.ID, grandchild.ID}, done, "unchecking should leave sub-items alone")
+}
+
+func TestDeletingChecklistItemDeletesSubItems(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	parent := SeedChecklist(t, struct{}{}, todo.ID)
+	child := SeedChecklist(t, database.Checklist{ParentID: &parent.ID}, todo.ID)
+	SeedChecklist(t, database.Checklist{ParentID: &child.ID}, todo.ID)
+	sibling := SeedChecklist(t, struct{}{}, todo.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, parent.ID), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var remaining []uint
+	TestServerInstance.DB.Model(&database.Checklist{}).Pluck("id", &remaining)
+	assert.Equal(t, []uint{sibling.ID}, remaining)
+}
+
+func TestFetchTodos_ChecklistProgress(t *testing.T) {
+	user, authToken := setupTest(t)
+	checklistTodo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	parent := SeedChecklist(t, database.Checklist{Done: true}, checklistTodo.ID)
+	SeedChecklist(t, database.Checklist{ParentID: &parent.ID, Done: true}, checklistTodo.ID)
+	SeedChecklist(t, struct{}{}, checklistTodo.ID)
+	textTodo := SeedTodo(t, struct{}{}, user.ID)
+
+	response := fetchTodos(t, authToken, "")
+	progress := make(map[uint]database.ChecklistProgress)
+	for _, todo := range response.Data {
+		progress[todo.ID] = todo.Progress
+	}
+
+	assert.Equal(t, database.ChecklistProgress{Done: 2, Total: 3}, progress[checklistTodo.ID])
+	assert.Equal(t, database.ChecklistProgress{}, progress[textTodo.ID])
+}
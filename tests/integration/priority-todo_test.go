@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCreateTodo_Priority(t *testing.T) {
+	tests := []struct {
+		name               string
+		priority           string
+		expectedStatusCode int
+		expectedPriority   enums.TodoPriority
+	}{
+		{
+			name:               "priority is stored",
+			priority:           "urgent",
+			expectedStatusCode: http.StatusCreated,
+			expectedPriority:   enums.PriorityUrgent,
+		},
+		{
+			name:               "priority defaults to none",
+			expectedStatusCode: http.StatusCreated,
+			expectedPriority:   enums.PriorityNone,
+		},
+		{
+			name:               "unknown priority is rejected",
+			priority:           "critical",
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			request := map[string]string{"title": "Triage", "content": "inbox", "type": "text"}
+			if tt.priority != "" {
+				request["priority"] = tt.priority
+			}
+
+			resp, _ := sendAuthenticatedRequest(t, http.MethodPost, "/todos", authToken, request)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+
+			if tt.expectedPriority != "" {
+				todo := database.Todo{}
+				require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).First(&todo).Error)
+				assert.Equal(t, tt.expectedPriority, todo.Priority)
+			}
+		})
+	}
+}
+
+func TestUpdateTodo_KeepsPriorityWhenLeftOut(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Priority: enums.PriorityHigh}, user.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d", todo.ID), authToken, map[string]string{"title": "Renamed", "content": "inbox", "type": "text"})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	updatedTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.First(&updatedTodo, todo.ID).Error)
+	assert.Equal(t, enums.PriorityHigh, updatedTodo.Priority)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d", todo.ID), authToken, map[string]string{"title": "Renamed", "content": "inbox", "type": "text", "priority": "low"})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, TestServerInstance.DB.First(&updatedTodo, todo.ID).Error)
+	assert.Equal(t, enums.PriorityLow, updatedTodo.Priority)
+}
+
+func TestFetchTodos_Priority(t *testing.T) {
+	user, authToken := setupTest(t)
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	nextWeek := time.Now().AddDate(0, 0, 7)
+
+	low := SeedTodo(t, database.Todo{Priority: enums.PriorityLow}, user.ID)
+	urgent := SeedTodo(t, database.Todo{Priority: enums.PriorityUrgent}, user.ID)
+	highDueNextWeek := SeedTodo(t, database.Todo{Priority: enums.PriorityHigh, DueAt: &nextWeek}, user.ID)
+	highDueTomorrow := SeedTodo(t, database.Todo{Priority: enums.PriorityHigh, DueAt: &tomorrow}, user.ID)
+	highUndated := SeedTodo(t, database.Todo{Priority: enums.PriorityHigh}, user.ID)
+	pinnedNone := SeedTodo(t, database.Todo{Pinned: true}, user.ID)
+
+	t.Run("default order is pinned, then priority, then due date", func(t *testing.T) {
+		response := fetchTodos(t, authToken, "")
+		expectedIds := []uint{pinnedNone.ID, urgent.ID, highDueTomorrow.ID, highDueNextWeek.ID, highUndated.ID, low.ID}
+		assert.Equal(t, expectedIds, todoIds(response.Data))
+	})
+
+	t.Run("sorting by priority uses its rank", func(t *testing.T) {
+		response := fetchTodos(t, authToken, "sort_by=priority&order=desc")
+		priorities := make([]enums.TodoPriority, 0, len(response.Data))
+		for _, todo := range response.Data {
+			priorities = append(priorities, todo.Priority)
+		}
+		expectedPriorities := []enums.TodoPriority{
+			enums.PriorityUrgent,
+			enums.PriorityHigh,
+			enums.PriorityHigh,
+			enums.PriorityHigh,
+			enums.PriorityLow,
+			enums.PriorityNone,
+		}
+		assert.Equal(t, expectedPriorities, priorities)
+	})
+
+	t.Run("filtering by priority", func(t *testing.T) {
+		response := fetchTodos(t, authToken, "filters[priority]=high")
+		assert.ElementsMatch(t, []uint{highDueTomorrow.ID, highDueNextWeek.ID, highUndated.ID}, todoIds(response.Data))
+	})
+}
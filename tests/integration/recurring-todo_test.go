@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCreateRecurringTodo(t *testing.T) {
+	dueAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	content := "weekly report"
+	weeklyOnFriday := "RRULE:freq=weekly;byday=fr"
+	weekly := "FREQ=WEEKLY"
+	hourly := "FREQ=HOURLY"
+
+	tests := []struct {
+		name               string
+		request            dtos.CreateTodoDTO
+		expectedStatusCode int
+		expectedMsg        string
+		expectedRecurrence string
+	}{
+		{
+			name:               "recurrence is stored in canonical form",
+			request:            dtos.CreateTodoDTO{Title: "Report", Content: &content, Type: enums.Text, DueAt: &dueAt, Recurrence: &weeklyOnFriday},
+			expectedStatusCode: http.StatusCreated,
+			expectedRecurrence: "FREQ=WEEKLY;BYDAY=FR",
+		},
+		{
+			name:               "recurrence requires a due date",
+			request:            dtos.CreateTodoDTO{Title: "Report", Content: &content, Type: enums.Text, Recurrence: &weekly},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "recurring todos must have a due date",
+		},
+		{
+			name:               "invalid recurrence is rejected",
+			request:            dtos.CreateTodoDTO{Title: "Report", Content: &content, Type: enums.Text, DueAt: &dueAt, Recurrence: &hourly},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "invalid recurrence: unsupported recurrence frequency: HOURLY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			jsonRequest, err := json.Marshal(tt.request)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, TestServerInstance.Server.URL+"/todos", bytes.NewBuffer(jsonRequest))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+authToken)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				var response utils.JsonResponse[interface{}]
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			if tt.expectedRecurrence != "" {
+				todo := database.Todo{}
+				require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).First(&todo).Error)
+				require.NotNil(t, todo.Recurrence)
+				assert.Equal(t, tt.expectedRecurrence, *todo.Recurrence)
+			}
+		})
+	}
+}
+
+func TestCompletingRecurringChecklistSpawnsNextOccurrence(t *testing.T) {
+	//ARRANGE
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+	authToken := GenerateTestJwtToken(t, user.ID)
+
+	dueAt := time.Date(2025, time.March, 7, 17, 0, 0, 0, time.Local)
+	startAt := dueAt.Add(-2 * time.Hour)
+	recurrence := "FREQ=WEEKLY"
+	todo := SeedTodo(t, database.Todo{
+		Type:       enums.Checklist,
+		StartAt:    &startAt,
+		DueAt:      &dueAt,
+		Recurrence: &recurrence,
+	}, user.ID)
+	SeedChecklist(t, database.Checklist{Description: "collect numbers", Done: true}, todo.ID)
+	lastItem := SeedChecklist(t, database.Checklist{Description: "send report"}, todo.ID)
+
+	//ACT
+	jsonRequest, err := json.Marshal(dtos.ChecklistStatus{Done: true})
+	require.NoError(t, err)
+	url := fmt.Sprintf("%s/todos/%d/checklist/%d", TestServerInstance.Server.URL, todo.ID, lastItem.ID)
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonRequest))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	//ASSERT
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	completedTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.First(&completedTodo, todo.ID).Error)
+	assert.Nil(t, completedTodo.Recurrence)
+
+	nextTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.Preload("Checklists").Where("id <> ?", todo.ID).First(&nextTodo).Error)
+	assert.Equal(t, todo.Title, nextTodo.Title)
+	assert.Equal(t, 2, nextTodo.Occurrence)
+	require.NotNil(t, nextTodo.Recurrence)
+	assert.Equal(t, recurrence, *nextTodo.Recurrence)
+	require.NotNil(t, nextTodo.DueAt)
+	assert.True(t, dueAt.AddDate(0, 0, 7).Equal(*nextTodo.DueAt))
+	require.NotNil(t, nextTodo.StartAt)
+	assert.True(t, startAt.AddDate(0, 0, 7).Equal(*nextTodo.StartAt))
+
+	require.Len(t, nextTodo.Checklists, 2)
+	for _, checklist := range nextTodo.Checklists {
+		assert.False(t, checklist.Done)
+	}
+}
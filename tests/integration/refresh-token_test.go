@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func login(t *testing.T) dtos.AuthTokens {
+	t.Helper()
+	return loginFrom(t, "", "")
+}
+
+// loginFrom logs in from a device with the given user agent, naming it deviceName when that is set.
+func loginFrom(t *testing.T, userAgent string, deviceName string) dtos.AuthTokens {
+	t.Helper()
+
+	request := loginRequest
+	request.DeviceName = deviceName
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, TestServerInstance.Server.URL+"/auth/login", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response utils.JsonResponse[dtos.LoginUserResponseDto]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response.Data.AuthTokens
+}
+
+func refresh(t *testing.T, refreshToken string) (*http.Response, utils.JsonResponse[dtos.AuthTokens]) {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	require.NoError(t, err)
+	resp, err := http.Post(TestServerInstance.Server.URL+"/auth/refresh", "application/json", bytes.NewBuffer(request))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response utils.JsonResponse[dtos.AuthTokens]
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	return resp, response
+}
+
+func TestRefreshToken_Rotates(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	SeedUser[dtos.LoginUserDTO](t, loginRequest)
+
+	tokens := login(t)
+	assert.NotEmpty(t, tokens.Token.Token)
+	assert.NotEmpty(t, tokens.RefreshToken.Token)
+
+	// only a hash of the refresh token is stored
+	var stored database.RefreshToken
+	require.NoError(t, TestServerInstance.DB.First(&stored).Error)
+	assert.Equal(t, utils.HashToken(tokens.RefreshToken.Token), stored.TokenHash)
+
+	resp, response := refresh(t, tokens.RefreshToken.Token)
+	require.Equal(t, http.StatusOK, resp.StatusCode, response.Message)
+	assert.Equal(t, "token refreshed", response.Message)
+	rotated := response.Data
+	assert.NotEqual(t, tokens.RefreshToken.Token, rotated.RefreshToken.Token)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", rotated.Token.Token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// using a rotated refresh token again revokes everything descending from the same login
+	resp, response = refresh(t, tokens.RefreshToken.Token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token has already been used", response.Message)
+
+	resp, response = refresh(t, rotated.RefreshToken.Token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token is invalid", response.Message)
+}
+
+func TestRefreshToken_Rejected(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	SeedUser[dtos.LoginUserDTO](t, loginRequest)
+	tokens := login(t)
+
+	resp, _ := refresh(t, "")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	resp, response := refresh(t, "not-a-refresh-token")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token is invalid", response.Message)
+
+	// an access token is not a refresh token
+	resp, response = refresh(t, tokens.Token.Token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token is invalid", response.Message)
+
+	require.NoError(t, TestServerInstance.DB.Model(&database.RefreshToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	resp, response = refresh(t, tokens.RefreshToken.Token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token has expired", response.Message)
+}
+
+func TestLogout_RevokesRefreshTokenFamily(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	SeedUser[dtos.LoginUserDTO](t, loginRequest)
+	laptop := login(t)
+	phone := login(t)
+
+	// the family is revoked even after its refresh token has been rotated
+	resp, response := refresh(t, laptop.RefreshToken.Token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	laptop = response.Data
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPost, "/auth/logout", laptop.Token.Token, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", laptop.Token.Token, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, response = refresh(t, laptop.RefreshToken.Token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token is invalid", response.Message)
+
+	// other logins are left alone
+	resp, _ = refresh(t, phone.RefreshToken.Token)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,307 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/pkg"
+	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type CreateReminderSetupResponse struct {
+	User      *database.User
+	AuthToken string
+	Todo      *database.Todo
+	Request   map[string]interface{}
+}
+
+func TestCreateReminder(t *testing.T) {
+	tests := []struct {
+		name               string
+		setupFunc          func(t *testing.T) CreateReminderSetupResponse
+		expectedStatusCode int
+		expectedMsg        string
+		expectedRemindAt   func(setup CreateReminderSetupResponse) time.Time
+	}{
+		{
+			name: "absolute reminder",
+			setupFunc: func(t *testing.T) CreateReminderSetupResponse {
+				setup := setupCreateReminderTest(t, nil)
+				setup.Request = map[string]interface{}{"remind_at": time.Now().Add(time.Hour).Truncate(time.Second)}
+				return setup
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedMsg:        "Reminder created successfully",
+			expectedRemindAt: func(setup CreateReminderSetupResponse) time.Time {
+				return setup.Request["remind_at"].(time.Time)
+			},
+		},
+		{
+			name: "reminder relative to the due date",
+			setupFunc: func(t *testing.T) CreateReminderSetupResponse {
+				dueAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+				setup := setupCreateReminderTest(t, &dueAt)
+				setup.Request = map[string]interface{}{"offset_minutes": 30}
+				return setup
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedMsg:        "Reminder created successfully",
+			expectedRemindAt: func(setup CreateReminderSetupResponse) time.Time {
+				return setup.Todo.DueAt.Add(-30 * time.Minute)
+			},
+		},
+		{
+			name: "relative reminder without a due date",
+			setupFunc: func(t *testing.T) CreateReminderSetupResponse {
+				setup := setupCreateReminderTest(t, nil)
+				setup.Request = map[string]interface{}{"offset_minutes": 30}
+				return setup
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "todo does not have a due date to be reminded before",
+		},
+		{
+			name: "reminder in the past",
+			setupFunc: func(t *testing.T) CreateReminderSetupResponse {
+				setup := setupCreateReminderTest(t, nil)
+				setup.Request = map[string]interface{}{"remind_at": time.Now().Add(-time.Hour)}
+				return setup
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "reminder time has already passed",
+		},
+		{
+			name: "reminder needs a time or an offset",
+			setupFunc: func(t *testing.T) CreateReminderSetupResponse {
+				setup := setupCreateReminderTest(t, nil)
+				setup.Request = map[string]interface{}{}
+				return setup
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "another user's todo",
+			setupFunc: func(t *testing.T) CreateReminderSetupResponse {
+				setup := setupCreateReminderTest(t, nil)
+				anotherUser := SeedUser(t, database.User{Email: "another@gmail.com"})
+				setup.Todo = SeedTodo(t, struct{}{}, anotherUser.ID)
+				setup.Request = map[string]interface{}{"remind_at": time.Now().Add(time.Hour)}
+				return setup
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "todo does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup := tt.setupFunc(t)
+			jsonRequest, err := json.Marshal(setup.Request)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("%s/todos/%d/reminders", TestServerInstance.Server.URL, setup.Todo.ID)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonRequest))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+setup.AuthToken)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				var response utils.JsonResponse[interface{}]
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			if tt.expectedRemindAt != nil {
+				reminder := database.Reminder{}
+				require.NoError(t, TestServerInstance.DB.Where("todo_id = ?", setup.Todo.ID).First(&reminder).Error)
+				assert.True(t, tt.expectedRemindAt(setup).Equal(reminder.RemindAt), "expected %v, got %v", tt.expectedRemindAt(setup), reminder.RemindAt)
+				assert.Nil(t, reminder.SentAt)
+			}
+		})
+	}
+}
+
+func setupCreateReminderTest(t *testing.T, dueAt *time.Time) CreateReminderSetupResponse {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+	todo := SeedTodo(t, database.Todo{DueAt: dueAt}, user.ID)
+	return CreateReminderSetupResponse{
+		User:      user,
+		AuthToken: GenerateTestJwtToken(t, user.ID),
+		Todo:      todo,
+	}
+}
+
+func TestDispatchDueReminders(t *testing.T) {
+	//ARRANGE
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+	todo := SeedTodo(t, struct{}{}, user.ID)
+	deletedTodo := SeedTodo(t, struct{}{}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Delete(deletedTodo).Error)
+
+	missedReminder := database.Reminder{TodoID: todo.ID, UserID: user.ID, RemindAt: time.Now().Add(-time.Hour)}
+	futureReminder := database.Reminder{TodoID: todo.ID, UserID: user.ID, RemindAt: time.Now().Add(time.Hour)}
+	deletedTodoReminder := database.Reminder{TodoID: deletedTodo.ID, UserID: user.ID, RemindAt: time.Now().Add(-time.Hour)}
+	require.NoError(t, TestServerInstance.DB.Create(&missedReminder).Error)
+	require.NoError(t, TestServerInstance.DB.Create(&futureReminder).Error)
+	require.NoError(t, TestServerInstance.DB.Create(&deletedTodoReminder).Error)
+
+	sseService := TestServerInstance.App.SSEContainer.SSEService
+	client := &sse.ConnectedClient{
+		Data: make(chan dtos.SSEData, 1),
+		Quit: make(chan struct{}),
+		Done: make(chan struct{}),
+	}
+	sseService.AddClient(user.ID, client)
+	defer sseService.RemoveClients(user.ID)
+
+	//ACT
+	TestServerInstance.App.ReminderContainer.ReminderService.DispatchDueReminders(context.Background())
+
+	//ASSERT
+	require.NoError(t, TestServerInstance.DB.First(&missedReminder, missedReminder.ID).Error)
+	assert.NotNil(t, missedReminder.SentAt)
+	require.NoError(t, TestServerInstance.DB.First(&futureReminder, futureReminder.ID).Error)
+	assert.Nil(t, futureReminder.SentAt)
+	require.NoError(t, TestServerInstance.DB.First(&deletedTodoReminder, deletedTodoReminder.ID).Error)
+	assert.Nil(t, deletedTodoReminder.SentAt)
+
+	select {
+	case message := <-client.Data:
+		assert.Equal(t, dtos.ReminderDue, message.Event)
+		assert.Equal(t, todo.Title, message.Data.(map[string]interface{})["title"])
+	default:
+		t.Error("expected a reminder to be pushed to the SSE client")
+	}
+
+	// a second run must not deliver the same reminder again
+	TestServerInstance.App.ReminderContainer.ReminderService.DispatchDueReminders(context.Background())
+	assert.Len(t, client.Data, 0)
+}
+
+func TestDispatchDueReminders_RetriesFailedDeliveries(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+	todo := SeedTodo(t, struct{}{}, user.ID)
+	reminder := database.Reminder{TodoID: todo.ID, UserID: user.ID, RemindAt: time.Now().Add(-time.Minute)}
+	require.NoError(t, TestServerInstance.DB.Create(&reminder).Error)
+
+	sseService := TestServerInstance.App.SSEContainer.SSEService
+	client := &sse.ConnectedClient{
+		Data: make(chan dtos.SSEData, 1),
+		Quit: make(chan struct{}),
+		Done: make(chan struct{}),
+	}
+	sseService.AddClient(user.ID, client)
+	defer sseService.RemoveClients(user.ID)
+
+	reminderService := TestServerInstance.App.ReminderContainer.ReminderService
+	sendEmail := reminderService.SendEmail
+	defer func() { reminderService.SendEmail = sendEmail }()
+
+	// a reminder that cannot be emailed stays unsent, but is still pushed to the app
+	reminderService.SendEmail = func(pkg.SendEmailConfig) error { return errors.New("mail server is down") }
+	reminderService.DispatchDueReminders(context.Background())
+
+	require.NoError(t, TestServerInstance.DB.First(&reminder, reminder.ID).Error)
+	assert.Nil(t, reminder.SentAt)
+	assert.Equal(t, 1, reminder.Attempts)
+	require.NotNil(t, reminder.LastError)
+	assert.Equal(t, "mail server is down", *reminder.LastError)
+	require.NotNil(t, reminder.RetryAt)
+	assert.True(t, reminder.RetryAt.After(time.Now()))
+	assert.Len(t, client.Data, 1)
+	<-client.Data
+
+	// it is held back until its retry is due
+	reminderService.SendEmail = sendEmail
+	reminderService.DispatchDueReminders(context.Background())
+	require.NoError(t, TestServerInstance.DB.First(&reminder, reminder.ID).Error)
+	assert.Nil(t, reminder.SentAt)
+
+	// and then goes out without being pushed a second time
+	require.NoError(t, TestServerInstance.DB.Model(&reminder).Update("retry_at", time.Now().Add(-time.Second).UTC()).Error)
+	reminderService.DispatchDueReminders(context.Background())
+
+	require.NoError(t, TestServerInstance.DB.First(&reminder, reminder.ID).Error)
+	assert.NotNil(t, reminder.SentAt)
+	assert.Len(t, client.Data, 0)
+}
+
+func TestDispatchDueReminders_GivesUpAfterTooManyAttempts(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+	todo := SeedTodo(t, struct{}{}, user.ID)
+	failingReminder := database.Reminder{TodoID: todo.ID, UserID: user.ID, RemindAt: time.Now().Add(-time.Hour).UTC()}
+	require.NoError(t, TestServerInstance.DB.Create(&failingReminder).Error)
+
+	reminderService := TestServerInstance.App.ReminderContainer.ReminderService
+	sendEmail := reminderService.SendEmail
+	defer func() { reminderService.SendEmail = sendEmail }()
+	reminderService.SendEmail = func(pkg.SendEmailConfig) error { return errors.New("mailbox does not exist") }
+
+	for attempt := 1; attempt <= 6; attempt++ {
+		reminderService.DispatchDueReminders(context.Background())
+		require.NoError(t, TestServerInstance.DB.Model(&failingReminder).Update("retry_at", nil).Error)
+	}
+
+	require.NoError(t, TestServerInstance.DB.First(&failingReminder, failingReminder.ID).Error)
+	assert.Nil(t, failingReminder.SentAt)
+	assert.Equal(t, 5, failingReminder.Attempts)
+
+	// reminders that have been given up on no longer take up room in the batch
+	reminderService.SendEmail = sendEmail
+	reminder := database.Reminder{TodoID: todo.ID, UserID: user.ID, RemindAt: time.Now().Add(-time.Minute).UTC()}
+	require.NoError(t, TestServerInstance.DB.Create(&reminder).Error)
+	reminders, err := reminderService.ReminderRepository.FetchDueReminders(context.Background(), time.Now(), 5, 1)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, reminder.ID, reminders[0].ID)
+}
+
+// test that reminders set with an offset fire at the instant they name rather than by how their text compares
+func TestDispatchDueReminders_RemindAtWithAnOffset(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+	todo := SeedTodo(t, struct{}{}, user.ID)
+
+	reminderService := TestServerInstance.App.ReminderContainer.ReminderService
+	createReminder := func(remindAt time.Time) uint {
+		t.Helper()
+		reminderId, err := reminderService.ReminderRepository.CreateReminder(context.Background(), &dtos.CreateReminderDTO{
+			TodoID:   todo.ID,
+			UserID:   user.ID,
+			RemindAt: &remindAt,
+		})
+		require.NoError(t, err)
+		return reminderId
+	}
+	//a minute ago, written ahead of UTC so its text reads later than now
+	dueReminder := createReminder(time.Now().Add(-time.Minute).In(time.FixedZone("+05:00", 5*60*60)))
+	//in an hour, written behind UTC so its text reads earlier than now
+	futureReminder := createReminder(time.Now().Add(time.Hour).In(time.FixedZone("-05:00", -5*60*60)))
+
+	reminderService.DispatchDueReminders(context.Background())
+
+	var sent, unsent database.Reminder
+	require.NoError(t, TestServerInstance.DB.First(&sent, dueReminder).Error)
+	assert.NotNil(t, sent.SentAt)
+	require.NoError(t, TestServerInstance.DB.First(&unsent, futureReminder).Error)
+	assert.Nil(t, unsent.SentAt)
+}
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func createTodos(t *testing.T, authToken string, count int) []uint {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		resp, _ := sendAuthenticatedRequest(t, http.MethodPost, "/todos", authToken, map[string]string{"title": fmt.Sprintf("todo %d", i), "content": "content", "type": "text"})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	var ids []uint
+	TestServerInstance.DB.Model(&database.Todo{}).Order("id asc").Pluck("id", &ids)
+	return ids
+}
+
+func TestMoveTodo(t *testing.T) {
+	_, authToken := setupTest(t)
+	ids := createTodos(t, authToken, 4)
+
+	response := fetchTodos(t, authToken, "sort_by=position")
+	require.Equal(t, ids, todoIds(response.Data), "new todos should be added to the end")
+
+	var untouched database.Todo
+	require.NoError(t, TestServerInstance.DB.First(&untouched, ids[1]).Error)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/position", ids[3]), authToken, dtos.ReorderDTO{AfterID: &ids[0]})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	response = fetchTodos(t, authToken, "sort_by=position")
+	assert.Equal(t, []uint{ids[0], ids[3], ids[1], ids[2]}, todoIds(response.Data))
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/position", ids[2]), authToken, dtos.ReorderDTO{BeforeID: &ids[0]})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	response = fetchTodos(t, authToken, "sort_by=position")
+	assert.Equal(t, []uint{ids[2], ids[0], ids[3], ids[1]}, todoIds(response.Data))
+
+	var stillUntouched database.Todo
+	require.NoError(t, TestServerInstance.DB.First(&stillUntouched, ids[1]).Error)
+	assert.Equal(t, untouched.Position, stillUntouched.Position, "moving a todo should not rewrite the others")
+}
+
+func TestMoveTodo_RebalancesTodosWithoutPositions(t *testing.T) {
+	user, authToken := setupTest(t)
+	first := SeedTodo(t, struct{}{}, user.ID)
+	second := SeedTodo(t, struct{}{}, user.ID)
+	third := SeedTodo(t, struct{}{}, user.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/position", third.ID), authToken, dtos.ReorderDTO{AfterID: &first.ID, BeforeID: &second.ID})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	response := fetchTodos(t, authToken, "sort_by=position")
+	assert.Equal(t, []uint{first.ID, third.ID, second.ID}, todoIds(response.Data))
+}
+
+func TestMoveTodo_Errors(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, struct{}{}, user.ID)
+	anotherUser := SeedUser(t, database.User{Email: "another@gmail.com"})
+	anotherTodo := SeedTodo(t, struct{}{}, anotherUser.ID)
+
+	tests := []struct {
+		name               string
+		request            dtos.ReorderDTO
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "no neighbour",
+			request:            dtos.ReorderDTO{},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "next to itself",
+			request:            dtos.ReorderDTO{AfterID: &todo.ID},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "a todo cannot be moved next to itself",
+		},
+		{
+			name:               "next to another user's todo",
+			request:            dtos.ReorderDTO{AfterID: &anotherTodo.ID},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "todo to move next to not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/position", todo.ID), authToken, tt.request)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+}
+
+func TestMoveChecklistItem(t *testing.T) {
+	user, authToken := setupTest(t)
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPost, "/todos", authToken, dtos.CreateTodoDTO{Title: "Groceries", Type: enums.Checklist, Checklist: []string{"milk", "eggs", "bread"}})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	todo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).First(&todo).Error)
+	checklistDescriptions := func() []string {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/todos/%d", TestServerInstance.Server.URL, todo.ID), nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+authToken)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var response struct {
+			Data database.Todo `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+
+		descriptions := make([]string, 0, len(response.Data.Checklists))
+		for _, checklist := range response.Data.Checklists {
+			descriptions = append(descriptions, checklist.Description)
+		}
+		return descriptions
+	}
+	require.Equal(t, []string{"milk", "eggs", "bread"}, checklistDescriptions())
+
+	var items []database.Checklist
+	TestServerInstance.DB.Where("todo_id = ?", todo.ID).Order("id asc").Find(&items)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/checklist/%d/position", todo.ID, items[0].ID), authToken, dtos.ReorderDTO{AfterID: &items[2].ID})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, []string{"eggs", "bread", "milk"}, checklistDescriptions())
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/checklist/%d/position", todo.ID, items[0].ID+100), authToken, dtos.ReorderDTO{AfterID: &items[2].ID})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "checklist not found", response.Message)
+}
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func searchTodos(t *testing.T, authToken string, search string) []database.Todo {
+	t.Helper()
+	return fetchTodos(t, authToken, "q="+url.QueryEscape(search)).Data
+}
+
+func TestSearchTodos_MatchesTitleContentAndChecklist(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	content := "remember the oat milk"
+	titleTodo := SeedTodo(t, database.Todo{Title: "Buy milk"}, user.ID)
+	contentTodo := SeedTodo(t, database.Todo{Title: "Groceries", Content: &content}, user.ID)
+	checklistTodo := SeedTodo(t, database.Todo{Title: "Shopping", Type: enums.Checklist}, user.ID)
+	SeedChecklist(t, database.Checklist{Description: "Milk and eggs"}, checklistTodo.ID)
+	SeedTodo(t, database.Todo{Title: "Walk the dog"}, user.ID)
+
+	results := searchTodos(t, authToken, "milk")
+	assert.ElementsMatch(t, []uint{titleTodo.ID, contentTodo.ID, checklistTodo.ID}, todoIds(results))
+	for _, todo := range results {
+		assert.Contains(t, todo.Snippet, "<mark>", "todo %d should have a highlighted snippet", todo.ID)
+	}
+}
+
+func TestSearchTodos_RanksBestMatchFirst(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	passing := "a long note about the weekend plans, the garden, the car and somewhere in here the word invoice"
+	weakMatch := SeedTodo(t, database.Todo{Title: "Weekend", Content: &passing}, user.ID)
+	strongMatch := SeedTodo(t, database.Todo{Title: "Send invoice"}, user.ID)
+
+	results := searchTodos(t, authToken, "invoice")
+	assert.Equal(t, []uint{strongMatch.ID, weakMatch.ID}, todoIds(results))
+	assert.Equal(t, "Send <mark>invoice</mark>", results[0].Snippet)
+
+	sorted := fetchTodos(t, authToken, "q=invoice&sort_by=id&order=asc").Data
+	assert.Equal(t, []uint{weakMatch.ID, strongMatch.ID}, todoIds(sorted), "an explicit sort should override ranking")
+}
+
+func TestSearchTodos_MatchesPrefixesAndEveryWord(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	dentist := SeedTodo(t, database.Todo{Title: "Book dentist appointment"}, user.ID)
+	SeedTodo(t, database.Todo{Title: "Book flights"}, user.ID)
+
+	assert.Equal(t, []uint{dentist.ID}, todoIds(searchTodos(t, authToken, "book dent")))
+	assert.Len(t, searchTodos(t, authToken, "boo"), 2)
+	assert.Empty(t, searchTodos(t, authToken, `"appointment OR flights"`))
+	assert.Empty(t, searchTodos(t, authToken, "NEAR("), "search operators should be treated as text")
+}
+
+func TestSearchTodos_StaysInSyncWithChanges(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+
+	todo := SeedTodo(t, database.Todo{Title: "Call the plumber", Type: enums.Checklist}, user.ID)
+	item := SeedChecklist(t, database.Checklist{Description: "Ask about the boiler"}, todo.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d", todo.ID), authToken, map[string]interface{}{
+		"title": "Call the electrician",
+		"type":  enums.Checklist,
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, searchTodos(t, authToken, "plumber"))
+	assert.Equal(t, []uint{todo.ID}, todoIds(searchTodos(t, authToken, "electrician")))
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPut, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, item.ID), authToken, map[string]interface{}{
+		"item": "Ask about the fuse box",
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, searchTodos(t, authToken, "boiler"))
+	assert.Equal(t, []uint{todo.ID}, todoIds(searchTodos(t, authToken, "fuse")))
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, item.ID), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, searchTodos(t, authToken, "fuse"))
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, searchTodos(t, authToken, "electrician"))
+}
+
+func TestSearchTodos_OnlyListsVisibleTodosAndKeepsPagination(t *testing.T) {
+	user, authToken, _ := setupFetchTodoTest(t, 0)
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+
+	for i := 0; i < 3; i++ {
+		SeedTodo(t, database.Todo{Title: fmt.Sprintf("Report %d", i)}, user.ID)
+	}
+	SeedTodo(t, database.Todo{Title: "Report for someone else"}, other.ID)
+
+	response := fetchTodos(t, authToken, "q=report&per_page=2&page=2")
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, 3, response.Meta.TotalCount)
+	assert.Equal(t, 2, response.Meta.LastPage)
+	assert.Equal(t, 2, response.Meta.CurrentPage)
+
+	response = fetchTodos(t, authToken, "q=%20%20")
+	assert.Equal(t, 3, response.Meta.TotalCount, "a blank search should list every todo")
+	assert.Empty(t, response.Data[0].Snippet)
+}
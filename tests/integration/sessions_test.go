@@ -0,0 +1,203 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/horlerdipo/todo-golang/env"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/sse"
+	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const (
+	firefoxOnWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0"
+	safariOnIPhone   = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+)
+
+func fetchSessions(t *testing.T, authToken string) []dtos.SessionDetails {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+"/auth/sessions", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response utils.JsonResponse[[]dtos.SessionDetails]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response.Data
+}
+
+// connectSSEClient registers an SSE client for the session the device named deviceName logged in with.
+func connectSSEClient(t *testing.T, userId uint, deviceName string) *sse.ConnectedClient {
+	t.Helper()
+
+	var session database.Session
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ? AND device_name = ?", userId, deviceName).First(&session).Error)
+	client := &sse.ConnectedClient{
+		Data:    make(chan dtos.SSEData, 1),
+		Quit:    make(chan struct{}),
+		Done:    make(chan struct{}),
+		Session: session.FamilyID,
+	}
+	TestServerInstance.App.SSEContainer.SSEService.AddClient(userId, client)
+	return client
+}
+
+func disconnected(client *sse.ConnectedClient) bool {
+	select {
+	case <-client.Quit:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser[dtos.LoginUserDTO](t, loginRequest)
+	laptop := loginFrom(t, firefoxOnWindows, "")
+	phone := loginFrom(t, safariOnIPhone, "My phone")
+
+	laptopClient := connectSSEClient(t, user.ID, "Firefox on Windows")
+	phoneClient := connectSSEClient(t, user.ID, "My phone")
+	defer TestServerInstance.App.SSEContainer.SSEService.RemoveClients(user.ID)
+
+	sessions := fetchSessions(t, laptop.Token.Token)
+	require.Len(t, sessions, 2)
+	byName := make(map[string]dtos.SessionDetails)
+	for _, session := range sessions {
+		byName[session.DeviceName] = session
+	}
+	require.Contains(t, byName, "Firefox on Windows")
+	require.Contains(t, byName, "My phone")
+	assert.True(t, byName["Firefox on Windows"].Current)
+	assert.False(t, byName["My phone"].Current)
+	assert.Equal(t, safariOnIPhone, byName["My phone"].UserAgent)
+	assert.Equal(t, "127.0.0.1", byName["My phone"].IPAddress)
+
+	// someone else's session cannot be revoked
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/auth/sessions/%d", byName["My phone"].ID), GenerateTestJwtToken(t, other.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "session does not exist", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/auth/sessions/%d", byName["My phone"].ID), laptop.Token.Token, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// the phone is logged out straight away and disconnected, the laptop is left alone
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", phone.Token.Token, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, response2 := refresh(t, phone.RefreshToken.Token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "refresh token is invalid", response2.Message)
+	assert.True(t, disconnected(phoneClient))
+	assert.False(t, disconnected(laptopClient))
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", laptop.Token.Token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	sessions = fetchSessions(t, laptop.Token.Token)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Firefox on Windows", sessions[0].DeviceName)
+
+	resp, response = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/auth/sessions/%d", byName["My phone"].ID), laptop.Token.Token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "session does not exist", response.Message)
+}
+
+func TestSessions_LogoutOnlyDisconnectsItsDevice(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser[dtos.LoginUserDTO](t, loginRequest)
+	laptop := loginFrom(t, firefoxOnWindows, "")
+	phone := loginFrom(t, safariOnIPhone, "")
+
+	laptopClient := connectSSEClient(t, user.ID, "Firefox on Windows")
+	phoneClient := connectSSEClient(t, user.ID, "Safari on iOS")
+	defer TestServerInstance.App.SSEContainer.SSEService.RemoveClients(user.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPost, "/auth/logout", laptop.Token.Token, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.True(t, disconnected(laptopClient))
+	assert.False(t, disconnected(phoneClient))
+	sessions := fetchSessions(t, phone.Token.Token)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Safari on iOS", sessions[0].DeviceName)
+	assert.True(t, sessions[0].Current)
+}
+
+func TestSessions_LogoutEverywhere(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser[dtos.LoginUserDTO](t, loginRequest)
+	laptop := loginFrom(t, firefoxOnWindows, "")
+	phone := loginFrom(t, safariOnIPhone, "")
+
+	laptopClient := connectSSEClient(t, user.ID, "Firefox on Windows")
+	phoneClient := connectSSEClient(t, user.ID, "Safari on iOS")
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, "/auth/sessions", laptop.Token.Token, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	for _, tokens := range []dtos.AuthTokens{laptop, phone} {
+		resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", tokens.Token.Token, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp, _ = refresh(t, tokens.RefreshToken.Token)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+	assert.True(t, disconnected(laptopClient))
+	assert.True(t, disconnected(phoneClient))
+
+	// logging in again starts afresh
+	tablet := login(t)
+	sessions := fetchSessions(t, tablet.Token.Token)
+	require.Len(t, sessions, 1)
+	assert.True(t, sessions[0].Current)
+}
+
+func TestSessions_RequestsMarkTheSessionSeen(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser[dtos.LoginUserDTO](t, loginRequest)
+	laptop := loginFrom(t, firefoxOnWindows, "")
+
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, TestServerInstance.DB.Model(&database.Session{}).Where("user_id = ?", user.ID).UpdateColumn("last_seen_at", stale).Error)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", laptop.Token.Token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	session := database.Session{}
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).First(&session).Error)
+	assert.WithinDuration(t, time.Now(), session.LastSeenAt, time.Minute)
+
+	// a session seen moments ago is not written to again
+	seen := session.LastSeenAt
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", laptop.Token.Token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).First(&session).Error)
+	assert.True(t, seen.Equal(session.LastSeenAt))
+}
+
+func TestSessions_RejectsTokensWithoutASession(t *testing.T) {
+	ClearAllTables(t, TestServerInstance.DB)
+	user := SeedUser(t, struct{}{})
+
+	//signed like an access token, but not issued for any session
+	authToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"data": user.ID,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(env.FetchString("JWT_SECRET")))
+	require.NoError(t, err)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodGet, "/auth/user", authToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
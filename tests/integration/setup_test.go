@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-faker/faker/v4"
@@ -9,12 +11,14 @@ import (
 	"github.com/horlerdipo/todo-golang/internal/database"
 	"github.com/horlerdipo/todo-golang/internal/enums"
 	"github.com/horlerdipo/todo-golang/utils"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"io"
 	"log"
 	_ "modernc.org/sqlite"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
@@ -26,6 +30,7 @@ type TestServer struct {
 	DB     *gorm.DB
 	Route  *chi.Mux
 	Server *httptest.Server
+	App    *app.Container
 }
 
 var TestServerInstance *TestServer
@@ -45,18 +50,23 @@ func setupGlobalServer() *TestServer {
 		DriverName: "sqlite", // <-- must match the imported driver
 		DSN:        DBName,
 	}, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Migrate models
-	err = db.AutoMigrate(&database.User{}, &database.TokenBlacklist{}, &database.Todo{}, &database.Checklist{})
+	err = db.AutoMigrate(&database.User{}, &database.TokenBlacklist{}, &database.Todo{}, &database.Checklist{}, &database.Reminder{}, &database.Label{}, &database.TodoCompletion{}, &database.TodoShare{}, &database.Workspace{}, &database.WorkspaceMember{}, &database.Comment{}, &database.Attachment{}, &database.View{}, &database.Template{}, &database.CalendarFeed{}, &database.RefreshToken{}, &database.Session{}, &database.OrphanedBlob{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if err = database.MigrateSearch(db); err != nil {
+		log.Fatal(err)
+	}
+
 	r := chi.NewRouter()
 
 	appContainer := app.NewAppContainer(db)
@@ -66,6 +76,7 @@ func setupGlobalServer() *TestServer {
 		DB:     db,
 		Route:  r,
 		Server: httptest.NewServer(r),
+		App:    appContainer,
 	}
 }
 
@@ -88,6 +99,8 @@ func tearDownGlobalServer(ts *TestServer) {
 		_ = os.Remove(DBName)
 	}
 
+	_ = os.RemoveAll(env.FetchString("ATTACHMENT_STORAGE_PATH"))
+
 }
 
 func ClearAllTables(t *testing.T, db *gorm.DB) {
@@ -129,7 +142,8 @@ func getAllTableNames(db *gorm.DB) ([]string, error) {
 
 	switch db.Dialector.Name() {
 	case "sqlite":
-		query := "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
+		// shadow tables belong to virtual tables such as the search index and are cleared along with them
+		query := "SELECT name FROM pragma_table_list WHERE schema = 'main' AND type IN ('table', 'virtual') AND name NOT LIKE 'sqlite_%'"
 		err := db.Raw(query).Scan(&tables).Error
 		return tables, err
 
@@ -197,10 +211,30 @@ func SeedUser[T any](t *testing.T, input T) *database.User {
 	return &user
 }
 
+// GenerateTestJwtToken starts a session for the user the way logging in does, with a refresh token family of its own,
+// and returns an access token issued for it.
 func GenerateTestJwtToken(t *testing.T, userID uint) string {
 	t.Helper()
-	ttl := time.Now().Add(time.Hour * time.Duration(env.FetchInt("JWT_TTL")))
-	token, err := utils.GenerateJwtToken(env.FetchString("JWT_SECRET"), ttl, userID)
+	familyId, err := utils.RandomToken(32)
+	require.NoError(t, err)
+	refreshToken, err := utils.RandomToken(32)
+	require.NoError(t, err)
+
+	require.NoError(t, TestServerInstance.DB.Create(&database.Session{
+		FamilyID:   familyId,
+		UserID:     userID,
+		DeviceName: "Test device",
+		LastSeenAt: time.Now(),
+	}).Error)
+	require.NoError(t, TestServerInstance.DB.Create(&database.RefreshToken{
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyId,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Duration(env.FetchInt("REFRESH_TOKEN_TTL", 30)) * 24 * time.Hour),
+	}).Error)
+
+	ttl := time.Now().Add(time.Minute * time.Duration(env.FetchInt("ACCESS_TOKEN_TTL")))
+	token, err := utils.GenerateFamilyJwtToken(env.FetchString("JWT_SECRET"), ttl, userID, familyId)
 	if err != nil {
 		t.Fatal("unable to generate JWT token", err)
 	}
@@ -266,3 +300,28 @@ func mergeStruct[T any, B any](t *testing.T, target *B, input T) {
 		}
 	}
 }
+
+// sendAuthenticatedRequest sends body as JSON on behalf of the user authToken belongs to and decodes the JSON response.
+func sendAuthenticatedRequest(t *testing.T, method string, path string, authToken string, body interface{}) (*http.Response, utils.JsonResponse[interface{}]) {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(method, TestServerInstance.Server.URL+path, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response utils.JsonResponse[interface{}]
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	return resp, response
+}
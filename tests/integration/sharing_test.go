@@ -0,0 +1,263 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func shareTodo(t *testing.T, todoId uint, userId uint, role enums.ShareRole) {
+	t.Helper()
+	share := database.TodoShare{TodoID: todoId, UserID: userId, Role: role}
+	require.NoError(t, TestServerInstance.DB.Create(&share).Error)
+}
+
+func TestShareTodo(t *testing.T) {
+	tests := []struct {
+		name               string
+		email              string
+		role               string
+		asCollaborator     bool
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "invite an editor",
+			email:              "collaborator@gmail.com",
+			role:               "editor",
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "unknown email",
+			email:              "nobody@gmail.com",
+			role:               "viewer",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "user does not exist",
+		},
+		{
+			name:               "sharing with yourself",
+			email:              "testing@gmail.com",
+			role:               "viewer",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "you cannot share a todo with yourself",
+		},
+		{
+			name:               "collaborators cannot invite",
+			email:              "collaborator@gmail.com",
+			role:               "viewer",
+			asCollaborator:     true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "todo does not exist",
+		},
+		{
+			name:               "invalid role",
+			email:              "collaborator@gmail.com",
+			role:               "owner",
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com"})
+			todo := SeedTodo(t, database.Todo{}, user.ID)
+
+			if tt.asCollaborator {
+				shareTodo(t, todo.ID, collaborator.ID, enums.Editor)
+				authToken = GenerateTestJwtToken(t, collaborator.ID)
+			}
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/collaborators", todo.ID), authToken, map[string]string{
+				"email": tt.email,
+				"role":  tt.role,
+			})
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			if tt.expectedStatusCode == http.StatusCreated {
+				share := database.TodoShare{}
+				require.NoError(t, TestServerInstance.DB.Where("todo_id = ? AND user_id = ?", todo.ID, collaborator.ID).First(&share).Error)
+				assert.Equal(t, enums.Editor, share.Role)
+				assert.Equal(t, user.ID, share.InvitedByID)
+			}
+		})
+	}
+}
+
+func TestShareTodo_ChangesRole(t *testing.T) {
+	user, authToken := setupTest(t)
+	collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com"})
+	todo := SeedTodo(t, database.Todo{}, user.ID)
+	shareTodo(t, todo.ID, collaborator.ID, enums.Viewer)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/collaborators", todo.ID), authToken, map[string]string{
+		"email": collaborator.Email,
+		"role":  "editor",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var shares []database.TodoShare
+	require.NoError(t, TestServerInstance.DB.Where("todo_id = ?", todo.ID).Find(&shares).Error)
+	require.Len(t, shares, 1)
+	assert.Equal(t, enums.Editor, shares[0].Role)
+}
+
+func TestFetchCollaborators(t *testing.T) {
+	user, authToken := setupTest(t)
+	collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com", FirstName: "Jane"})
+	todo := SeedTodo(t, database.Todo{}, user.ID)
+	shareTodo(t, todo.ID, collaborator.ID, enums.Viewer)
+
+	for _, token := range []string{authToken, GenerateTestJwtToken(t, collaborator.ID)} {
+		resp, response := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d/collaborators", todo.ID), token, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		collaborators := response.Data.([]interface{})
+		require.Len(t, collaborators, 1)
+		first := collaborators[0].(map[string]interface{})
+		assert.Equal(t, "collaborator@gmail.com", first["email"])
+		assert.Equal(t, "Jane", first["first_name"])
+		assert.Equal(t, "viewer", first["role"])
+		assert.NotContains(t, first, "password")
+	}
+
+	stranger := SeedUser(t, database.User{Email: "stranger@gmail.com"})
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d/collaborators", todo.ID), GenerateTestJwtToken(t, stranger.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "todo does not exist", response.Message)
+}
+
+func TestRemoveCollaborator(t *testing.T) {
+	tests := []struct {
+		name               string
+		as                 string
+		remove             string
+		expectedStatusCode int
+		expectedMsg        string
+		expectRemoved      bool
+	}{
+		{
+			name:               "owner removes a collaborator",
+			as:                 "owner",
+			remove:             "editor",
+			expectedStatusCode: http.StatusNoContent,
+			expectRemoved:      true,
+		},
+		{
+			name:               "collaborator leaves",
+			as:                 "viewer",
+			remove:             "viewer",
+			expectedStatusCode: http.StatusNoContent,
+			expectRemoved:      true,
+		},
+		{
+			name:               "collaborator removes someone else",
+			as:                 "viewer",
+			remove:             "editor",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only the owner can remove other collaborators",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			todo := SeedTodo(t, database.Todo{}, user.ID)
+			editor := SeedUser(t, database.User{Email: "editor@gmail.com"})
+			viewer := SeedUser(t, database.User{Email: "viewer@gmail.com"})
+			shareTodo(t, todo.ID, editor.ID, enums.Editor)
+			shareTodo(t, todo.ID, viewer.ID, enums.Viewer)
+
+			users := map[string]*database.User{"owner": user, "editor": editor, "viewer": viewer}
+			if tt.as != "owner" {
+				authToken = GenerateTestJwtToken(t, users[tt.as].ID)
+			}
+			removed := users[tt.remove]
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/collaborators/%d", todo.ID, removed.ID), authToken, nil)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			var count int64
+			TestServerInstance.DB.Model(&database.TodoShare{}).Where("todo_id = ? AND user_id = ?", todo.ID, removed.ID).Count(&count)
+			assert.Equal(t, tt.expectRemoved, count == 0)
+		})
+	}
+}
+
+func TestSharedTodo_Permissions(t *testing.T) {
+	tests := []struct {
+		name               string
+		role               enums.ShareRole
+		expectedStatusCode int
+		expectedMsg        string
+		expectCompleted    bool
+	}{
+		{
+			name:               "editors can complete",
+			role:               enums.Editor,
+			expectedStatusCode: http.StatusOK,
+			expectCompleted:    true,
+		},
+		{
+			name:               "viewers cannot complete",
+			role:               enums.Viewer,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "you do not have permission to edit this todo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, _ := setupTest(t)
+			collaborator := SeedUser(t, database.User{Email: "collaborator@gmail.com"})
+			todo := SeedTodo(t, database.Todo{}, user.ID)
+			shareTodo(t, todo.ID, collaborator.ID, tt.role)
+			authToken := GenerateTestJwtToken(t, collaborator.ID)
+
+			fetched := fetchTodo(t, authToken, todo.ID)
+			assert.Equal(t, todo.ID, fetched.ID)
+
+			resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/complete", todo.ID), authToken, nil)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+
+			updatedTodo := database.Todo{}
+			require.NoError(t, TestServerInstance.DB.First(&updatedTodo, todo.ID).Error)
+			assert.Equal(t, tt.expectCompleted, updatedTodo.Completed)
+
+			if tt.expectCompleted {
+				completion := database.TodoCompletion{}
+				require.NoError(t, TestServerInstance.DB.Where("todo_id = ?", todo.ID).First(&completion).Error)
+				assert.Equal(t, collaborator.ID, completion.UserID, "the completion should be recorded against the collaborator")
+			}
+
+			resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d", todo.ID), authToken, nil)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "only the owner can delete a shared todo")
+		})
+	}
+}
+
+func TestFetchTodos_SharedFilter(t *testing.T) {
+	user, authToken := setupTest(t)
+	owner := SeedUser(t, database.User{Email: "owner@gmail.com"})
+	own := SeedTodo(t, database.Todo{}, user.ID)
+	shared := SeedTodo(t, database.Todo{}, owner.ID)
+	SeedTodo(t, database.Todo{}, owner.ID)
+	shareTodo(t, shared.ID, user.ID, enums.Viewer)
+
+	assert.Equal(t, []uint{own.ID}, todoIds(fetchTodos(t, authToken, "sort_by=id").Data))
+	assert.Equal(t, []uint{own.ID, shared.ID}, todoIds(fetchTodos(t, authToken, "sort_by=id&filters[shared]=include").Data))
+	assert.Equal(t, []uint{shared.ID}, todoIds(fetchTodos(t, authToken, "sort_by=id&filters[shared]=only").Data))
+}
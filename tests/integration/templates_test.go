@@ -0,0 +1,180 @@
+package integration
+
+import (
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func createTemplate(t *testing.T, authToken string, request map[string]interface{}) uint {
+	t.Helper()
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/templates", authToken, request)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	return uint(response.Data.(map[string]interface{})["id"].(float64))
+}
+
+func instantiateTemplate(t *testing.T, authToken string, templateId uint) database.Todo {
+	t.Helper()
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/templates/%d/instantiate", templateId), authToken, nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	assert.Equal(t, "Todo created from template successfully", response.Message)
+
+	var todo database.Todo
+	todoId := uint(response.Data.(map[string]interface{})["id"].(float64))
+	require.NoError(t, TestServerInstance.DB.Preload("Checklists").Preload("Labels").First(&todo, todoId).Error)
+	return todo
+}
+
+func TestCreateTemplate(t *testing.T) {
+	user, authToken := setupTest(t)
+	work := SeedLabel(t, struct{}{}, user.ID)
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	otherLabel := SeedLabel(t, struct{}{}, other.ID)
+	content := "Say hello to the team"
+
+	tests := []struct {
+		name               string
+		request            map[string]interface{}
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name: "success",
+			request: map[string]interface{}{
+				"title":              "Onboarding",
+				"type":               "checklist",
+				"checklist":          []string{"Set up laptop", "Meet the team"},
+				"label_ids":          []uint{work.ID, work.ID},
+				"due_offset_minutes": 7 * 24 * 60,
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedMsg:        "Template created successfully",
+		},
+		{
+			name:               "text template without content",
+			request:            map[string]interface{}{"title": "Hello", "type": "text"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "negative offset",
+			request:            map[string]interface{}{"title": "Hello", "type": "text", "content": content, "due_offset_minutes": -5},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "starts after it is due",
+			request: map[string]interface{}{
+				"title":                "Hello",
+				"type":                 "text",
+				"content":              content,
+				"start_offset_minutes": 120,
+				"due_offset_minutes":   60,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "start offset cannot be after due offset",
+		},
+		{
+			name:               "someone else's label",
+			request:            map[string]interface{}{"title": "Hello", "type": "text", "content": content, "label_ids": []uint{otherLabel.ID}},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "label does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/templates", authToken, tt.request)
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+
+	var templates []database.Template
+	require.NoError(t, TestServerInstance.DB.Where("user_id = ?", user.ID).Find(&templates).Error)
+	require.Len(t, templates, 1)
+	assert.Equal(t, []string{"Set up laptop", "Meet the team"}, templates[0].Checklist)
+	assert.Equal(t, []uint{work.ID}, templates[0].LabelIDs)
+	assert.Equal(t, enums.PriorityNone, templates[0].Priority)
+}
+
+func TestInstantiateTemplate(t *testing.T) {
+	user, authToken := setupTest(t)
+	work := SeedLabel(t, struct{}{}, user.ID)
+	personal := SeedLabel(t, database.Label{Name: "personal"}, user.ID)
+
+	templateId := createTemplate(t, authToken, map[string]interface{}{
+		"title":                "Onboarding",
+		"type":                 "checklist",
+		"checklist":            []string{"Set up laptop", "Meet the team"},
+		"priority":             "high",
+		"label_ids":            []uint{work.ID, personal.ID},
+		"start_offset_minutes": 60,
+		"due_offset_minutes":   7 * 24 * 60,
+	})
+
+	before := time.Now()
+	todo := instantiateTemplate(t, authToken, templateId)
+	assert.Equal(t, "Onboarding", todo.Title)
+	assert.Equal(t, enums.Checklist, todo.Type)
+	assert.Equal(t, enums.PriorityHigh, todo.Priority)
+	assert.Equal(t, user.ID, todo.UserID)
+	require.Len(t, todo.Checklists, 2)
+	assert.ElementsMatch(t, []string{"Set up laptop", "Meet the team"}, []string{todo.Checklists[0].Description, todo.Checklists[1].Description})
+	assert.Len(t, todo.Labels, 2)
+	require.NotNil(t, todo.StartAt)
+	require.NotNil(t, todo.DueAt)
+	assert.WithinDuration(t, before.Add(time.Hour), *todo.StartAt, time.Minute)
+	assert.WithinDuration(t, before.Add(7*24*time.Hour), *todo.DueAt, time.Minute)
+
+	// every instantiation is a new todo, and labels deleted in the meantime are left out
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/labels/%d", personal.ID), authToken, nil)
+	require.Less(t, resp.StatusCode, 300)
+	again := instantiateTemplate(t, authToken, templateId)
+	assert.NotEqual(t, todo.ID, again.ID)
+	require.Len(t, again.Labels, 1)
+	assert.Equal(t, work.ID, again.Labels[0].ID)
+
+	// a template belongs to the user who saved it
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/templates/%d/instantiate", templateId), GenerateTestJwtToken(t, other.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "template does not exist", response.Message)
+}
+
+func TestUpdateAndDeleteTemplate(t *testing.T) {
+	_, authToken := setupTest(t)
+	templateId := createTemplate(t, authToken, map[string]interface{}{
+		"title":     "Weekly review",
+		"type":      "checklist",
+		"checklist": []string{"Inbox zero"},
+	})
+
+	content := "Review the week"
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPut, fmt.Sprintf("/templates/%d", templateId), authToken, map[string]interface{}{
+		"title":   "Weekly review",
+		"type":    "text",
+		"content": content,
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	todo := instantiateTemplate(t, authToken, templateId)
+	assert.Equal(t, enums.Text, todo.Type)
+	assert.Equal(t, &content, todo.Content)
+	assert.Empty(t, todo.Checklists)
+	assert.Nil(t, todo.DueAt)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/templates/%d", templateId), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/templates/%d", templateId), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "template does not exist", response.Message)
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFetchTrash(t *testing.T) {
+	user, authToken := setupTest(t)
+	trashedTodo := SeedTodo(t, struct{}{}, user.ID)
+	SeedTodo(t, struct{}{}, user.ID)
+
+	anotherUser := SeedUser(t, database.User{Email: "another@gmail.com"})
+	anotherTodo := SeedTodo(t, struct{}{}, anotherUser.ID)
+	require.NoError(t, TestServerInstance.DB.Delete(anotherTodo).Error)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d", trashedTodo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, "/todos/trash", authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Trashed todos fetched successfully", response.Message)
+
+	trash := response.Data.([]interface{})
+	require.Len(t, trash, 1)
+	assert.Equal(t, float64(trashedTodo.ID), trash[0].(map[string]interface{})["id"])
+
+	todos := fetchTodos(t, authToken, "")
+	assert.NotContains(t, todoIds(todos.Data), trashedTodo.ID)
+}
+
+func TestRestoreTodo(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	keptItem := SeedChecklist(t, struct{}{}, todo.ID)
+	removedItem := SeedChecklist(t, struct{}{}, todo.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/checklist/%d", todo.ID, removedItem.ID), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/restore", todo.ID), authToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	restoredTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.Preload("Checklists").First(&restoredTodo, todo.ID).Error)
+	require.Len(t, restoredTodo.Checklists, 1, "only the items trashed with the todo should be restored")
+	assert.Equal(t, keptItem.ID, restoredTodo.Checklists[0].ID)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos/%d/restore", todo.ID), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "todo is not in the trash", response.Message)
+}
+
+func TestPurgeTodo(t *testing.T) {
+	user, authToken := setupTest(t)
+	todo := SeedTodo(t, database.Todo{Type: enums.Checklist}, user.ID)
+	SeedChecklist(t, struct{}{}, todo.ID)
+	label := SeedLabel(t, struct{}{}, user.ID)
+	require.NoError(t, TestServerInstance.DB.Model(todo).Association("Labels").Append(label))
+	require.NoError(t, TestServerInstance.DB.Create(&database.Reminder{TodoID: todo.ID, UserID: user.ID, RemindAt: time.Now()}).Error)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/permanent", todo.ID), authToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "todo is not in the trash", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d", todo.ID), authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/todos/%d/permanent", todo.ID), authToken, nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assertPurged(t, todo.ID)
+	var labels int64
+	TestServerInstance.DB.Unscoped().Model(&database.Label{}).Count(&labels)
+	assert.Equal(t, int64(1), labels, "labels should outlive the todos they were attached to")
+}
+
+func TestPurgeTrash_RemovesTodosPastRetention(t *testing.T) {
+	user, _ := setupTest(t)
+	expiredTodo := SeedTodo(t, struct{}{}, user.ID)
+	SeedChecklist(t, struct{}{}, expiredTodo.ID)
+	recentlyTrashedTodo := SeedTodo(t, struct{}{}, user.ID)
+	activeTodo := SeedTodo(t, struct{}{}, user.ID)
+
+	TestServerInstance.DB.Model(&database.Checklist{}).Where("todo_id = ?", expiredTodo.ID).Update("deleted_at", time.Now().AddDate(0, 0, -31))
+	TestServerInstance.DB.Model(expiredTodo).Update("deleted_at", time.Now().AddDate(0, 0, -31))
+	TestServerInstance.DB.Model(recentlyTrashedTodo).Update("deleted_at", time.Now().AddDate(0, 0, -1))
+
+	TestServerInstance.App.TodoContainer.TodoService.PurgeTrash(context.Background())
+
+	assertPurged(t, expiredTodo.ID)
+	var remaining []uint
+	TestServerInstance.DB.Unscoped().Model(&database.Todo{}).Order("id").Pluck("id", &remaining)
+	assert.Equal(t, []uint{recentlyTrashedTodo.ID, activeTodo.ID}, remaining)
+}
+
+func assertPurged(t *testing.T, todoId uint) {
+	t.Helper()
+
+	tables := map[string]interface{}{
+		"todos":      &database.Todo{},
+		"checklists": &database.Checklist{},
+		"reminders":  &database.Reminder{},
+	}
+	for table, model := range tables {
+		var count int64
+		column := "todo_id"
+		if table == "todos" {
+			column = "id"
+		}
+		TestServerInstance.DB.Unscoped().Model(model).Where(column+" = ?", todoId).Count(&count)
+		assert.Equal(t, int64(0), count, "expected no %s rows left for todo %d", table, todoId)
+	}
+
+	var todoLabels int64
+	TestServerInstance.DB.Table("todo_labels").Where("todo_id = ?", todoId).Count(&todoLabels)
+	assert.Equal(t, int64(0), todoLabels)
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 )
 
 var updateTodoContent string = "random random string"
@@ -59,6 +60,13 @@ func TestUpdateTodo(t *testing.T) {
 			expectedMsg:        "",
 			extraAssertions:    changeTodoContentToNilSuccessfullyExtraAssertions,
 		},
+		{
+			description:        "Should turn the content into checklist items like the bulk change_type action",
+			setupFunc:          convertContentToChecklistSetup,
+			expectedStatusCode: http.StatusNoContent,
+			expectedMsg:        "",
+			extraAssertions:    convertContentToChecklistExtraAssertions,
+		},
 		{
 			description:        "Should update todo successfully and delete checklists associated with the todo",
 			setupFunc:          clearTodoChecklistSuccessfullySetup,
@@ -66,6 +74,20 @@ func TestUpdateTodo(t *testing.T) {
 			expectedMsg:        "",
 			extraAssertions:    clearTodoChecklistSuccessfullyExtraAssertions,
 		},
+		{
+			description:        "Should keep the dates and recurrence that are left out",
+			setupFunc:          keepOmittedDatesSetup,
+			expectedStatusCode: http.StatusNoContent,
+			expectedMsg:        "",
+			extraAssertions:    keepOmittedDatesExtraAssertions,
+		},
+		{
+			description:        "Should clear the dates and recurrence that are sent as null",
+			setupFunc:          clearNullDatesSetup,
+			expectedStatusCode: http.StatusNoContent,
+			expectedMsg:        "",
+			extraAssertions:    clearNullDatesExtraAssertions,
+		},
 		{
 			description:        "Should return validation error",
 			setupFunc:          updateTodoValidationErrorSetup,
@@ -209,6 +231,37 @@ func changeTodoContentToNilSuccessfullyExtraAssertions(t *testing.T, setup Updat
 	}
 }
 
+func convertContentToChecklistSetup(t *testing.T) UpdateTodoSetupResponse {
+	t.Helper()
+	user, authToken, _ := setupUpdateTodoTest(t)
+	content := "- [x] Passport\n- [ ] Tickets"
+	todo := SeedTodo(t, database.Todo{Content: &content}, user.ID)
+
+	return UpdateTodoSetupResponse{
+		User:      user,
+		AuthToken: authToken,
+		Todo:      todo,
+		RequestDto: dtos.UpdateTodoDTO{
+			Title: "Pack",
+			Type:  enums.Checklist,
+		},
+	}
+}
+
+func convertContentToChecklistExtraAssertions(t *testing.T, setup UpdateTodoSetupResponse) {
+	t.Helper()
+	todo := findTodo(t, setup.Todo.ID)
+	if todo.Type != enums.Checklist || todo.Content != nil {
+		t.Errorf("expected a checklist without content, got %v with %v", todo.Type, todo.Content)
+	}
+
+	var checklists []database.Checklist
+	TestServerInstance.DB.Where("todo_id = ?", setup.Todo.ID).Order("position asc").Find(&checklists)
+	if len(checklists) != 2 || checklists[0].Description != "Passport" || !checklists[0].Done || checklists[1].Description != "Tickets" || checklists[1].Done {
+		t.Errorf("expected the content's lines as checklist items, got %+v", checklists)
+	}
+}
+
 func clearTodoChecklistSuccessfullySetup(t *testing.T) UpdateTodoSetupResponse {
 	t.Helper()
 	user, authToken, todo := setupUpdateTodoTest(t)
@@ -261,6 +314,78 @@ func clearTodoChecklistSuccessfullyExtraAssertions(t *testing.T, setup UpdateTod
 	}
 }
 
+// setupScheduledTodoTest seeds a weekly todo with a start and a due date.
+func setupScheduledTodoTest(t *testing.T) (*database.User, string, *database.Todo) {
+	t.Helper()
+	user, authToken, _ := setupUpdateTodoTest(t)
+	startAt := time.Date(2030, time.March, 1, 9, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2030, time.March, 1, 17, 0, 0, 0, time.UTC)
+	weekly := "FREQ=WEEKLY"
+	todo := SeedTodo(t, database.Todo{StartAt: &startAt, DueAt: &dueAt, Recurrence: &weekly}, user.ID)
+	return user, authToken, todo
+}
+
+func keepOmittedDatesSetup(t *testing.T) UpdateTodoSetupResponse {
+	t.Helper()
+	user, authToken, todo := setupScheduledTodoTest(t)
+
+	return UpdateTodoSetupResponse{
+		User:       user,
+		AuthToken:  authToken,
+		Todo:       todo,
+		RequestDto: updateTodoRequest,
+	}
+}
+
+func keepOmittedDatesExtraAssertions(t *testing.T, setup UpdateTodoSetupResponse) {
+	t.Helper()
+	todo := database.Todo{}
+	if err := TestServerInstance.DB.Where("id = ?", setup.Todo.ID).First(&todo).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if todo.Title != updateTodoRequest.Title {
+		t.Errorf("expected title to be %s, got %s", updateTodoRequest.Title, todo.Title)
+	}
+	if todo.StartAt == nil || !todo.StartAt.Equal(*setup.Todo.StartAt) {
+		t.Errorf("expected start date to be kept as %v, got %v", *setup.Todo.StartAt, todo.StartAt)
+	}
+	if todo.DueAt == nil || !todo.DueAt.Equal(*setup.Todo.DueAt) {
+		t.Errorf("expected due date to be kept as %v, got %v", *setup.Todo.DueAt, todo.DueAt)
+	}
+	if todo.Recurrence == nil || *todo.Recurrence != *setup.Todo.Recurrence {
+		t.Errorf("expected recurrence to be kept as %v, got %v", *setup.Todo.Recurrence, todo.Recurrence)
+	}
+}
+
+func clearNullDatesSetup(t *testing.T) UpdateTodoSetupResponse {
+	t.Helper()
+	user, authToken, todo := setupScheduledTodoTest(t)
+	request := updateTodoRequest
+	request.StartAt = dtos.Null[time.Time]()
+	request.DueAt = dtos.Null[time.Time]()
+	request.Recurrence = dtos.Null[string]()
+
+	return UpdateTodoSetupResponse{
+		User:       user,
+		AuthToken:  authToken,
+		Todo:       todo,
+		RequestDto: request,
+	}
+}
+
+func clearNullDatesExtraAssertions(t *testing.T, setup UpdateTodoSetupResponse) {
+	t.Helper()
+	todo := database.Todo{}
+	if err := TestServerInstance.DB.Where("id = ?", setup.Todo.ID).First(&todo).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if todo.StartAt != nil || todo.DueAt != nil || todo.Recurrence != nil {
+		t.Errorf("expected dates and recurrence to be cleared, got %v, %v and %v", todo.StartAt, todo.DueAt, todo.Recurrence)
+	}
+}
+
 func updateTodoValidationErrorSetup(t *testing.T) UpdateTodoSetupResponse {
 	t.Helper()
 	user, authToken, todo := setupUpdateTodoTest(t)
@@ -0,0 +1,264 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func createView(t *testing.T, authToken string, request map[string]interface{}) uint {
+	t.Helper()
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/views", authToken, request)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, response.Message)
+	return uint(response.Data.(map[string]interface{})["id"].(float64))
+}
+
+func fetchViewTodos(t *testing.T, authToken string, path string) dtos.PaginatedResponse[database.Todo] {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response dtos.PaginatedResponse[database.Todo]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response
+}
+
+func fetchViews(t *testing.T, authToken string) []database.View {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+"/views", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []database.View `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response.Data
+}
+
+func viewIds(views []database.View) []uint {
+	ids := make([]uint, 0, len(views))
+	for _, view := range views {
+		ids = append(ids, view.ID)
+	}
+	return ids
+}
+
+func TestCreateView(t *testing.T) {
+	user, authToken := setupTest(t)
+	work := SeedLabel(t, struct{}{}, user.ID)
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	otherLabel := SeedLabel(t, struct{}{}, other.ID)
+
+	tests := []struct {
+		name               string
+		request            map[string]interface{}
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name: "success",
+			request: map[string]interface{}{
+				"name":      "Urgent work",
+				"filters":   map[string]string{"priority": "urgent", "due_at[null]": "false"},
+				"sort_by":   "due_at",
+				"order":     "asc",
+				"q":         "report",
+				"label_ids": []uint{work.ID},
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedMsg:        "View created successfully",
+		},
+		{
+			name:               "missing name",
+			request:            map[string]interface{}{"sort_by": "due_at"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "invalid order",
+			request:            map[string]interface{}{"name": "Sideways", "order": "sideways"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "unknown filter",
+			request:            map[string]interface{}{"name": "Colours", "filters": map[string]string{"colour": "red"}},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedMsg:        "invalid list query: filter colour is not allowed",
+		},
+		{
+			name:               "unsupported filter operator",
+			request:            map[string]interface{}{"name": "Titles", "filters": map[string]string{"title[gt]": "a"}},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedMsg:        "invalid list query: filter title does not support the gt operator",
+		},
+		{
+			name:               "filter value of the wrong type",
+			request:            map[string]interface{}{"name": "Someday", "filters": map[string]string{"due_from": "someday"}},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "unknown sort field",
+			request:            map[string]interface{}{"name": "Coloured", "sort_by": "colour", "is_default": true},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedMsg:        "invalid list query: sorting by colour is not allowed",
+		},
+		{
+			name:               "someone else's label",
+			request:            map[string]interface{}{"name": "Theirs", "label_ids": []uint{otherLabel.ID}},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "label does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/views", authToken, tt.request)
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+
+	views := fetchViews(t, authToken)
+	require.Len(t, views, 1)
+	assert.Equal(t, "Urgent work", views[0].Name)
+	assert.Equal(t, map[string]string{"priority": "urgent", "due_at[null]": "false"}, views[0].Filters)
+	assert.Equal(t, []uint{work.ID}, views[0].LabelIDs)
+	assert.Equal(t, dtos.OrderAsc, views[0].Order)
+}
+
+func TestFetchViewTodos(t *testing.T) {
+	user, authToken := setupTest(t)
+	work := SeedLabel(t, struct{}{}, user.ID)
+
+	soon := time.Now().Add(24 * time.Hour)
+	between := time.Now().Add(48 * time.Hour)
+	later := time.Now().Add(72 * time.Hour)
+	content := "quarterly report"
+	laterReport := SeedTodo(t, database.Todo{Title: "Write report", Priority: enums.PriorityUrgent, DueAt: &later}, user.ID)
+	soonReport := SeedTodo(t, database.Todo{Title: "Review", Content: &content, Priority: enums.PriorityUrgent, DueAt: &soon}, user.ID)
+	unlabelled := SeedTodo(t, database.Todo{Title: "Report expenses", Priority: enums.PriorityUrgent, DueAt: &between}, user.ID)
+	SeedTodo(t, database.Todo{Title: "Report bug", Priority: enums.PriorityLow, DueAt: &soon}, user.ID)
+	SeedTodo(t, database.Todo{Title: "Undated report", Priority: enums.PriorityUrgent}, user.ID)
+	for _, todo := range []*database.Todo{laterReport, soonReport} {
+		require.NoError(t, TestServerInstance.DB.Model(todo).Association("Labels").Append(work))
+	}
+
+	viewId := createView(t, authToken, map[string]interface{}{
+		"name":      "Urgent work",
+		"filters":   map[string]string{"priority": "urgent", "due_at[null]": "false"},
+		"sort_by":   "due_at",
+		"order":     "asc",
+		"q":         "report",
+		"label_ids": []uint{work.ID},
+	})
+
+	response := fetchViewTodos(t, authToken, fmt.Sprintf("/views/%d/todos", viewId))
+	assert.Equal(t, []uint{soonReport.ID, laterReport.ID}, todoIds(response.Data))
+	assert.Equal(t, 2, response.Meta.TotalCount)
+
+	// paging comes from the request, everything else from the view
+	response = fetchViewTodos(t, authToken, fmt.Sprintf("/views/%d/todos?per_page=1&page=2&filters[priority]=low&sort_by=title", viewId))
+	assert.Equal(t, []uint{laterReport.ID}, todoIds(response.Data))
+	assert.Equal(t, 2, response.Meta.LastPage)
+
+	// editing the view changes what it lists
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPut, fmt.Sprintf("/views/%d", viewId), authToken, map[string]interface{}{
+		"name":    "Urgent reports",
+		"filters": map[string]string{"priority": "urgent", "due_at[null]": "false"},
+		"sort_by": "due_at",
+		"order":   "desc",
+		"q":       "report",
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	response = fetchViewTodos(t, authToken, fmt.Sprintf("/views/%d/todos", viewId))
+	assert.Equal(t, []uint{laterReport.ID, unlabelled.ID, soonReport.ID}, todoIds(response.Data))
+
+	// a view belongs to the user who saved it
+	other := SeedUser(t, database.User{Email: "other@gmail.com"})
+	resp, response2 := sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/views/%d/todos", viewId), GenerateTestJwtToken(t, other.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "view does not exist", response2.Message)
+}
+
+func TestDefaultView(t *testing.T) {
+	user, authToken := setupTest(t)
+	pinned := SeedTodo(t, database.Todo{Pinned: true}, user.ID)
+	unpinned := SeedTodo(t, struct{}{}, user.ID)
+
+	// without a default view every todo is listed
+	response := fetchViewTodos(t, authToken, "/views/default/todos")
+	assert.ElementsMatch(t, []uint{pinned.ID, unpinned.ID}, todoIds(response.Data))
+
+	first := createView(t, authToken, map[string]interface{}{
+		"name":       "Pinned",
+		"filters":    map[string]string{"pinned": "true"},
+		"is_default": true,
+	})
+	response = fetchViewTodos(t, authToken, "/views/default/todos")
+	assert.Equal(t, []uint{pinned.ID}, todoIds(response.Data))
+
+	// marking another view as the default unmarks the first
+	second := createView(t, authToken, map[string]interface{}{
+		"name":       "Unpinned",
+		"filters":    map[string]string{"pinned": "false"},
+		"is_default": true,
+	})
+	response = fetchViewTodos(t, authToken, "/views/default/todos")
+	assert.Equal(t, []uint{unpinned.ID}, todoIds(response.Data))
+
+	defaults := make(map[uint]bool)
+	for _, view := range fetchViews(t, authToken) {
+		defaults[view.ID] = view.IsDefault
+	}
+	assert.Equal(t, map[uint]bool{first: false, second: true}, defaults)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/views/%d", second), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	response = fetchViewTodos(t, authToken, "/views/default/todos")
+	assert.ElementsMatch(t, []uint{pinned.ID, unpinned.ID}, todoIds(response.Data))
+}
+
+func TestMoveView(t *testing.T) {
+	_, authToken := setupTest(t)
+	ids := make([]uint, 0, 3)
+	for _, name := range []string{"Today", "This week", "Someday"} {
+		ids = append(ids, createView(t, authToken, map[string]interface{}{"name": name}))
+	}
+	assert.Equal(t, ids, viewIds(fetchViews(t, authToken)))
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/views/%d/position", ids[2]), authToken, map[string]interface{}{
+		"before_id": ids[0],
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, []uint{ids[2], ids[0], ids[1]}, viewIds(fetchViews(t, authToken)))
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/views/%d/position", ids[1]), authToken, map[string]interface{}{
+		"after_id": ids[1],
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "a view cannot be moved next to itself", response.Message)
+}
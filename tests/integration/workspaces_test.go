@@ -0,0 +1,359 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/horlerdipo/todo-golang/internal/database"
+	"github.com/horlerdipo/todo-golang/internal/dtos"
+	"github.com/horlerdipo/todo-golang/internal/enums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func SeedWorkspace(t *testing.T, ownerId uint, members map[uint]enums.WorkspaceRole) *database.Workspace {
+	t.Helper()
+
+	workspace := database.Workspace{Name: "Team board", OwnerID: ownerId}
+	require.NoError(t, TestServerInstance.DB.Create(&workspace).Error)
+	require.NoError(t, TestServerInstance.DB.Create(&database.WorkspaceMember{WorkspaceID: workspace.ID, UserID: ownerId, Role: enums.WorkspaceOwner}).Error)
+	for userId, role := range members {
+		require.NoError(t, TestServerInstance.DB.Create(&database.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userId, Role: role}).Error)
+	}
+	return &workspace
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	user, authToken := setupTest(t)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodPost, "/workspaces", authToken, map[string]string{"name": " Team board "})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	workspaceId := uint(response.Data.(map[string]interface{})["id"].(float64))
+
+	workspace := database.Workspace{}
+	require.NoError(t, TestServerInstance.DB.First(&workspace, workspaceId).Error)
+	assert.Equal(t, "Team board", workspace.Name)
+	assert.Equal(t, user.ID, workspace.OwnerID)
+
+	member := database.WorkspaceMember{}
+	require.NoError(t, TestServerInstance.DB.Where("workspace_id = ? AND user_id = ?", workspaceId, user.ID).First(&member).Error)
+	assert.Equal(t, enums.WorkspaceOwner, member.Role)
+
+	resp, response = sendAuthenticatedRequest(t, http.MethodGet, "/workspaces", authToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	workspaces := response.Data.([]interface{})
+	require.Len(t, workspaces, 1)
+	assert.Equal(t, "owner", workspaces[0].(map[string]interface{})["role"])
+}
+
+func TestUpdateAndDeleteWorkspace(t *testing.T) {
+	tests := []struct {
+		name               string
+		role               enums.WorkspaceRole
+		method             string
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "admin renames",
+			role:               enums.WorkspaceAdmin,
+			method:             http.MethodPatch,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "member cannot rename",
+			role:               enums.WorkspaceMember,
+			method:             http.MethodPatch,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only owners and admins can update the workspace",
+		},
+		{
+			name:               "owner deletes",
+			role:               enums.WorkspaceOwner,
+			method:             http.MethodDelete,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "admin cannot delete",
+			role:               enums.WorkspaceAdmin,
+			method:             http.MethodDelete,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only the owner can delete the workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, authToken := setupTest(t)
+			other := SeedUser(t, database.User{Email: "other@gmail.com"})
+			workspace := SeedWorkspace(t, user.ID, map[uint]enums.WorkspaceRole{other.ID: enums.WorkspaceMember})
+			if tt.role != enums.WorkspaceOwner {
+				TestServerInstance.DB.Model(&database.WorkspaceMember{}).Where("user_id = ?", other.ID).Update("role", tt.role)
+				authToken = GenerateTestJwtToken(t, other.ID)
+			}
+
+			resp, response := sendAuthenticatedRequest(t, tt.method, fmt.Sprintf("/workspaces/%d", workspace.ID), authToken, map[string]string{"name": "Renamed"})
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+}
+
+func TestDeleteWorkspace_HandsTodosBack(t *testing.T) {
+	user, authToken := setupTest(t)
+	workspace := SeedWorkspace(t, user.ID, nil)
+	todo := SeedTodo(t, database.Todo{WorkspaceID: &workspace.ID}, user.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodDelete, fmt.Sprintf("/workspaces/%d", workspace.ID), authToken, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	updatedTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.First(&updatedTodo, todo.ID).Error)
+	assert.Nil(t, updatedTodo.WorkspaceID)
+	assert.Equal(t, []uint{todo.ID}, todoIds(fetchTodos(t, authToken, "").Data))
+
+	var members int64
+	TestServerInstance.DB.Model(&database.WorkspaceMember{}).Where("workspace_id = ?", workspace.ID).Count(&members)
+	assert.Zero(t, members)
+}
+
+func TestWorkspaceMembers(t *testing.T) {
+	tests := []struct {
+		name               string
+		as                 enums.WorkspaceRole
+		method             string
+		target             string
+		body               map[string]string
+		expectedStatusCode int
+		expectedMsg        string
+	}{
+		{
+			name:               "admin adds a member",
+			as:                 enums.WorkspaceAdmin,
+			method:             http.MethodPost,
+			body:               map[string]string{"email": "new@gmail.com", "role": "member"},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "admin cannot add an admin",
+			as:                 enums.WorkspaceAdmin,
+			method:             http.MethodPost,
+			body:               map[string]string{"email": "new@gmail.com", "role": "admin"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only the owner can add admins",
+		},
+		{
+			name:               "member cannot add members",
+			as:                 enums.WorkspaceMember,
+			method:             http.MethodPost,
+			body:               map[string]string{"email": "new@gmail.com", "role": "member"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only owners and admins can add members",
+		},
+		{
+			name:               "adding an existing member",
+			as:                 enums.WorkspaceOwner,
+			method:             http.MethodPost,
+			body:               map[string]string{"email": "member@gmail.com", "role": "member"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "user is already a member of this workspace",
+		},
+		{
+			name:               "owner promotes a member",
+			as:                 enums.WorkspaceOwner,
+			method:             http.MethodPatch,
+			target:             "member",
+			body:               map[string]string{"role": "admin"},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "admin cannot change roles",
+			as:                 enums.WorkspaceAdmin,
+			method:             http.MethodPatch,
+			target:             "member",
+			body:               map[string]string{"role": "admin"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only the owner can change member roles",
+		},
+		{
+			name:               "admin removes a member",
+			as:                 enums.WorkspaceAdmin,
+			method:             http.MethodDelete,
+			target:             "member",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "member leaves",
+			as:                 enums.WorkspaceMember,
+			method:             http.MethodDelete,
+			target:             "member",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "member cannot remove others",
+			as:                 enums.WorkspaceMember,
+			method:             http.MethodDelete,
+			target:             "admin",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "only owners and admins can remove members",
+		},
+		{
+			name:               "owner cannot be removed",
+			as:                 enums.WorkspaceAdmin,
+			method:             http.MethodDelete,
+			target:             "owner",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMsg:        "the owner cannot be removed from the workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, authToken := setupTest(t)
+			admin := SeedUser(t, database.User{Email: "admin@gmail.com"})
+			member := SeedUser(t, database.User{Email: "member@gmail.com"})
+			SeedUser(t, database.User{Email: "new@gmail.com"})
+			workspace := SeedWorkspace(t, owner.ID, map[uint]enums.WorkspaceRole{
+				admin.ID:  enums.WorkspaceAdmin,
+				member.ID: enums.WorkspaceMember,
+			})
+
+			users := map[string]*database.User{"owner": owner, "admin": admin, "member": member}
+			switch tt.as {
+			case enums.WorkspaceAdmin:
+				authToken = GenerateTestJwtToken(t, admin.ID)
+			case enums.WorkspaceMember:
+				authToken = GenerateTestJwtToken(t, member.ID)
+			}
+
+			path := fmt.Sprintf("/workspaces/%d/members", workspace.ID)
+			if tt.target != "" {
+				path = fmt.Sprintf("%s/%d", path, users[tt.target].ID)
+			}
+
+			resp, response := sendAuthenticatedRequest(t, tt.method, path, authToken, tt.body)
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if tt.expectedMsg != "" {
+				assert.Equal(t, tt.expectedMsg, response.Message)
+			}
+		})
+	}
+}
+
+func TestWorkspaceTodos(t *testing.T) {
+	owner, ownerToken := setupTest(t)
+	member := SeedUser(t, database.User{Email: "member@gmail.com"})
+	stranger := SeedUser(t, database.User{Email: "stranger@gmail.com"})
+	workspace := SeedWorkspace(t, owner.ID, map[uint]enums.WorkspaceRole{member.ID: enums.WorkspaceMember})
+	memberToken := GenerateTestJwtToken(t, member.ID)
+	personal := SeedTodo(t, database.Todo{}, owner.ID)
+
+	resp, _ := sendAuthenticatedRequest(t, http.MethodPost, fmt.Sprintf("/todos?workspace_id=%d", workspace.ID), memberToken, map[string]interface{}{
+		"title":   "Team todo",
+		"type":    "text",
+		"content": "Shared with the team",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	teamTodo := database.Todo{}
+	require.NoError(t, TestServerInstance.DB.Where("title = ?", "Team todo").First(&teamTodo).Error)
+	require.NotNil(t, teamTodo.WorkspaceID)
+	assert.Equal(t, workspace.ID, *teamTodo.WorkspaceID)
+	assert.Equal(t, member.ID, teamTodo.UserID)
+
+	query := fmt.Sprintf("workspace_id=%d", workspace.ID)
+	assert.Equal(t, []uint{teamTodo.ID}, todoIds(fetchTodos(t, ownerToken, query).Data))
+	assert.Equal(t, []uint{personal.ID}, todoIds(fetchTodos(t, ownerToken, "").Data), "workspace todos stay off personal lists")
+	assert.Empty(t, fetchTodos(t, memberToken, "").Data)
+
+	//any member can edit the workspace's todos
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, fmt.Sprintf("/todos/%d/complete", teamTodo.ID), ownerToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, response := sendAuthenticatedRequest(t, http.MethodGet, "/todos?"+query, GenerateTestJwtToken(t, stranger.ID), nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "you are not a member of this workspace", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodGet, fmt.Sprintf("/todos/%d", teamTodo.ID), GenerateTestJwtToken(t, stranger.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWorkspaceTodos_Header(t *testing.T) {
+	owner, authToken := setupTest(t)
+	workspace := SeedWorkspace(t, owner.ID, nil)
+	todo := SeedTodo(t, database.Todo{WorkspaceID: &workspace.ID}, owner.ID)
+
+	req, err := http.NewRequest(http.MethodGet, TestServerInstance.Server.URL+"/todos", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.Header.Set("X-Workspace-ID", fmt.Sprint(workspace.ID))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response dtos.PaginatedResponse[database.Todo]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, []uint{todo.ID}, todoIds(response.Data))
+	assert.Empty(t, fetchTodos(t, authToken, "").Data)
+}
+
+func TestWorkspaceTodos_Moderation(t *testing.T) {
+	owner, ownerToken := setupTest(t)
+	admin := SeedUser(t, database.User{Email: "admin@gmail.com"})
+	author := SeedUser(t, database.User{Email: "author@gmail.com"})
+	member := SeedUser(t, database.User{Email: "member@gmail.com"})
+	workspace := SeedWorkspace(t, owner.ID, map[uint]enums.WorkspaceRole{
+		admin.ID:  enums.WorkspaceAdmin,
+		author.ID: enums.WorkspaceMember,
+		member.ID: enums.WorkspaceMember,
+	})
+	adminToken := GenerateTestJwtToken(t, admin.ID)
+	authorToken := GenerateTestJwtToken(t, author.ID)
+	memberToken := GenerateTestJwtToken(t, member.ID)
+	todo := SeedTodo(t, database.Todo{WorkspaceID: &workspace.ID}, author.ID)
+	path := fmt.Sprintf("/todos/%d", todo.ID)
+
+	trash := func(authToken string, query string) []interface{} {
+		t.Helper()
+		resp, response := sendAuthenticatedRequest(t, http.MethodGet, "/todos/trash"+query, authToken, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return response.Data.([]interface{})
+	}
+
+	// members only manage the todos they created
+	resp, response := sendAuthenticatedRequest(t, http.MethodDelete, path, memberToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "todo does not exist", response.Message)
+
+	// while the workspace's owner and admins moderate all of them
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, path+"/pin", ownerToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPatch, path+"/archive", adminToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = sendAuthenticatedRequest(t, http.MethodDelete, path, authorToken, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the trash follows the active workspace
+	query := fmt.Sprintf("?workspace_id=%d", workspace.ID)
+	assert.Len(t, trash(authorToken, query), 1)
+	assert.Len(t, trash(adminToken, query), 1)
+	assert.Empty(t, trash(memberToken, query))
+	assert.Empty(t, trash(authorToken, ""), "workspace todos stay out of the personal trash")
+
+	// a member who leaves loses control of the todos they created there
+	require.NoError(t, TestServerInstance.DB.Where("workspace_id = ? AND user_id = ?", workspace.ID, author.ID).Delete(&database.WorkspaceMember{}).Error)
+	resp, response = sendAuthenticatedRequest(t, http.MethodPost, path+"/restore", authorToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "todo is not in the trash", response.Message)
+
+	resp, _ = sendAuthenticatedRequest(t, http.MethodPost, path+"/restore", adminToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, response = sendAuthenticatedRequest(t, http.MethodPatch, path+"/unarchive", authorToken, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "todo does not exist", response.Message)
+}
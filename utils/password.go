@@ -2,6 +2,8 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"math/big"
@@ -32,12 +34,35 @@ func RandomNumericString(length int) (string, error) {
 	return string(result), nil
 }
 
-func GenerateJwtToken(secretKey string, ttl time.Time, data interface{}) (string, error) {
+// RandomToken returns a hex encoded secret made of size random bytes, for tokens that are handed out once and only
+// stored as a HashToken.
+func RandomToken(size int) (string, error) {
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// HashToken is how long random tokens are stored and looked up. Unlike passwords they need no salt or slow hash,
+// since they cannot be guessed.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateFamilyJwtToken signs an access token for data, issued alongside a refresh token. It records the refresh
+// token family, and so the session, the access token belongs to, so that revoking the session turns it off.
+func GenerateFamilyJwtToken(secretKey string, ttl time.Time, data interface{}, familyId string) (string, error) {
 	claims := jwt.MapClaims{
 		"data": data,
+		"fid":  familyId,
 		"exp":  ttl.Unix(),
 	}
+	return signJwtToken(secretKey, claims)
+}
 
+func signJwtToken(secretKey string, claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(secretKey))
 	if err != nil {